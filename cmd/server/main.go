@@ -9,6 +9,9 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 )
 
 func main() {
@@ -28,22 +31,55 @@ func main() {
 	userRepo := repositories.NewUserRepository(db)
 	purchaseRepo := repositories.NewPurchaseRepository(db)
 	achievementRepo := repositories.NewAchievementRepository(db)
+	balanceRepo := repositories.NewMonthlyBalanceRepository(db)
+	runRepo := repositories.NewGamificationRunRepository(db)
+	pointsRepo := repositories.NewPointTransactionRepository(db)
+	settlementRepo := repositories.NewSettlementRepository(db)
+	participationRepo := repositories.NewParticipationRepository(db)
+	recurrenceRepo := repositories.NewRecurrenceRepository(db)
+	budgetRepo := repositories.NewBudgetRepository(db)
+	importProfileRepo := repositories.NewImportProfileRepository(db)
+	categoryRepo := repositories.NewCategoryRepository(db)
+	accountRepo := repositories.NewAccountRepository(db)
+	invoiceRepo := repositories.NewInvoiceRepository(db)
+	installmentRepo := repositories.NewInstallmentRepository(db)
+	goalRepo := repositories.NewGoalRepository(db)
 
 	// ============================================
 	// Inicializar Services (Regras de Negócio)
 	// ============================================
-	expenseService := services.NewExpenseService(expenseRepo)
-	userService := services.NewUserService(userRepo)
-	purchaseService := services.NewPurchaseService(purchaseRepo, userRepo)
-	gamificationService := services.NewGamificationService(userRepo, purchaseRepo, achievementRepo)
+	expenseService := services.NewExpenseService(expenseRepo, userRepo, participationRepo, recurrenceRepo, budgetRepo, categoryRepo, accountRepo, installmentRepo, goalRepo)
+	userService := services.NewUserService(userRepo, participationRepo)
+	purchaseService := services.NewPurchaseService(purchaseRepo, userRepo, balanceRepo, settlementRepo, participationRepo)
+	importService := services.NewImportService(expenseService, importProfileRepo)
+	exportService := services.NewExportService(expenseService, purchaseService)
+	categoryService := services.NewCategoryService(categoryRepo)
+	accountService := services.NewAccountService(accountRepo, goalRepo)
+	invoiceService := services.NewInvoiceService(invoiceRepo, accountRepo)
+	forecastService := services.NewForecastService(expenseRepo, recurrenceRepo, accountRepo, invoiceRepo, installmentRepo)
+	goalService := services.NewGoalService(goalRepo, userRepo)
+	searchService := services.NewSearchService(expenseRepo, purchaseRepo, userRepo)
+	gamificationService := services.NewGamificationService(userRepo, purchaseRepo, achievementRepo, runRepo, pointsRepo, participationRepo)
 
 	// ============================================
 	// Inicializar Controllers (HTTP Handlers)
 	// ============================================
-	expenseController := controllers.NewExpenseController(expenseService)
-	userController := controllers.NewUserController(userService)
+	expenseController := controllers.NewExpenseController(expenseService, userService, forecastService, goalService)
+	userController := controllers.NewUserController(userService, purchaseService)
 	purchaseController := controllers.NewPurchaseController(purchaseService, userService, gamificationService)
 	gamificationController := controllers.NewGamificationController(gamificationService, purchaseService)
+	recurrenceController := controllers.NewRecurrenceController(expenseService, userService)
+	budgetController := controllers.NewBudgetController(expenseService)
+	importController := controllers.NewImportController(importService)
+	exportController := controllers.NewExportController(exportService)
+	searchController := controllers.NewSearchController(searchService)
+	categoryController := controllers.NewCategoryController(categoryService)
+	accountController := controllers.NewAccountController(accountService, goalService)
+	invoiceController := controllers.NewInvoiceController(invoiceService)
+	installmentController := controllers.NewInstallmentController(expenseService, userService)
+	goalController := controllers.NewGoalController(goalService, userService)
+	retentionDays := trashRetentionDays()
+	trashController := controllers.NewTrashController(expenseService, retentionDays)
 
 	// ============================================
 	// Registrar Rotas
@@ -53,6 +89,17 @@ func main() {
 		User:         userController,
 		Purchase:     purchaseController,
 		Gamification: gamificationController,
+		Recurrence:   recurrenceController,
+		Budget:       budgetController,
+		Import:       importController,
+		Export:       exportController,
+		Search:       searchController,
+		Trash:        trashController,
+		Category:     categoryController,
+		Account:      accountController,
+		Invoice:      invoiceController,
+		Installment:  installmentController,
+		Goal:         goalController,
 	}
 	routes.RegisterRoutes(allControllers)
 
@@ -60,6 +107,52 @@ func main() {
 	fs := http.FileServer(http.Dir("web/static"))
 	http.Handle("/static/", http.StripPrefix("/static/", fs))
 
+	// ============================================
+	// Colocar nas faturas as compras no cartão ainda sem fatura
+	// ============================================
+	// Lançamentos novos já entram na fatura ao serem gravados; aqui entram os gravados antes das faturas
+	if err := invoiceService.AssignPending(); err != nil {
+		log.Printf("erro ao atualizar faturas: %v", err)
+	}
+
+	// ============================================
+	// Gerar lançamentos recorrentes vencidos
+	// ============================================
+	// Ao iniciar recupera os períodos perdidos com o servidor desligado; depois verifica a cada hora
+	generateRecurrences := func() {
+		created, err := expenseService.GenerateDueRecurrences(time.Now())
+		if err != nil {
+			log.Printf("erro ao gerar lançamentos recorrentes: %v", err)
+			return
+		}
+		if created > 0 {
+			log.Printf("%d lançamento(s) recorrente(s) gerado(s)", created)
+		}
+	}
+	generateRecurrences()
+
+	// ============================================
+	// Esvaziar a lixeira (lançamentos removidos há mais de TRASH_RETENTION_DAYS dias)
+	// ============================================
+	purgeTrash := func() {
+		purged, err := expenseService.PurgeTrash(time.Now(), retentionDays)
+		if err != nil {
+			log.Printf("erro ao esvaziar a lixeira: %v", err)
+			return
+		}
+		if purged > 0 {
+			log.Printf("%d lançamento(s) apagado(s) da lixeira", purged)
+		}
+	}
+	purgeTrash()
+
+	go func() {
+		for range time.Tick(time.Hour) {
+			generateRecurrences()
+			purgeTrash()
+		}
+	}()
+
 	// ============================================
 	// Iniciar servidor HTTP
 	// ============================================
@@ -71,6 +164,8 @@ func main() {
 	fmt.Println("║  🥪 Compras:        http://localhost:8080/purchases          ║")
 	fmt.Println("║  🏆 Ranking:        http://localhost:8080/ranking            ║")
 	fmt.Println("║  🏅 Conquistas:     http://localhost:8080/achievements       ║")
+	fmt.Println("║  🔁 Recorrentes:    http://localhost:8080/recurrences        ║")
+	fmt.Println("║  🎯 Orçamentos:     http://localhost:8080/budgets            ║")
 	fmt.Println("║  📈 Relatórios:     http://localhost:8080/insights           ║")
 	fmt.Println("╚══════════════════════════════════════════════════════════════╝")
 
@@ -78,3 +173,17 @@ func main() {
 		log.Fatal("Falha ao iniciar servidor:", err)
 	}
 }
+
+// trashRetentionDays lê o prazo da lixeira da variável TRASH_RETENTION_DAYS (padrão: 30 dias)
+func trashRetentionDays() int {
+	value := os.Getenv("TRASH_RETENTION_DAYS")
+	if value == "" {
+		return services.DefaultTrashRetentionDays
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days <= 0 {
+		log.Printf("TRASH_RETENTION_DAYS inválido (%q), usando %d dias", value, services.DefaultTrashRetentionDays)
+		return services.DefaultTrashRetentionDays
+	}
+	return days
+}
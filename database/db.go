@@ -2,6 +2,9 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
@@ -11,12 +14,22 @@ func Connect() (*sql.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate cria as tabelas que faltam e aplica as migrações de dados
+// Separado de Connect para os testes montarem o schema num banco em memória
+func Migrate(db *sql.DB) error {
+	var err error
 
 	// Tabela de expenses (existente)
 	expensesTable := `CREATE TABLE IF NOT EXISTS expenses (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		description TEXT NOT NULL,
-		amount REAL NOT NULL,
+		amount INTEGER NOT NULL,
 		type TEXT NOT NULL,
 		category TEXT NOT NULL,
 		payer TEXT DEFAULT '',
@@ -26,7 +39,7 @@ func Connect() (*sql.DB, error) {
 		deleted_at DATETIME DEFAULT NULL
 	)`
 	if _, err = db.Exec(expensesTable); err != nil {
-		return nil, err
+		return err
 	}
 
 	// Migration: adicionar coluna payer se não existir
@@ -45,35 +58,343 @@ func Connect() (*sql.DB, error) {
 		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	)`
 	if _, err = db.Exec(usersTable); err != nil {
-		return nil, err
+		return err
+	}
+
+	// Migration: período de participação do membro no rateio (NULL = sem limite)
+	db.Exec(`ALTER TABLE users ADD COLUMN joined_at DATE DEFAULT NULL`)
+	db.Exec(`ALTER TABLE users ADD COLUMN left_at DATE DEFAULT NULL`)
+
+	// Membros são arquivados em vez de removidos, preservando o histórico
+	db.Exec(`ALTER TABLE users ADD COLUMN archived_at DATETIME DEFAULT NULL`)
+
+	// Migration: pagador da despesa passa a ser um membro cadastrado (texto livre vira vínculo pelo nome)
+	db.Exec(`ALTER TABLE expenses ADD COLUMN payer_user_id INTEGER DEFAULT NULL REFERENCES users(id)`)
+	db.Exec(`UPDATE expenses SET payer_user_id = (
+			SELECT u.id FROM users u WHERE LOWER(u.name) = LOWER(TRIM(expenses.payer)) LIMIT 1
+		) WHERE payer_user_id IS NULL AND TRIM(payer) <> ''`)
+
+	// Tabela de recorrências (modelos de lançamentos que se repetem, gerados quando vencem)
+	recurrencesTable := `CREATE TABLE IF NOT EXISTS recurrences (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		description TEXT NOT NULL,
+		amount INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		category TEXT NOT NULL,
+		payer_user_id INTEGER DEFAULT NULL,
+		frequency TEXT NOT NULL,
+		interval_days INTEGER NOT NULL DEFAULT 1,
+		day_of_month INTEGER NOT NULL DEFAULT 1,
+		start_date DATE NOT NULL,
+		end_date DATE DEFAULT NULL,
+		next_date DATE NOT NULL,
+		paused INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (payer_user_id) REFERENCES users(id)
+	)`
+	if _, err = db.Exec(recurrencesTable); err != nil {
+		return err
+	}
+
+	// Migration: lançamento gerado por uma recorrência (uma única vez por data)
+	db.Exec(`ALTER TABLE expenses ADD COLUMN recurrence_id INTEGER DEFAULT NULL REFERENCES recurrences(id)`)
+	db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_expenses_recurrence_date
+		ON expenses (recurrence_id, date) WHERE recurrence_id IS NOT NULL`)
+
+	// Tabela de orçamentos mensais por categoria (limite de despesas, com sobra acumulável)
+	budgetsTable := `CREATE TABLE IF NOT EXISTS budgets (
+		category TEXT PRIMARY KEY,
+		amount INTEGER NOT NULL,
+		rollover INTEGER NOT NULL DEFAULT 0,
+		start_month TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err = db.Exec(budgetsTable); err != nil {
+		return err
+	}
+
+	// Tabela de perfis de importação de extratos CSV (mapeamento de colunas por banco)
+	importProfilesTable := `CREATE TABLE IF NOT EXISTS import_profiles (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		delimiter TEXT NOT NULL DEFAULT '',
+		has_header INTEGER NOT NULL DEFAULT 1,
+		date_column TEXT NOT NULL,
+		date_format TEXT NOT NULL,
+		description_column TEXT NOT NULL,
+		amount_mode TEXT NOT NULL DEFAULT 'signed',
+		amount_column TEXT NOT NULL DEFAULT '',
+		debit_column TEXT NOT NULL DEFAULT '',
+		credit_column TEXT NOT NULL DEFAULT '',
+		decimal_comma INTEGER NOT NULL DEFAULT 1,
+		negate INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err = db.Exec(importProfilesTable); err != nil {
+		return err
+	}
+
+	// Migration: identificação da transação no extrato OFX (reimportar não duplica lançamentos)
+	db.Exec(`ALTER TABLE expenses ADD COLUMN fitid TEXT DEFAULT NULL`)
+	db.Exec(`ALTER TABLE expenses ADD COLUMN ofx_account TEXT DEFAULT NULL`)
+	db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_expenses_fitid
+		ON expenses (ofx_account, fitid) WHERE fitid IS NOT NULL`)
+
+	// Índices da lista de lançamentos (ordenação e paginação por data ou valor, desempate pelo ID)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_expenses_list_date ON expenses(substr(date, 1, 10), id) WHERE deleted_at IS NULL`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_expenses_list_amount ON expenses(amount, id) WHERE deleted_at IS NULL`)
+
+	// Tabela de categorias (nome único sem diferenciar maiúsculas; subcategorias via parent_id)
+	categoriesTable := `CREATE TABLE IF NOT EXISTS categories (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		color TEXT NOT NULL DEFAULT '#8b8fa3',
+		icon TEXT NOT NULL DEFAULT '',
+		parent_id INTEGER REFERENCES categories(id),
+		type TEXT NOT NULL CHECK (type IN ('receita', 'despesa')),
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err = db.Exec(categoriesTable); err != nil {
+		return err
+	}
+
+	// Tabela de contas (corrente, poupança, dinheiro, cartão de crédito)
+	accountsTable := `CREATE TABLE IF NOT EXISTS accounts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		type TEXT NOT NULL CHECK (type IN ('corrente', 'poupanca', 'dinheiro', 'cartao')),
+		opening_balance INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err = db.Exec(accountsTable); err != nil {
+		return err
+	}
+
+	// Tabela de transferências entre contas (não são receita nem despesa)
+	transfersTable := `CREATE TABLE IF NOT EXISTS transfers (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		from_account_id INTEGER NOT NULL REFERENCES accounts(id),
+		to_account_id INTEGER NOT NULL REFERENCES accounts(id),
+		amount INTEGER NOT NULL,
+		date DATE NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err = db.Exec(transfersTable); err != nil {
+		return err
+	}
+
+	// Migration: conta de cada lançamento e recorrência
+	db.Exec(`ALTER TABLE expenses ADD COLUMN account_id INTEGER DEFAULT NULL REFERENCES accounts(id)`)
+	db.Exec(`ALTER TABLE recurrences ADD COLUMN account_id INTEGER DEFAULT NULL REFERENCES accounts(id)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_expenses_account ON expenses(account_id)`)
+
+	// Migration: dias de fechamento e vencimento da fatura do cartão
+	db.Exec(`ALTER TABLE accounts ADD COLUMN closing_day INTEGER NOT NULL DEFAULT 0`)
+	db.Exec(`ALTER TABLE accounts ADD COLUMN due_day INTEGER NOT NULL DEFAULT 0`)
+
+	// Tabela de faturas do cartão (uma por cartão e mês de vencimento)
+	// O pagamento é uma transferência da conta corrente para o cartão
+	invoicesTable := `CREATE TABLE IF NOT EXISTS invoices (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id INTEGER NOT NULL REFERENCES accounts(id),
+		month TEXT NOT NULL,
+		closing_date DATE NOT NULL,
+		due_date DATE NOT NULL,
+		transfer_id INTEGER DEFAULT NULL REFERENCES transfers(id),
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(account_id, month)
+	)`
+	if _, err = db.Exec(invoicesTable); err != nil {
+		return err
+	}
+
+	// Migration: fatura de cada compra no cartão
+	db.Exec(`ALTER TABLE expenses ADD COLUMN invoice_id INTEGER DEFAULT NULL REFERENCES invoices(id)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_expenses_invoice ON expenses(invoice_id)`)
+
+	// Tabela de compras parceladas (cada parcela é um lançamento ligado ao plano)
+	installmentPlansTable := `CREATE TABLE IF NOT EXISTS installment_plans (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		description TEXT NOT NULL,
+		total INTEGER NOT NULL,
+		installments INTEGER NOT NULL,
+		category TEXT NOT NULL,
+		payer TEXT NOT NULL DEFAULT '',
+		payer_user_id INTEGER DEFAULT NULL REFERENCES users(id),
+		account_id INTEGER DEFAULT NULL REFERENCES accounts(id),
+		first_date DATE NOT NULL,
+		remainder TEXT NOT NULL DEFAULT 'last' CHECK (remainder IN ('first', 'last')),
+		cancelled_at DATETIME DEFAULT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err = db.Exec(installmentPlansTable); err != nil {
+		return err
+	}
+
+	// Migration: plano e número da parcela de cada lançamento parcelado
+	db.Exec(`ALTER TABLE expenses ADD COLUMN installment_plan_id INTEGER DEFAULT NULL REFERENCES installment_plans(id)`)
+	db.Exec(`ALTER TABLE expenses ADD COLUMN installment_number INTEGER DEFAULT NULL`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_expenses_installment_plan ON expenses(installment_plan_id)`)
+
+	// Tabela de metas de economia (aportes são lançamentos ou transferências ligados à meta)
+	goalsTable := `CREATE TABLE IF NOT EXISTS goals (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		target INTEGER NOT NULL CHECK (target > 0),
+		deadline DATE NOT NULL,
+		user_id INTEGER DEFAULT NULL REFERENCES users(id),
+		completed_at DATETIME DEFAULT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err = db.Exec(goalsTable); err != nil {
+		return err
+	}
+
+	// Migration: meta de economia do aporte
+	db.Exec(`ALTER TABLE expenses ADD COLUMN goal_id INTEGER DEFAULT NULL REFERENCES goals(id)`)
+	db.Exec(`ALTER TABLE transfers ADD COLUMN goal_id INTEGER DEFAULT NULL REFERENCES goals(id)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_expenses_goal ON expenses(goal_id)`)
+	db.Exec(`CREATE INDEX IF NOT EXISTS idx_transfers_goal ON transfers(goal_id)`)
+
+	// Tabela de participação mensal (opt-out ou cota parcial de um membro em um mês)
+	userMonthWeightsTable := `CREATE TABLE IF NOT EXISTS user_month_weights (
+		user_id INTEGER NOT NULL,
+		month TEXT NOT NULL,
+		weight REAL NOT NULL DEFAULT 1,
+		PRIMARY KEY (user_id, month),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	)`
+	if _, err = db.Exec(userMonthWeightsTable); err != nil {
+		return err
 	}
 
 	// Tabela de compras de lanche
 	purchasesTable := `CREATE TABLE IF NOT EXISTS purchases (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		user_id INTEGER NOT NULL,
-		amount REAL NOT NULL,
+		amount INTEGER NOT NULL,
 		date DATE NOT NULL,
 		month TEXT NOT NULL,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		FOREIGN KEY (user_id) REFERENCES users(id)
 	)`
 	if _, err = db.Exec(purchasesTable); err != nil {
-		return nil, err
+		return err
+	}
+
+	// Migration: soft delete de compras (mantém o histórico para auditoria)
+	db.Exec(`ALTER TABLE purchases ADD COLUMN deleted_at DATETIME DEFAULT NULL`)
+
+	// Tabela de participantes de uma compra (sem linhas = dividida entre todos do mês)
+	// amount > 0 é um valor fixo do participante; o restante é dividido pelos pesos
+	purchaseParticipantsTable := `CREATE TABLE IF NOT EXISTS purchase_participants (
+		purchase_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		weight REAL NOT NULL DEFAULT 1,
+		amount INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (purchase_id, user_id),
+		FOREIGN KEY (purchase_id) REFERENCES purchases(id),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	)`
+	if _, err = db.Exec(purchaseParticipantsTable); err != nil {
+		return err
 	}
 
 	// Tabela de balanço mensal
 	monthlyBalancesTable := `CREATE TABLE IF NOT EXISTS monthly_balances (
 		user_id INTEGER NOT NULL,
 		month TEXT NOT NULL,
-		total_paid REAL DEFAULT 0,
-		share_value REAL DEFAULT 0,
-		balance REAL DEFAULT 0,
+		total_paid INTEGER DEFAULT 0,
+		share_value INTEGER DEFAULT 0,
+		balance INTEGER DEFAULT 0,
 		PRIMARY KEY (user_id, month),
 		FOREIGN KEY (user_id) REFERENCES users(id)
 	)`
 	if _, err = db.Exec(monthlyBalancesTable); err != nil {
-		return nil, err
+		return err
+	}
+
+	// Migration: fator de participação do membro gravado no snapshot
+	db.Exec(`ALTER TABLE monthly_balances ADD COLUMN weight REAL DEFAULT NULL`)
+
+	// Tabela de fechamentos mensais (mês congelado com snapshot em monthly_balances)
+	monthClosingsTable := `CREATE TABLE IF NOT EXISTS month_closings (
+		month TEXT PRIMARY KEY,
+		total_spent INTEGER DEFAULT 0,
+		member_count INTEGER DEFAULT 0,
+		closed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err = db.Exec(monthClosingsTable); err != nil {
+		return err
+	}
+
+	// Migration: fechamento pode levar o saldo em aberto para os meses seguintes (opt-in)
+	db.Exec(`ALTER TABLE month_closings ADD COLUMN carry_over INTEGER DEFAULT 0`)
+
+	// Migration: cota cheia do mês gravada no fechamento (membros podem ter pesos diferentes)
+	db.Exec(`ALTER TABLE month_closings ADD COLUMN share_per_person INTEGER DEFAULT NULL`)
+	db.Exec(`UPDATE month_closings SET share_per_person = total_spent / member_count
+		WHERE share_per_person IS NULL AND member_count > 0`)
+
+	// Tabela de acertos de contas (transferências pagas entre membros)
+	settlementsTable := `CREATE TABLE IF NOT EXISTS settlements (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		month TEXT NOT NULL,
+		from_user_id INTEGER NOT NULL,
+		to_user_id INTEGER NOT NULL,
+		amount INTEGER NOT NULL,
+		paid_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (from_user_id) REFERENCES users(id),
+		FOREIGN KEY (to_user_id) REFERENCES users(id)
+	)`
+	if _, err = db.Exec(settlementsTable); err != nil {
+		return err
+	}
+
+	// Tabela de extrato de pontos (cada ganho/perda de pontos é um lançamento)
+	pointTransactionsTable := `CREATE TABLE IF NOT EXISTS point_transactions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		delta INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		month TEXT DEFAULT '',
+		purchase_id INTEGER DEFAULT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id),
+		FOREIGN KEY (purchase_id) REFERENCES purchases(id)
+	)`
+	if _, err = db.Exec(pointTransactionsTable); err != nil {
+		return err
+	}
+
+	// Migration: transformar o contador antigo users.points em lançamentos do extrato
+	if err := migrateLegacyPoints(db); err != nil {
+		return err
+	}
+
+	// Tabela de execuções da gamificação (uma por mês)
+	gamificationRunsTable := `CREATE TABLE IF NOT EXISTS gamification_runs (
+		month TEXT PRIMARY KEY,
+		processed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err = db.Exec(gamificationRunsTable); err != nil {
+		return err
+	}
+
+	// Tabela de pontos aplicados em cada execução (permite reverter o mês)
+	gamificationRunDeltasTable := `CREATE TABLE IF NOT EXISTS gamification_run_deltas (
+		month TEXT NOT NULL,
+		user_id INTEGER NOT NULL,
+		points INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		FOREIGN KEY (month) REFERENCES gamification_runs(month),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	)`
+	if _, err = db.Exec(gamificationRunDeltasTable); err != nil {
+		return err
 	}
 
 	// Tabela de conquistas (badges)
@@ -84,7 +405,7 @@ func Connect() (*sql.DB, error) {
 		icon TEXT
 	)`
 	if _, err = db.Exec(achievementsTable); err != nil {
-		return nil, err
+		return err
 	}
 
 	// Tabela de conquistas dos usuários
@@ -98,7 +419,7 @@ func Connect() (*sql.DB, error) {
 		FOREIGN KEY (achievement_id) REFERENCES achievements(id)
 	)`
 	if _, err = db.Exec(userAchievementsTable); err != nil {
-		return nil, err
+		return err
 	}
 
 	// Seed de conquistas padrão (insert or ignore para não duplicar)
@@ -108,9 +429,361 @@ func Connect() (*sql.DB, error) {
 		('Contador', 'Mais compras no mês', '🧾'),
 		('Equilibrado', 'Saldo próximo de zero', '🔄'),
 		('Mão Aberta', 'Maior gasto individual', '💸'),
-		('Caloteiro Simpático', 'Maior débito do mês', '🐢')
+		('Caloteiro Simpático', 'Maior débito do mês', '🐢'),
+		('Meta Cumprida', 'Concluiu uma meta de economia', '🎯')
 	`
 	db.Exec(seedAchievements)
 
-	return db, nil
+	// Migration: valores monetários em reais (REAL) passam a ser centavos (INTEGER)
+	if err := migrateMoneyToCents(db); err != nil {
+		return err
+	}
+
+	if err := migrateCategories(db); err != nil {
+		return err
+	}
+
+	// Lançamentos anteriores às contas ficam na conta principal
+	db.Exec(`INSERT INTO accounts (name, type) SELECT 'Conta principal', 'corrente' WHERE NOT EXISTS (SELECT 1 FROM accounts)`)
+	db.Exec(`UPDATE expenses SET account_id = (SELECT min(id) FROM accounts) WHERE account_id IS NULL`)
+	db.Exec(`UPDATE recurrences SET account_id = (SELECT min(id) FROM accounts) WHERE account_id IS NULL`)
+
+	// Depois da migração de centavos, que recria tabelas (e apaga os triggers delas)
+	if err := setupExpenseSearch(db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// legacyPurchasePoints são os pontos que o contador antigo dava por compra (PointsPaidSnack)
+const legacyPurchasePoints = 10
+
+// migrateLegacyPoints passa o contador antigo users.points para o extrato de pontos
+// Enquanto o extrato está vazio, o contador vira um saldo inicial ("legacy_balance") por membro.
+// Depois, cada compra ativa sem lançamento no extrato ganha o seu +10, descontado do saldo inicial,
+// para que remover ou transferir uma compra antiga estorne os pontos dela como nas compras novas.
+// Roda a cada inicialização e só mexe em compras ainda sem lançamento
+func migrateLegacyPoints(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO point_transactions (user_id, delta, reason)
+		SELECT id, points, 'legacy_balance' FROM users
+		WHERE points <> 0 AND NOT EXISTS (SELECT 1 FROM point_transactions)`)
+	if err != nil {
+		return err
+	}
+
+	// Compras antigas (sem nenhum lançamento no extrato)
+	const unlinked = `p.deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM point_transactions pt WHERE pt.purchase_id = p.id)`
+
+	// O +10 de cada compra sai do saldo inicial de quem pagou (o primeiro, se houver mais de um)
+	_, err = tx.Exec(`
+		UPDATE point_transactions
+		SET delta = delta - ? * (SELECT count(*) FROM purchases p WHERE p.user_id = point_transactions.user_id AND `+unlinked+`)
+		WHERE id IN (SELECT min(id) FROM point_transactions WHERE reason = 'legacy_balance' GROUP BY user_id)`,
+		legacyPurchasePoints)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO point_transactions (user_id, delta, reason, month, purchase_id)
+		SELECT p.user_id, ?, 'paid_snack', p.month, p.id
+		FROM purchases p
+		WHERE `+unlinked+`
+		ORDER BY p.id`, legacyPurchasePoints)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM point_transactions WHERE reason = 'legacy_balance' AND delta = 0`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// defaultCategories são as categorias criadas no primeiro uso
+var defaultCategories = []struct{ name, icon, color, kind string }{
+	{"Alimentação", "🍔", "#e67e22", "despesa"},
+	{"Transporte", "🚗", "#3498db", "despesa"},
+	{"Lazer", "🎉", "#9b59b6", "despesa"},
+	{"Saúde", "💊", "#e74c3c", "despesa"},
+	{"Moradia", "🏠", "#16a085", "despesa"},
+	{"Educação", "📚", "#f1c40f", "despesa"},
+	{"Outros", "📦", "#8b8fa3", "despesa"},
+	{"Salário", "💼", "#27ae60", "receita"},
+	{"Outras receitas", "💰", "#2ecc71", "receita"},
+}
+
+// legacyCategories traduz as chaves do antigo select fixo para o nome da categoria
+var legacyCategories = map[string]string{
+	"alimentacao": "Alimentação",
+	"transporte":  "Transporte",
+	"lazer":       "Lazer",
+	"saude":       "Saúde",
+	"moradia":     "Moradia",
+	"educacao":    "Educação",
+	"outros":      "Outros",
+}
+
+// migrateCategories cria as categorias padrão e liga os dados antigos a elas:
+// chaves do select fixo ("alimentacao") viram o nome da categoria, a grafia é igualada à da
+// categoria ("saúde" -> "Saúde") e textos livres sem categoria viram categorias novas,
+// com o tipo mais usado nos lançamentos. Roda a cada início e não altera o que já está ligado
+func migrateCategories(db *sql.DB) error {
+	var count int
+	if err := db.QueryRow(`SELECT count(*) FROM categories`).Scan(&count); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if count == 0 {
+		for _, c := range defaultCategories {
+			if _, err := tx.Exec(`INSERT INTO categories (name, icon, color, type) VALUES (?, ?, ?, ?)`,
+				c.name, c.icon, c.color, c.kind); err != nil {
+				return err
+			}
+		}
+	}
+
+	// "outros" em receitas vai para a categoria de receitas
+	for _, table := range []string{"expenses", "recurrences"} {
+		if _, err := tx.Exec(`UPDATE ` + table + ` SET category = 'Outras receitas'
+			WHERE category = 'outros' AND type = 'receita'
+			AND EXISTS (SELECT 1 FROM categories WHERE name = 'Outras receitas')`); err != nil {
+			return err
+		}
+	}
+	for key, name := range legacyCategories {
+		for _, table := range []string{"expenses", "recurrences", "budgets"} {
+			if _, err := tx.Exec(`UPDATE OR IGNORE `+table+` SET category = ?
+				WHERE category = ? AND EXISTS (SELECT 1 FROM categories WHERE name = ?)`, name, key, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Textos livres que ainda não são categoria viram categorias, com o tipo predominante
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO categories (name, type)
+		SELECT category, CASE WHEN sum(type = 'receita') > sum(type <> 'receita') THEN 'receita' ELSE 'despesa' END
+		FROM (
+			SELECT category, type FROM expenses
+			UNION ALL SELECT category, type FROM recurrences
+			UNION ALL SELECT category, 'despesa' FROM budgets
+		)
+		WHERE trim(category) <> '' AND category NOT IN (SELECT name FROM categories)
+		GROUP BY lower(category)`); err != nil {
+		return err
+	}
+
+	// Mesma grafia da categoria (a comparação com o nome usa o NOCASE da coluna)
+	for _, table := range []string{"expenses", "recurrences", "budgets"} {
+		if _, err := tx.Exec(`UPDATE OR IGNORE ` + table + `
+			SET category = (SELECT c.name FROM categories c WHERE c.name = ` + table + `.category)
+			WHERE EXISTS (SELECT 1 FROM categories c
+				WHERE c.name = ` + table + `.category AND c.name <> ` + table + `.category COLLATE BINARY)`); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// setupExpenseSearch cria o índice de busca textual (FTS5) das descrições dos lançamentos
+// O tokenizer remove acentos e ignora maiúsculas ("farmacia" encontra "Farmácia").
+// Triggers mantêm o índice em dia: lançamentos removidos (deleted_at) saem do índice e voltam
+// se forem restaurados. Se o índice divergir da tabela (banco anterior ao FTS), ele é reconstruído
+func setupExpenseSearch(db *sql.DB) error {
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS expenses_fts USING fts5(
+			description,
+			tokenize = 'unicode61 remove_diacritics 2'
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS expenses_fts_insert AFTER INSERT ON expenses
+		WHEN new.deleted_at IS NULL BEGIN
+			INSERT INTO expenses_fts (rowid, description) VALUES (new.id, new.description);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS expenses_fts_update AFTER UPDATE OF description, deleted_at ON expenses BEGIN
+			DELETE FROM expenses_fts WHERE rowid = old.id;
+			INSERT INTO expenses_fts (rowid, description) SELECT new.id, new.description WHERE new.deleted_at IS NULL;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS expenses_fts_delete AFTER DELETE ON expenses BEGIN
+			DELETE FROM expenses_fts WHERE rowid = old.id;
+		END`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return fmt.Errorf("busca textual: %w", err)
+		}
+	}
+
+	var indexed, active int
+	if err := db.QueryRow(`SELECT count(*) FROM expenses_fts`).Scan(&indexed); err != nil {
+		return err
+	}
+	if err := db.QueryRow(`SELECT count(*) FROM expenses WHERE deleted_at IS NULL`).Scan(&active); err != nil {
+		return err
+	}
+	if indexed == active {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM expenses_fts`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO expenses_fts (rowid, description)
+		SELECT id, description FROM expenses WHERE deleted_at IS NULL`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// moneyColumns lista as colunas monetárias de cada tabela (guardadas em centavos)
+var moneyColumns = map[string][]string{
+	"expenses":              {"amount"},
+	"purchases":             {"amount"},
+	"purchase_participants": {"amount"},
+	"monthly_balances":      {"total_paid", "share_value", "balance"},
+	"month_closings":        {"total_spent", "share_per_person"},
+	"settlements":           {"amount"},
+}
+
+// migrateMoneyToCents converte tabelas criadas com colunas monetárias REAL (reais)
+// para INTEGER (centavos). O SQLite não altera o tipo de uma coluna, então a tabela é recriada
+// com o mesmo schema (trocando REAL por INTEGER) e os dados são copiados já convertidos.
+// Tabelas já em centavos são ignoradas, então a migração roda uma única vez
+func migrateMoneyToCents(db *sql.DB) error {
+	for table, columns := range moneyColumns {
+		legacy, err := hasRealColumn(db, table, columns)
+		if err != nil {
+			return err
+		}
+		if !legacy {
+			continue
+		}
+		if err := rebuildWithCents(db, table, columns); err != nil {
+			return fmt.Errorf("migração de centavos em %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// hasRealColumn indica se alguma das colunas monetárias da tabela ainda é REAL
+func hasRealColumn(db *sql.DB, table string, columns []string) (bool, error) {
+	rows, err := db.Query(`SELECT name, type FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, colType string
+		if err := rows.Scan(&name, &colType); err != nil {
+			return false, err
+		}
+		for _, column := range columns {
+			if name == column && strings.EqualFold(colType, "REAL") {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// rebuildWithCents recria a tabela com as colunas monetárias INTEGER, convertendo reais em centavos
+// Os índices da tabela antiga são recriados na nova, na mesma transação (apagar a tabela apaga
+// os índices, e sem eles os UNIQUE de recorrência e de importação OFX deixariam de valer)
+func rebuildWithCents(db *sql.DB, table string, columns []string) error {
+	var ddl string
+	if err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&ddl); err != nil {
+		return err
+	}
+
+	// Índices criados explicitamente (os automáticos de PRIMARY KEY/UNIQUE não têm sql)
+	indexRows, err := db.Query(`SELECT sql FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL`, table)
+	if err != nil {
+		return err
+	}
+	var indexes []string
+	for indexRows.Next() {
+		var index string
+		if err := indexRows.Scan(&index); err != nil {
+			indexRows.Close()
+			return err
+		}
+		indexes = append(indexes, index)
+	}
+	indexRows.Close()
+
+	// Lista de colunas atual (inclui as adicionadas por ALTER TABLE)
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	var names, selects []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		names = append(names, name)
+		selects = append(selects, name)
+	}
+	rows.Close()
+
+	isMoney := make(map[string]bool)
+	for _, column := range columns {
+		isMoney[column] = true
+		ddl = regexp.MustCompile(`(?i)\b`+column+`\s+REAL\b`).ReplaceAllString(ddl, column+" INTEGER")
+	}
+	for i, name := range selects {
+		if isMoney[name] {
+			selects[i] = "CAST(ROUND(" + name + " * 100) AS INTEGER)"
+		}
+	}
+
+	// A tabela nova é criada com outro nome e renomeada no fim, para não reescrever
+	// as referências (FOREIGN KEY) de outras tabelas para a tabela antiga
+	tmp := table + "_cents"
+	ddl = regexp.MustCompile(`(?i)^CREATE TABLE\s+(IF NOT EXISTS\s+)?"?`+table+`"?`).ReplaceAllString(ddl, "CREATE TABLE "+tmp)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(ddl); err != nil {
+		return err
+	}
+	copyQuery := fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM %s`,
+		tmp, strings.Join(names, ", "), strings.Join(selects, ", "), table)
+	if _, err := tx.Exec(copyQuery); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DROP TABLE ` + table); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`ALTER TABLE ` + tmp + ` RENAME TO ` + table); err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if _, err := tx.Exec(index); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"database/sql"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// openMemory abre um banco em memória com uma única conexão (cada conexão ":memory:" é um banco)
+func openMemory(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// openLegacy monta o banco antigo de testdata/legacy.sql e roda as migrações
+func openLegacy(t *testing.T) *sql.DB {
+	t.Helper()
+	db := openMemory(t)
+	fixture, err := os.ReadFile("testdata/legacy.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(fixture)); err != nil {
+		t.Fatalf("banco antigo: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migração: %v", err)
+	}
+	return db
+}
+
+func queryInt(t *testing.T, db *sql.DB, query string, args ...any) int64 {
+	t.Helper()
+	var n int64
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+// schemaOf lista nome e SQL dos índices e triggers do banco
+func schemaOf(t *testing.T, db *sql.DB, kind string) map[string]string {
+	t.Helper()
+	rows, err := db.Query(`SELECT name, sql FROM sqlite_master WHERE type = ? AND sql IS NOT NULL`, kind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	result := make(map[string]string)
+	for rows.Next() {
+		var name, ddl string
+		if err := rows.Scan(&name, &ddl); err != nil {
+			t.Fatal(err)
+		}
+		result[name] = ddl
+	}
+	return result
+}
+
+func TestMigrateMoneyToCents(t *testing.T) {
+	db := openLegacy(t)
+
+	for table, columns := range moneyColumns {
+		for _, column := range columns {
+			var colType string
+			err := db.QueryRow(`SELECT type FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&colType)
+			if err != nil {
+				t.Fatalf("%s.%s: %v", table, column, err)
+			}
+			if colType != "INTEGER" {
+				t.Errorf("%s.%s é %s, quer INTEGER", table, column, colType)
+			}
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  int64
+	}{
+		{`SELECT amount FROM expenses WHERE id = 1`, 500000},
+		{`SELECT amount FROM expenses WHERE id = 2`, 1999}, // 19.99 em REAL é 19.98999...
+		{`SELECT amount FROM expenses WHERE id = 3`, 10},
+		{`SELECT amount FROM expenses WHERE id = 4`, 4550},
+		{`SELECT amount FROM purchases WHERE id = 2`, 1010},
+		{`SELECT amount FROM purchases WHERE id = 3`, 3333},
+		{`SELECT total_paid FROM monthly_balances WHERE user_id = 2`, 2000},
+		{`SELECT share_value FROM monthly_balances WHERE user_id = 2`, 667},
+		{`SELECT balance FROM monthly_balances WHERE user_id = 1`, -667},
+		{`SELECT typeof(amount) = 'integer' FROM expenses WHERE id = 2`, 1},
+		{`SELECT count(*) FROM expenses`, 4},
+	}
+	for _, tt := range tests {
+		if got := queryInt(t, db, tt.query); got != tt.want {
+			t.Errorf("%s = %d, quer %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateKeepsIndexesAndSearch(t *testing.T) {
+	legacy := openLegacy(t)
+	fresh := openMemory(t)
+	if err := Migrate(fresh); err != nil {
+		t.Fatal(err)
+	}
+
+	// As tabelas recriadas ficam com os mesmos índices e triggers de um banco novo
+	for _, kind := range []string{"index", "trigger"} {
+		got, want := schemaOf(t, legacy, kind), schemaOf(t, fresh, kind)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s do banco migrado:\n%v\nquer:\n%v", kind, got, want)
+		}
+	}
+
+	search := func(term string) int64 {
+		return queryInt(t, legacy, `SELECT count(*) FROM expenses_fts WHERE expenses_fts MATCH ?`, term)
+	}
+	if n := search(`"farmacia"`); n != 1 {
+		t.Errorf("busca por farmacia: %d resultados, quer 1", n)
+	}
+	if n := search(`"cinema"`); n != 0 {
+		t.Errorf("lançamento removido está no índice de busca (%d)", n)
+	}
+
+	// Os triggers continuam mantendo o índice depois da migração
+	if _, err := legacy.Exec(`INSERT INTO expenses (description, amount, type, category, date) VALUES ('Padaria', 850, 'despesa', 'Alimentação', '2026-02-01')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.Exec(`UPDATE expenses SET description = 'Drogaria' WHERE id = 2`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.Exec(`UPDATE expenses SET deleted_at = NULL WHERE id = 4`); err != nil {
+		t.Fatal(err)
+	}
+	for term, want := range map[string]int64{`"padaria"`: 1, `"drogaria"`: 1, `"farmacia"`: 0, `"cinema"`: 1} {
+		if n := search(term); n != want {
+			t.Errorf("busca por %s depois das alterações: %d resultados, quer %d", term, n, want)
+		}
+	}
+}
+
+func TestMigrateLegacyPoints(t *testing.T) {
+	db := openLegacy(t)
+
+	tests := []struct {
+		name  string
+		query string
+		want  int64
+	}{
+		{"saldo de GUILHERME", `SELECT COALESCE(SUM(delta), 0) FROM point_transactions WHERE user_id = 1`, -15},
+		{"saldo de DIONE", `SELECT COALESCE(SUM(delta), 0) FROM point_transactions WHERE user_id = 2`, 25},
+		{"saldo de AMANDA", `SELECT COALESCE(SUM(delta), 0) FROM point_transactions WHERE user_id = 3`, 35},
+		{"LORENA sem lançamentos", `SELECT count(*) FROM point_transactions WHERE user_id = 4`, 0},
+		{"saldo inicial de DIONE sem as compras", `SELECT delta FROM point_transactions WHERE user_id = 2 AND reason = 'legacy_balance'`, 5},
+		{"um +10 por compra", `SELECT count(*) FROM point_transactions WHERE reason = 'paid_snack' AND delta = 10 AND purchase_id IS NOT NULL`, 3},
+		{"compras com lançamento no mês da compra", `SELECT count(*) FROM point_transactions pt JOIN purchases p ON p.id = pt.purchase_id
+			WHERE pt.month = p.month AND pt.user_id = p.user_id`, 3},
+	}
+	for _, tt := range tests {
+		if got := queryInt(t, db, tt.query); got != tt.want {
+			t.Errorf("%s = %d, quer %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateTwiceChangesNothing(t *testing.T) {
+	db := openLegacy(t)
+	snapshot := func() []int64 {
+		return []int64{
+			queryInt(t, db, `SELECT SUM(amount) FROM expenses`),
+			queryInt(t, db, `SELECT SUM(amount) FROM purchases`),
+			queryInt(t, db, `SELECT SUM(balance) FROM monthly_balances`),
+			queryInt(t, db, `SELECT count(*) FROM point_transactions`),
+			queryInt(t, db, `SELECT SUM(delta) FROM point_transactions`),
+			queryInt(t, db, `SELECT count(*) FROM expenses_fts`),
+		}
+	}
+	before := snapshot()
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if after := snapshot(); !reflect.DeepEqual(before, after) {
+		t.Errorf("segunda migração mudou os dados: %v -> %v", before, after)
+	}
+}
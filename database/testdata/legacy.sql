@@ -0,0 +1,76 @@
+-- Banco anterior à migração de centavos: colunas monetárias REAL e pontos no contador users.points
+CREATE TABLE expenses (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		description TEXT NOT NULL,
+		amount REAL NOT NULL,
+		type TEXT NOT NULL,
+		category TEXT NOT NULL,
+		date DATE NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		deleted_at DATETIME DEFAULT NULL
+	, payer TEXT DEFAULT '');
+CREATE TABLE users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		points INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+CREATE TABLE purchases (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		amount REAL NOT NULL,
+		date DATE NOT NULL,
+		month TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+CREATE TABLE monthly_balances (
+		user_id INTEGER NOT NULL,
+		month TEXT NOT NULL,
+		total_paid REAL DEFAULT 0,
+		share_value REAL DEFAULT 0,
+		balance REAL DEFAULT 0,
+		PRIMARY KEY (user_id, month),
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+CREATE TABLE achievements (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		description TEXT,
+		icon TEXT
+	);
+CREATE TABLE user_achievements (
+		user_id INTEGER NOT NULL,
+		achievement_id INTEGER NOT NULL,
+		month TEXT NOT NULL,
+		awarded_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, achievement_id, month),
+		FOREIGN KEY (user_id) REFERENCES users(id),
+		FOREIGN KEY (achievement_id) REFERENCES achievements(id)
+	);
+
+INSERT INTO expenses (id, description, amount, type, category, date, deleted_at, payer) VALUES
+	(1, 'Salário', 5000.0, 'receita', 'salario', '2026-01-05', NULL, ''),
+	(2, 'Farmácia', 19.99, 'despesa', 'saude', '2026-01-10', NULL, 'DIONE'),
+	(3, 'Mercado', 0.1, 'despesa', 'alimentacao', '2026-01-11', NULL, ''),
+	(4, 'Cinema', 45.5, 'despesa', 'lazer', '2026-01-12', '2026-01-13 10:00:00', '');
+
+INSERT INTO users (id, name, points) VALUES
+	(1, 'GUILHERME', -15),
+	(2, 'DIONE', 25),
+	(3, 'AMANDA', 35),
+	(4, 'LORENA', 0);
+
+INSERT INTO purchases (id, user_id, amount, date, month) VALUES
+	(1, 2, 20.0, '2026-01-01', '2026-01'),
+	(2, 3, 10.1, '2026-02-05', '2026-02'),
+	(3, 2, 33.33, '2026-02-05', '2026-02');
+
+INSERT INTO monthly_balances (user_id, month, total_paid, share_value, balance) VALUES
+	(2, '2026-01', 20.0, 6.67, 13.33),
+	(1, '2026-01', 0, 6.67, -6.67);
+
+INSERT INTO achievements (name, description, icon) VALUES
+	('Mecenas', 'Maior crédito do mês', '🏆');
@@ -0,0 +1,330 @@
+package controllers
+
+import (
+	"errors"
+	"financas/internal/models"
+	"financas/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AccountController struct {
+	service     *services.AccountService
+	goalService *services.GoalService
+}
+
+// AccountPageData é a estrutura passada para os templates de contas
+type AccountPageData struct {
+	CurrentPage string
+	Accounts    []models.Account
+	Total       models.Money // Soma dos saldos
+	Transfers   []models.Transfer
+	Goals       []models.Goal // Metas para o aporte da transferência
+	Statement   *services.AccountStatement
+	Today       string
+	CSRFToken   string
+}
+
+func NewAccountController(service *services.AccountService, goalService *services.GoalService) *AccountController {
+	return &AccountController{service: service, goalService: goalService}
+}
+
+// accountFromForm lê os campos da conta enviados pelo formulário
+// Os dias de fechamento e vencimento só são lidos para cartões de crédito
+func accountFromForm(r *http.Request) (*models.Account, error) {
+	opening, err := parseOptionalMoney(r.FormValue("opening_balance"))
+	if err != nil {
+		return nil, errors.New("saldo inicial inválido")
+	}
+	account := &models.Account{
+		Name:           r.FormValue("name"),
+		Type:           r.FormValue("type"),
+		OpeningBalance: opening,
+	}
+	if account.IsCreditCard() {
+		if account.ClosingDay, err = strconv.Atoi(r.FormValue("closing_day")); err != nil {
+			return nil, errors.New("dia de fechamento inválido")
+		}
+		if account.DueDay, err = strconv.Atoi(r.FormValue("due_day")); err != nil {
+			return nil, errors.New("dia de vencimento inválido")
+		}
+	}
+	return account, nil
+}
+
+// Index lista as contas com o saldo de hoje, as transferências recentes e os formulários de cadastro
+func (c *AccountController) Index(w http.ResponseWriter, r *http.Request) {
+	today := time.Now()
+	accounts, err := c.service.FindAll(today)
+	if err != nil {
+		log.Printf("erro ao buscar contas: %v", err)
+		http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+		return
+	}
+
+	transfers, err := c.service.FindTransfers()
+	if err != nil {
+		log.Printf("erro ao buscar transferências: %v", err)
+		http.Error(w, "erro ao carregar transferências", http.StatusInternalServerError)
+		return
+	}
+
+	goals, err := c.goalService.FindAll(today)
+	if err != nil {
+		log.Printf("erro ao buscar metas: %v", err)
+		http.Error(w, "erro ao carregar metas", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/accounts.html",
+	))
+
+	data := AccountPageData{
+		CurrentPage: "accounts",
+		Accounts:    accounts,
+		Total:       services.TotalBalance(accounts),
+		Transfers:   transfers,
+		Goals:       goals,
+		Today:       today.Format("2006-01-02"),
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Create cadastra uma conta (POST)
+func (c *AccountController) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	account, err := accountFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.Create(account); err != nil {
+		log.Printf("erro ao criar conta: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/accounts", http.StatusSeeOther)
+}
+
+// Edit mostra o formulário de edição e o extrato da conta com o saldo corrente
+func (c *AccountController) Edit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	statement, err := c.service.Statement(id, time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrAccountNotFound) {
+			http.Error(w, "conta não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao montar extrato: %v", err)
+		http.Error(w, "erro ao carregar extrato", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/account_edit.html",
+	))
+
+	data := AccountPageData{
+		CurrentPage: "accounts",
+		Statement:   statement,
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Update altera nome, tipo e saldo inicial de uma conta (POST)
+func (c *AccountController) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	account, err := accountFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	account.ID = id
+
+	if err := c.service.Update(account); err != nil {
+		if errors.Is(err, services.ErrAccountNotFound) {
+			http.Error(w, "conta não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao atualizar conta: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/accounts/edit?id="+strconv.Itoa(id), http.StatusSeeOther)
+}
+
+// Delete remove uma conta sem movimentos (POST)
+func (c *AccountController) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.Delete(id, time.Now()); err != nil {
+		if errors.Is(err, services.ErrAccountNotFound) {
+			http.Error(w, "conta não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao remover conta: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/accounts", http.StatusSeeOther)
+}
+
+// CreateTransfer registra uma transferência entre contas (POST)
+func (c *AccountController) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	fromID, err := parseAccountID(r.FormValue("from_account_id"))
+	if err != nil {
+		http.Error(w, "conta de origem inválida", http.StatusBadRequest)
+		return
+	}
+	toID, err := parseAccountID(r.FormValue("to_account_id"))
+	if err != nil {
+		http.Error(w, "conta de destino inválida", http.StatusBadRequest)
+		return
+	}
+	amount, err := models.ParseMoney(r.FormValue("amount"))
+	if err != nil {
+		http.Error(w, "valor inválido", http.StatusBadRequest)
+		return
+	}
+	date, err := time.Parse("2006-01-02", r.FormValue("date"))
+	if err != nil {
+		http.Error(w, "data inválida", http.StatusBadRequest)
+		return
+	}
+	goalID, err := parseGoalID(r.FormValue("goal_id"))
+	if err != nil {
+		http.Error(w, "meta inválida", http.StatusBadRequest)
+		return
+	}
+
+	transfer := &models.Transfer{
+		FromAccountID: fromID,
+		ToAccountID:   toID,
+		Amount:        amount,
+		Date:          date,
+		Description:   r.FormValue("description"),
+		GoalID:        goalID,
+	}
+
+	if err := c.service.CreateTransfer(transfer, time.Now()); err != nil {
+		log.Printf("erro ao registrar transferência: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/accounts", http.StatusSeeOther)
+}
+
+// DeleteTransfer remove uma transferência (POST)
+func (c *AccountController) DeleteTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.DeleteTransfer(id); err != nil {
+		log.Printf("erro ao remover transferência: %v", err)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	http.Redirect(w, r, "/accounts", http.StatusSeeOther)
+}
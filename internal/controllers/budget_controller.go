@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"financas/internal/models"
+	"financas/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+)
+
+type BudgetController struct {
+	service *services.ExpenseService
+}
+
+// BudgetPageData é a estrutura passada para o template de orçamentos
+type BudgetPageData struct {
+	CurrentPage  string
+	Budgets      []services.BudgetStatus // Situação de cada orçamento no mês atual
+	Categories   []models.Category
+	CurrentMonth string
+	CSRFToken    string
+}
+
+func NewBudgetController(service *services.ExpenseService) *BudgetController {
+	return &BudgetController{service: service}
+}
+
+// Index lista os orçamentos com a situação do mês atual e o formulário de cadastro
+func (c *BudgetController) Index(w http.ResponseWriter, r *http.Request) {
+	month := time.Now().Format("2006-01")
+
+	budgets, err := c.service.GetBudgetStatus(month)
+	if err != nil {
+		log.Printf("erro ao calcular orçamentos: %v", err)
+		http.Error(w, "erro ao carregar orçamentos", http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := c.service.FindCategories()
+	if err != nil {
+		log.Printf("erro ao buscar categorias: %v", err)
+		http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/budgets.html",
+	))
+
+	data := BudgetPageData{
+		CurrentPage:  "budgets",
+		Budgets:      budgets,
+		Categories:   categories,
+		CurrentMonth: month,
+		CSRFToken:    csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Save cria ou altera o orçamento de uma categoria (POST)
+func (c *BudgetController) Save(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/budgets", http.StatusSeeOther)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	amount, err := models.ParseMoney(r.FormValue("amount"))
+	if err != nil {
+		http.Error(w, "valor inválido", http.StatusBadRequest)
+		return
+	}
+
+	budget := &models.Budget{
+		Category:   r.FormValue("category"),
+		Amount:     amount,
+		Rollover:   r.FormValue("rollover") == "1",
+		StartMonth: time.Now().Format("2006-01"),
+	}
+
+	if err := c.service.SaveBudget(budget); err != nil {
+		log.Printf("erro ao salvar orçamento: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/budgets", http.StatusSeeOther)
+}
+
+// Delete remove o orçamento de uma categoria (POST)
+func (c *BudgetController) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	if err := c.service.DeleteBudget(r.FormValue("category")); err != nil {
+		log.Printf("erro ao remover orçamento: %v", err)
+		http.Error(w, "erro ao remover orçamento", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/budgets", http.StatusSeeOther)
+}
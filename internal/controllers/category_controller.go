@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"errors"
+	"financas/internal/models"
+	"financas/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type CategoryController struct {
+	service *services.CategoryService
+}
+
+// CategoryPageData é a estrutura passada para os templates de categorias
+type CategoryPageData struct {
+	CurrentPage string
+	Categories  []models.Category
+	Category    *models.Category
+	CSRFToken   string
+}
+
+func NewCategoryController(service *services.CategoryService) *CategoryController {
+	return &CategoryController{service: service}
+}
+
+// categoryFromForm lê os campos da categoria enviados pelo formulário
+func categoryFromForm(r *http.Request) *models.Category {
+	parentID, _ := strconv.Atoi(r.FormValue("parent_id"))
+	return &models.Category{
+		Name:     r.FormValue("name"),
+		Color:    r.FormValue("color"),
+		Icon:     r.FormValue("icon"),
+		ParentID: parentID,
+		Type:     r.FormValue("type"),
+	}
+}
+
+// Index lista as categorias com o formulário de cadastro
+func (c *CategoryController) Index(w http.ResponseWriter, r *http.Request) {
+	categories, err := c.service.FindAll()
+	if err != nil {
+		log.Printf("erro ao buscar categorias: %v", err)
+		http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/categories.html",
+	))
+
+	data := CategoryPageData{
+		CurrentPage: "categories",
+		Categories:  categories,
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Create cadastra uma categoria (POST)
+func (c *CategoryController) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	if err := c.service.Create(categoryFromForm(r)); err != nil {
+		log.Printf("erro ao criar categoria: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/categories", http.StatusSeeOther)
+}
+
+// Edit mostra o formulário de edição e de junção de uma categoria
+func (c *CategoryController) Edit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	category, err := c.service.FindByID(id)
+	if err != nil {
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			http.Error(w, "categoria não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao buscar categoria: %v", err)
+		http.Error(w, "erro ao carregar categoria", http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := c.service.FindAll()
+	if err != nil {
+		log.Printf("erro ao buscar categorias: %v", err)
+		http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/category_edit.html",
+	))
+
+	data := CategoryPageData{
+		CurrentPage: "categories",
+		Categories:  categories,
+		Category:    category,
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Update altera uma categoria; renomear atualiza os lançamentos existentes (POST)
+func (c *CategoryController) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	category := categoryFromForm(r)
+	category.ID = id
+	if err := c.service.Update(category); err != nil {
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			http.Error(w, "categoria não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao atualizar categoria: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/categories", http.StatusSeeOther)
+}
+
+// Delete remove uma categoria sem uso (POST)
+func (c *CategoryController) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.Delete(id); err != nil {
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			http.Error(w, "categoria não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao remover categoria: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/categories", http.StatusSeeOther)
+}
+
+// Merge junta uma categoria a outra, movendo os lançamentos (POST)
+func (c *CategoryController) Merge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	sourceID, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+	targetID, err := strconv.Atoi(r.FormValue("target_id"))
+	if err != nil {
+		http.Error(w, "categoria de destino inválida", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.Merge(sourceID, targetID); err != nil {
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			http.Error(w, "categoria não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao juntar categorias: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/categories", http.StatusSeeOther)
+}
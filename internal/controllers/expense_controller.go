@@ -3,6 +3,7 @@ package controllers
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"financas/internal/models"
 	"financas/internal/services"
 	"fmt"
@@ -10,11 +11,15 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type ExpenseController struct {
-	service *services.ExpenseService
+	service         *services.ExpenseService
+	userService     *services.UserService
+	forecastService *services.ForecastService
+	goalService     *services.GoalService
 }
 
 // PageData é a estrutura passada para os templates
@@ -22,22 +27,151 @@ type PageData struct {
 	CurrentPage string
 	Expenses    []models.Expense
 	Expense     *models.Expense
+	Users       []models.User // Membros que podem ser pagadores (rateio)
+	Categories  []models.Category
+	Accounts    []models.Account // Contas com o saldo de hoje
+	Goals       []models.Goal    // Metas de economia (progresso na tela inicial e aporte nos formulários)
+	Filter      models.ExpenseFilter
+	NextURL     string // Próxima página da lista ("" = última)
+	FirstURL    string // Primeira página, quando a lista não está nela
 	CSRFToken   string
 }
 
-func NewExpenseController(service *services.ExpenseService) *ExpenseController {
-	return &ExpenseController{service: service}
+func NewExpenseController(service *services.ExpenseService, userService *services.UserService, forecastService *services.ForecastService, goalService *services.GoalService) *ExpenseController {
+	return &ExpenseController{
+		service:         service,
+		userService:     userService,
+		forecastService: forecastService,
+		goalService:     goalService,
+	}
+}
+
+// parsePayerUserID lê o membro pagador do formulário (vazio = fora do rateio)
+func parsePayerUserID(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// parseAccountID lê a conta do formulário (vazio = 0, recusado ao salvar o lançamento)
+func parseAccountID(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// parseGoalID lê a meta de economia do aporte (vazio = lançamento sem meta)
+func parseGoalID(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// parseExpenseFilter lê o filtro da lista de lançamentos da query string
+// (?from=&to=&type=&category=&payer=&account=&min=&max=&q=&sort=)
+// Usado pela tela inicial e pela exportação, que recebe o mesmo formulário
+func parseExpenseFilter(r *http.Request) (models.ExpenseFilter, error) {
+	query := r.URL.Query()
+	filter := models.ExpenseFilter{
+		Type:     query.Get("type"),
+		Category: query.Get("category"),
+		Search:   query.Get("q"),
+		Sort:     query.Get("sort"),
+	}
+	var err error
+	if filter.From, err = parseOptionalDate(query.Get("from")); err != nil {
+		return filter, errors.New("data inicial inválida")
+	}
+	if filter.To, err = parseOptionalDate(query.Get("to")); err != nil {
+		return filter, errors.New("data final inválida")
+	}
+	if filter.PayerUserID, err = parsePayerUserID(query.Get("payer")); err != nil {
+		return filter, errors.New("pagador inválido")
+	}
+	if filter.AccountID, err = parseAccountID(query.Get("account")); err != nil {
+		return filter, errors.New("conta inválida")
+	}
+	if filter.MinAmount, err = parseOptionalMoney(query.Get("min")); err != nil {
+		return filter, errors.New("valor mínimo inválido")
+	}
+	if filter.MaxAmount, err = parseOptionalMoney(query.Get("max")); err != nil {
+		return filter, errors.New("valor máximo inválido")
+	}
+	return filter, nil
+}
+
+// parseOptionalMoney lê um valor opcional (vazio = 0)
+func parseOptionalMoney(value string) (models.Money, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+	return models.ParseMoney(value)
+}
+
+// pageURL monta o link de outra página da lista mantendo filtros e ordenação
+func pageURL(r *http.Request, cursor string) string {
+	query := r.URL.Query()
+	query.Del("cursor")
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	if len(query) == 0 {
+		return "/"
+	}
+	return "/?" + query.Encode()
 }
 
 func (c *ExpenseController) Index(w http.ResponseWriter, r *http.Request) {
-	expenses, err := c.service.FindAll()
+	filter, err := parseExpenseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+	page, err := c.service.FindPage(filter, cursor)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		log.Printf("error fetching expenses: %v", err)
 		http.Error(w, "erro ao carregar lançamentos", http.StatusInternalServerError)
 		return
 	}
 
-	fmt.Printf("Controller Index: passing %d expenses to template\n", len(expenses))
+	fmt.Printf("Controller Index: passing %d expenses to template\n", len(page.Expenses))
+
+	users, err := c.userService.FindAll()
+	if err != nil {
+		log.Printf("error fetching users: %v", err)
+		http.Error(w, "erro ao carregar membros", http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := c.service.FindCategories()
+	if err != nil {
+		log.Printf("error fetching categories: %v", err)
+		http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+		return
+	}
+
+	accounts, err := c.service.FindAccounts()
+	if err != nil {
+		log.Printf("error fetching accounts: %v", err)
+		http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+		return
+	}
+
+	goals, err := c.goalService.FindAll(time.Now())
+	if err != nil {
+		log.Printf("error fetching goals: %v", err)
+		http.Error(w, "erro ao carregar metas", http.StatusInternalServerError)
+		return
+	}
 
 	csrfToken, err := generateCSRFToken(w, r)
 	if err != nil {
@@ -53,9 +187,20 @@ func (c *ExpenseController) Index(w http.ResponseWriter, r *http.Request) {
 
 	data := PageData{
 		CurrentPage: "index",
-		Expenses:    expenses,
+		Expenses:    page.Expenses,
+		Users:       users,
+		Categories:  categories,
+		Accounts:    accounts,
+		Goals:       goals,
+		Filter:      filter,
 		CSRFToken:   csrfToken,
 	}
+	if page.NextCursor != "" {
+		data.NextURL = pageURL(r, page.NextCursor)
+	}
+	if cursor != "" {
+		data.FirstURL = pageURL(r, "")
+	}
 
 	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
 		fmt.Printf("Template execution error: %v\n", err)
@@ -73,6 +218,34 @@ func (c *ExpenseController) Create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		users, err := c.userService.FindAll()
+		if err != nil {
+			log.Printf("error fetching users: %v", err)
+			http.Error(w, "erro ao carregar membros", http.StatusInternalServerError)
+			return
+		}
+
+		categories, err := c.service.FindCategories()
+		if err != nil {
+			log.Printf("error fetching categories: %v", err)
+			http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+			return
+		}
+
+		accounts, err := c.service.FindAccounts()
+		if err != nil {
+			log.Printf("error fetching accounts: %v", err)
+			http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+			return
+		}
+
+		goals, err := c.goalService.FindAll(time.Now())
+		if err != nil {
+			log.Printf("error fetching goals: %v", err)
+			http.Error(w, "erro ao carregar metas", http.StatusInternalServerError)
+			return
+		}
+
 		tmpl := template.Must(template.ParseFiles(
 			"web/templates/layout.html",
 			"web/templates/create.html",
@@ -80,6 +253,10 @@ func (c *ExpenseController) Create(w http.ResponseWriter, r *http.Request) {
 
 		data := PageData{
 			CurrentPage: "create",
+			Users:       users,
+			Categories:  categories,
+			Accounts:    accounts,
+			Goals:       goals,
 			CSRFToken:   csrfToken,
 		}
 
@@ -108,10 +285,10 @@ func (c *ExpenseController) Create(w http.ResponseWriter, r *http.Request) {
 		fmt.Printf("  type: %s\n", r.FormValue("type"))
 		fmt.Printf("  category: %s\n", r.FormValue("category"))
 		fmt.Printf("  category: %s\n", r.FormValue("category"))
-		fmt.Printf("  payer: %s\n", r.FormValue("payer"))
+		fmt.Printf("  payer_user_id: %s\n", r.FormValue("payer_user_id"))
 		fmt.Printf("  date: %s\n", r.FormValue("date"))
 
-		amount, err := strconv.ParseFloat(r.FormValue("amount"), 64)
+		amount, err := models.ParseMoney(r.FormValue("amount"))
 		if err != nil {
 			log.Printf("error parsing amount: %v", err)
 			http.Error(w, "valor inválido", http.StatusBadRequest)
@@ -123,13 +300,66 @@ func (c *ExpenseController) Create(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "data inválida", http.StatusBadRequest)
 			return
 		}
+		payerUserID, err := parsePayerUserID(r.FormValue("payer_user_id"))
+		if err != nil {
+			http.Error(w, "pagador inválido", http.StatusBadRequest)
+			return
+		}
+		accountID, err := parseAccountID(r.FormValue("account_id"))
+		if err != nil {
+			http.Error(w, "conta inválida", http.StatusBadRequest)
+			return
+		}
+		goalID, err := parseGoalID(r.FormValue("goal_id"))
+		if err != nil {
+			http.Error(w, "meta inválida", http.StatusBadRequest)
+			return
+		}
+		installments := 1
+		if value := r.FormValue("installments"); value != "" {
+			if installments, err = strconv.Atoi(value); err != nil {
+				http.Error(w, "número de parcelas inválido", http.StatusBadRequest)
+				return
+			}
+		}
+
+		// Compra parcelada: o valor informado é o total, dividido entre as parcelas
+		if installments > 1 {
+			if r.FormValue("type") != "despesa" {
+				http.Error(w, "só despesas podem ser parceladas", http.StatusBadRequest)
+				return
+			}
+			if goalID > 0 {
+				http.Error(w, "compras parceladas não podem ser aporte de meta", http.StatusBadRequest)
+				return
+			}
+			plan := &models.InstallmentPlan{
+				Description:  r.FormValue("description"),
+				Total:        amount,
+				Installments: installments,
+				Category:     r.FormValue("category"),
+				PayerUserID:  payerUserID,
+				AccountID:    accountID,
+				FirstDate:    date,
+				Remainder:    r.FormValue("remainder"),
+			}
+			if err := c.service.CreateInstallmentPlan(plan); err != nil {
+				log.Printf("error creating installment plan: %v", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Redirect(w, r, "/installments/view?id="+strconv.Itoa(plan.ID), http.StatusSeeOther)
+			return
+		}
 
 		expense := &models.Expense{
 			Description: r.FormValue("description"),
 			Amount:      amount,
 			Type:        r.FormValue("type"),
 			Category:    r.FormValue("category"),
-			Payer:       r.FormValue("payer"),
+			PayerUserID: payerUserID,
+			AccountID:   accountID,
+			GoalID:      goalID,
 			Date:        date,
 		}
 
@@ -166,6 +396,39 @@ func (c *ExpenseController) Edit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	users, err := c.userService.FindAll()
+	if err != nil {
+		log.Printf("error fetching users: %v", err)
+		http.Error(w, "erro ao carregar membros", http.StatusInternalServerError)
+		return
+	}
+
+	// Pagador arquivado continua disponível para a despesa antiga
+	if payer, err := c.userService.FindByID(expense.PayerUserID); err == nil && payer.IsArchived() {
+		users = append(users, *payer)
+	}
+
+	categories, err := c.service.FindCategories()
+	if err != nil {
+		log.Printf("error fetching categories: %v", err)
+		http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+		return
+	}
+
+	accounts, err := c.service.FindAccounts()
+	if err != nil {
+		log.Printf("error fetching accounts: %v", err)
+		http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+		return
+	}
+
+	goals, err := c.goalService.FindAll(time.Now())
+	if err != nil {
+		log.Printf("error fetching goals: %v", err)
+		http.Error(w, "erro ao carregar metas", http.StatusInternalServerError)
+		return
+	}
+
 	tmpl := template.Must(template.ParseFiles(
 		"web/templates/layout.html",
 		"web/templates/edit.html",
@@ -174,6 +437,10 @@ func (c *ExpenseController) Edit(w http.ResponseWriter, r *http.Request) {
 	data := PageData{
 		CurrentPage: "edit",
 		Expense:     expense,
+		Users:       users,
+		Categories:  categories,
+		Accounts:    accounts,
+		Goals:       goals,
 		CSRFToken:   csrfToken,
 	}
 
@@ -197,7 +464,7 @@ func (c *ExpenseController) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	amount, err := strconv.ParseFloat(r.FormValue("amount"), 64)
+	amount, err := models.ParseMoney(r.FormValue("amount"))
 	if err != nil {
 		http.Error(w, "valor inválido", http.StatusBadRequest)
 		return
@@ -207,6 +474,21 @@ func (c *ExpenseController) Update(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "data inválida", http.StatusBadRequest)
 		return
 	}
+	payerUserID, err := parsePayerUserID(r.FormValue("payer_user_id"))
+	if err != nil {
+		http.Error(w, "pagador inválido", http.StatusBadRequest)
+		return
+	}
+	accountID, err := parseAccountID(r.FormValue("account_id"))
+	if err != nil {
+		http.Error(w, "conta inválida", http.StatusBadRequest)
+		return
+	}
+	goalID, err := parseGoalID(r.FormValue("goal_id"))
+	if err != nil {
+		http.Error(w, "meta inválida", http.StatusBadRequest)
+		return
+	}
 
 	expense := &models.Expense{
 		ID:          id,
@@ -214,11 +496,21 @@ func (c *ExpenseController) Update(w http.ResponseWriter, r *http.Request) {
 		Amount:      amount,
 		Type:        r.FormValue("type"),
 		Category:    r.FormValue("category"),
-		Payer:       r.FormValue("payer"),
+		PayerUserID: payerUserID,
+		AccountID:   accountID,
+		GoalID:      goalID,
 		Date:        date,
 	}
 
 	err = c.service.Update(expense)
+	if errors.Is(err, services.ErrExpenseNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, services.ErrGoalNotFound) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		log.Printf("error updating expense: %v", err)
 		http.Error(w, "erro ao atualizar lançamento", http.StatusInternalServerError)
@@ -258,16 +550,57 @@ func (c *ExpenseController) Delete(w http.ResponseWriter, r *http.Request) {
 type InsightsPageData struct {
 	CurrentPage string
 	Data        *services.InsightsData
+	Forecast    *models.Forecast
 }
 
+// Insights mostra os relatórios gerais e o orçamento x realizado do mês (?month=2026-02)
+// A tendência mensal é por competência ou por caixa (?basis=caixa)
+// A previsão de caixa cobre de 3 a 12 meses (?months=6)
 func (c *ExpenseController) Insights(w http.ResponseWriter, r *http.Request) {
-	insights, err := c.service.GetInsights()
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		month = time.Now().Format("2006-01")
+	}
+	if _, err := time.Parse("2006-01", month); err != nil {
+		http.Error(w, "mês inválido", http.StatusBadRequest)
+		return
+	}
+	basis := r.URL.Query().Get("basis")
+	if basis == "" {
+		basis = services.BasisCompetence
+	}
+	months := services.ForecastDefaultMonths
+	if value := r.URL.Query().Get("months"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "horizonte da previsão inválido", http.StatusBadRequest)
+			return
+		}
+		months = parsed
+	}
+
+	insights, err := c.service.GetInsights(month, basis)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		log.Printf("error fetching insights: %v", err)
 		http.Error(w, "erro ao carregar insights", http.StatusInternalServerError)
 		return
 	}
 
+	forecast, err := c.forecastService.Project(time.Now(), months)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("error projecting cash flow: %v", err)
+		http.Error(w, "erro ao carregar previsão de caixa", http.StatusInternalServerError)
+		return
+	}
+
 	tmpl := template.Must(template.ParseFiles(
 		"web/templates/layout.html",
 		"web/templates/insights.html",
@@ -276,6 +609,7 @@ func (c *ExpenseController) Insights(w http.ResponseWriter, r *http.Request) {
 	data := InsightsPageData{
 		CurrentPage: "insights",
 		Data:        insights,
+		Forecast:    forecast,
 	}
 
 	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
@@ -285,26 +619,60 @@ func (c *ExpenseController) Insights(w http.ResponseWriter, r *http.Request) {
 }
 
 type RateioPageData struct {
-	CurrentPage string
-	Data        *services.RateioStats
+	CurrentPage  string
+	Data         *services.RateioStats
+	Months       []string
+	CurrentMonth string
 }
 
+// Rateio divide as despesas do mês (pagas por membros) entre os membros ativos
 func (c *ExpenseController) Rateio(w http.ResponseWriter, r *http.Request) {
-	stats, err := c.service.GetRateioStats()
+	currentMonth := time.Now().Format("2006-01")
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		month = currentMonth
+	}
+	if _, err := time.Parse("2006-01", month); err != nil {
+		http.Error(w, "mês inválido", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := c.service.GetRateioStats(month)
 	if err != nil {
 		log.Printf("error fetching rateio stats: %v", err)
 		http.Error(w, "erro ao carregar dados de rateio", http.StatusInternalServerError)
 		return
 	}
 
+	months, err := c.service.GetRateioMonths()
+	if err != nil {
+		log.Printf("error fetching rateio months: %v", err)
+		http.Error(w, "erro ao carregar dados de rateio", http.StatusInternalServerError)
+		return
+	}
+
+	// Garantir que o mês atual apareça na lista
+	hasCurrentMonth := false
+	for _, m := range months {
+		if m == currentMonth {
+			hasCurrentMonth = true
+			break
+		}
+	}
+	if !hasCurrentMonth {
+		months = append([]string{currentMonth}, months...)
+	}
+
 	tmpl := template.Must(template.ParseFiles(
 		"web/templates/layout.html",
 		"web/templates/rateio.html",
 	))
 
 	data := RateioPageData{
-		CurrentPage: "rateio",
-		Data:        stats,
+		CurrentPage:  "rateio",
+		Data:         stats,
+		Months:       months,
+		CurrentMonth: month,
 	}
 
 	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"errors"
+	"financas/internal/services"
+	"log"
+	"net/http"
+	"time"
+)
+
+type ExportController struct {
+	service *services.ExportService
+}
+
+func NewExportController(service *services.ExportService) *ExportController {
+	return &ExportController{service: service}
+}
+
+// exportOptions lê o formato (?format=csv|xlsx) e a opção de formato brasileiro (?br=1)
+func exportOptions(r *http.Request) services.ExportOptions {
+	opts := services.ExportOptions{
+		Format: r.URL.Query().Get("format"),
+		BR:     r.URL.Query().Get("br") == "1",
+	}
+	if opts.Format == "" {
+		opts.Format = services.ExportCSV
+	}
+	return opts
+}
+
+// exportMonth lê o mês da exportação (?month=2026-02, padrão = mês atual)
+func exportMonth(r *http.Request) (string, error) {
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		return time.Now().Format("2006-01"), nil
+	}
+	_, err := time.Parse("2006-01", month)
+	return month, err
+}
+
+// writeExport envia as tabelas como arquivo para download
+func writeExport(w http.ResponseWriter, tables []services.ExportTable, opts services.ExportOptions, filename string) {
+	w.Header().Set("Content-Type", opts.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+opts.Filename(filename)+`"`)
+	if err := services.WriteExport(w, tables, opts); err != nil {
+		log.Printf("erro ao escrever exportação: %v", err)
+	}
+}
+
+// Expenses exporta os lançamentos com os mesmos filtros da tela inicial
+func (c *ExportController) Expenses(w http.ResponseWriter, r *http.Request) {
+	opts := exportOptions(r)
+	if err := opts.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseExpenseFilter(r)
+	if err != nil {
+		http.Error(w, "data inválida", http.StatusBadRequest)
+		return
+	}
+
+	tables, err := c.service.ExpensesTables(filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("erro ao exportar lançamentos: %v", err)
+		http.Error(w, "erro ao exportar lançamentos", http.StatusInternalServerError)
+		return
+	}
+
+	writeExport(w, tables, opts, "lancamentos-"+time.Now().Format("2006-01-02"))
+}
+
+// Purchases exporta as compras de lanche do mês
+func (c *ExportController) Purchases(w http.ResponseWriter, r *http.Request) {
+	opts := exportOptions(r)
+	if err := opts.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	month, err := exportMonth(r)
+	if err != nil {
+		http.Error(w, "mês inválido", http.StatusBadRequest)
+		return
+	}
+
+	tables, err := c.service.PurchasesTables(month)
+	if err != nil {
+		log.Printf("erro ao exportar compras: %v", err)
+		http.Error(w, "erro ao exportar compras", http.StatusInternalServerError)
+		return
+	}
+
+	writeExport(w, tables, opts, "compras-"+month)
+}
+
+// Rateio exporta o fechamento do mês: saldos dos membros e acertos
+func (c *ExportController) Rateio(w http.ResponseWriter, r *http.Request) {
+	opts := exportOptions(r)
+	if err := opts.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	month, err := exportMonth(r)
+	if err != nil {
+		http.Error(w, "mês inválido", http.StatusBadRequest)
+		return
+	}
+
+	tables, err := c.service.RateioTables(month)
+	if err != nil {
+		log.Printf("erro ao exportar rateio: %v", err)
+		if errors.Is(err, services.ErrNoParticipants) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "erro ao exportar rateio", http.StatusInternalServerError)
+		return
+	}
+
+	writeExport(w, tables, opts, "rateio-"+month)
+}
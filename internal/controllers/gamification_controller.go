@@ -6,6 +6,7 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"strconv"
 )
 
 type GamificationController struct {
@@ -19,6 +20,12 @@ type RankingPageData struct {
 	Ranking     []models.User
 }
 
+// PointsHistoryPageData é a estrutura para o template de extrato de pontos
+type PointsHistoryPageData struct {
+	CurrentPage string
+	Data        *services.PointsHistoryData
+}
+
 // AchievementsPageData é a estrutura para o template de conquistas
 type AchievementsPageData struct {
 	CurrentPage        string
@@ -61,6 +68,37 @@ func (c *GamificationController) Ranking(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// PointsHistory exibe o extrato de pontos de um membro
+func (c *GamificationController) PointsHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	history, err := c.gamificationService.GetPointsHistory(id)
+	if err != nil {
+		log.Printf("erro ao buscar extrato de pontos: %v", err)
+		http.Error(w, "membro não encontrado", http.StatusNotFound)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/points_history.html",
+	))
+
+	data := PointsHistoryPageData{
+		CurrentPage: "ranking",
+		Data:        history,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro no template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
 // Achievements exibe as conquistas disponíveis e recentes
 func (c *GamificationController) Achievements(w http.ResponseWriter, r *http.Request) {
 	achievements, err := c.gamificationService.GetAllAchievements()
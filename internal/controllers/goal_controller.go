@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"errors"
+	"financas/internal/models"
+	"financas/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type GoalController struct {
+	service     *services.GoalService
+	userService *services.UserService
+}
+
+// GoalPageData é a estrutura passada para os templates de metas
+type GoalPageData struct {
+	CurrentPage string
+	Goals       []models.Goal
+	Detail      *services.GoalDetail
+	Users       []models.User // Membros que podem ser responsáveis pela meta
+	Today       string
+	CSRFToken   string
+}
+
+func NewGoalController(service *services.GoalService, userService *services.UserService) *GoalController {
+	return &GoalController{service: service, userService: userService}
+}
+
+// goalFromForm lê os campos da meta enviados pelo formulário
+func goalFromForm(r *http.Request) (*models.Goal, error) {
+	target, err := models.ParseMoney(r.FormValue("target"))
+	if err != nil {
+		return nil, errors.New("valor da meta inválido")
+	}
+	deadline, err := time.Parse("2006-01-02", r.FormValue("deadline"))
+	if err != nil {
+		return nil, errors.New("prazo inválido")
+	}
+	ownerID, err := parsePayerUserID(r.FormValue("owner_user_id"))
+	if err != nil {
+		return nil, errors.New("responsável inválido")
+	}
+	return &models.Goal{
+		Name:        r.FormValue("name"),
+		Target:      target,
+		Deadline:    deadline,
+		OwnerUserID: ownerID,
+	}, nil
+}
+
+// Index lista as metas com o progresso e o aporte mensal necessário, e o formulário de cadastro
+func (c *GoalController) Index(w http.ResponseWriter, r *http.Request) {
+	today := time.Now()
+	goals, err := c.service.FindAll(today)
+	if err != nil {
+		log.Printf("erro ao buscar metas: %v", err)
+		http.Error(w, "erro ao carregar metas", http.StatusInternalServerError)
+		return
+	}
+
+	users, err := c.userService.FindAll()
+	if err != nil {
+		log.Printf("erro ao buscar usuários: %v", err)
+		http.Error(w, "erro ao carregar membros", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/goals.html",
+	))
+
+	data := GoalPageData{
+		CurrentPage: "goals",
+		Goals:       goals,
+		Users:       users,
+		Today:       today.Format("2006-01-02"),
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Create cadastra uma meta (POST)
+func (c *GoalController) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	goal, err := goalFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.Create(goal, time.Now()); err != nil {
+		log.Printf("erro ao criar meta: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/goals", http.StatusSeeOther)
+}
+
+// Edit mostra o formulário de edição e os aportes da meta
+func (c *GoalController) Edit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	detail, err := c.service.Detail(id, time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrGoalNotFound) {
+			http.Error(w, "meta não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao buscar meta: %v", err)
+		http.Error(w, "erro ao carregar meta", http.StatusInternalServerError)
+		return
+	}
+
+	users, err := c.userService.FindAll()
+	if err != nil {
+		log.Printf("erro ao buscar usuários: %v", err)
+		http.Error(w, "erro ao carregar membros", http.StatusInternalServerError)
+		return
+	}
+
+	// Responsável arquivado continua disponível para a meta antiga
+	if owner, err := c.userService.FindByID(detail.Goal.OwnerUserID); err == nil && owner.IsArchived() {
+		users = append(users, *owner)
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/goal_edit.html",
+	))
+
+	data := GoalPageData{
+		CurrentPage: "goals",
+		Detail:      detail,
+		Users:       users,
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Update altera nome, valor, prazo e responsável de uma meta (POST)
+func (c *GoalController) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	goal, err := goalFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	goal.ID = id
+
+	if err := c.service.Update(goal); err != nil {
+		if errors.Is(err, services.ErrGoalNotFound) {
+			http.Error(w, "meta não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao atualizar meta: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/goals/edit?id="+strconv.Itoa(id), http.StatusSeeOther)
+}
+
+// Delete remove uma meta; os aportes continuam lançados (POST)
+func (c *GoalController) Delete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.Delete(id); err != nil {
+		if errors.Is(err, services.ErrGoalNotFound) {
+			http.Error(w, "meta não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao remover meta: %v", err)
+		http.Error(w, "erro ao remover meta", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/goals", http.StatusSeeOther)
+}
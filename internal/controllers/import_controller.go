@@ -0,0 +1,356 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"financas/internal/models"
+	"financas/internal/services"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// maxImportSize limita o tamanho do extrato enviado (5 MB)
+const maxImportSize = 5 << 20
+
+type ImportController struct {
+	service *services.ImportService
+}
+
+// ImportPageData é a estrutura passada para os templates de importação
+type ImportPageData struct {
+	CurrentPage string
+	Profiles    []models.ImportProfile
+	Profile     models.ImportProfile // Mapeamento em uso (formulário ou perfil escolhido)
+	Rows        []services.ImportRow
+	Data        string // Conteúdo do arquivo em base64, reenviado na confirmação
+	ValidCount  int
+	DupCount    int
+	ErrorCount  int
+	Report      *services.OFXReport // Resultado da importação OFX
+	Categories  []models.Category
+	Accounts    []models.Account
+	CSRFToken   string
+}
+
+func NewImportController(service *services.ImportService) *ImportController {
+	return &ImportController{service: service}
+}
+
+// defaultImportProfile é o mapeamento sugerido para um extrato novo
+func defaultImportProfile() models.ImportProfile {
+	return models.ImportProfile{
+		HasHeader:         true,
+		DateColumn:        "1",
+		DateFormat:        "02/01/2006",
+		DescriptionColumn: "2",
+		AmountMode:        models.AmountSigned,
+		AmountColumn:      "3",
+		DecimalComma:      true,
+	}
+}
+
+// profileFromForm lê o mapeamento de colunas enviado pelo formulário (upload e confirmação)
+func profileFromForm(r *http.Request) models.ImportProfile {
+	return models.ImportProfile{
+		Name:              r.FormValue("profile_name"),
+		Delimiter:         r.FormValue("delimiter"),
+		HasHeader:         r.FormValue("has_header") == "1",
+		DateColumn:        r.FormValue("date_column"),
+		DateFormat:        r.FormValue("date_format"),
+		DescriptionColumn: r.FormValue("description_column"),
+		AmountMode:        r.FormValue("amount_mode"),
+		AmountColumn:      r.FormValue("amount_column"),
+		DebitColumn:       r.FormValue("debit_column"),
+		CreditColumn:      r.FormValue("credit_column"),
+		DecimalComma:      r.FormValue("decimal_comma") == "1",
+		Negate:            r.FormValue("negate") == "1",
+	}
+}
+
+// Index mostra o formulário de upload; ?profile=ID preenche o mapeamento com um perfil salvo
+func (c *ImportController) Index(w http.ResponseWriter, r *http.Request) {
+	profiles, err := c.service.FindProfiles()
+	if err != nil {
+		log.Printf("erro ao buscar perfis de importação: %v", err)
+		http.Error(w, "erro ao carregar perfis", http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := c.service.FindCategories()
+	if err != nil {
+		log.Printf("erro ao buscar categorias: %v", err)
+		http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+		return
+	}
+
+	accounts, err := c.service.FindAccounts()
+	if err != nil {
+		log.Printf("erro ao buscar contas: %v", err)
+		http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+		return
+	}
+
+	profile := defaultImportProfile()
+	if id, err := strconv.Atoi(r.URL.Query().Get("profile")); err == nil {
+		if saved, err := c.service.FindProfile(id); err == nil {
+			profile = *saved
+		}
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/import.html",
+	))
+
+	data := ImportPageData{
+		CurrentPage: "import",
+		Profiles:    profiles,
+		Profile:     profile,
+		Categories:  categories,
+		Accounts:    accounts,
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Preview lê o CSV enviado e mostra as linhas interpretadas, marcando duplicatas (POST)
+func (c *ImportController) Preview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/import", http.StatusSeeOther)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize+1<<20)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		http.Error(w, "arquivo muito grande ou inválido", http.StatusBadRequest)
+		return
+	}
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "selecione o arquivo CSV", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "erro ao ler arquivo", http.StatusBadRequest)
+		return
+	}
+
+	profile := profileFromForm(r)
+	rows, err := c.service.Preview(content, profile)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Mapeamento que funcionou pode ser salvo como perfil do banco
+	if profile.Name != "" {
+		if err := c.service.SaveProfile(&profile); err != nil {
+			log.Printf("erro ao salvar perfil de importação: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	categories, err := c.service.FindCategories()
+	if err != nil {
+		log.Printf("erro ao buscar categorias: %v", err)
+		http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+		return
+	}
+
+	accounts, err := c.service.FindAccounts()
+	if err != nil {
+		log.Printf("erro ao buscar contas: %v", err)
+		http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	data := ImportPageData{
+		CurrentPage: "import",
+		Profile:     profile,
+		Rows:        rows,
+		Categories:  categories,
+		Accounts:    accounts,
+		Data:        base64.StdEncoding.EncodeToString(content),
+		CSRFToken:   csrfToken,
+	}
+	for _, row := range rows {
+		switch {
+		case !row.Valid():
+			data.ErrorCount++
+		case row.Duplicate:
+			data.DupCount++
+		default:
+			data.ValidCount++
+		}
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/import_preview.html",
+	))
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Confirm cria os lançamentos das linhas selecionadas na prévia (POST)
+func (c *ImportController) Confirm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/import", http.StatusSeeOther)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	content, err := base64.StdEncoding.DecodeString(r.FormValue("data"))
+	if err != nil {
+		http.Error(w, "dados da importação inválidos", http.StatusBadRequest)
+		return
+	}
+
+	categories := make(map[int]string)
+	for _, value := range r.Form["rows"] {
+		line, err := strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "linha inválida", http.StatusBadRequest)
+			return
+		}
+		categories[line] = r.FormValue("category_" + value)
+	}
+
+	accountID, err := parseAccountID(r.FormValue("account_id"))
+	if err != nil {
+		http.Error(w, "conta inválida", http.StatusBadRequest)
+		return
+	}
+
+	count, err := c.service.Import(content, profileFromForm(r), categories, accountID)
+	if err != nil {
+		log.Printf("erro ao importar extrato: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("%d lançamento(s) importado(s)", count)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// OFX importa um extrato OFX e mostra quantas transações eram novas, já importadas ou em conflito (POST)
+func (c *ImportController) OFX(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/import", http.StatusSeeOther)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize+1<<20)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		http.Error(w, "arquivo muito grande ou inválido", http.StatusBadRequest)
+		return
+	}
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "selecione o arquivo OFX", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "erro ao ler arquivo", http.StatusBadRequest)
+		return
+	}
+
+	accountID, err := parseAccountID(r.FormValue("account_id"))
+	if err != nil {
+		http.Error(w, "conta inválida", http.StatusBadRequest)
+		return
+	}
+
+	report, err := c.service.ImportOFX(content, r.FormValue("expense_category"), r.FormValue("income_category"), accountID)
+	if err != nil {
+		log.Printf("erro ao importar OFX: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("OFX %s: %d nova(s), %d já importada(s), %d conflito(s)",
+		report.Account, len(report.New), report.Skipped, len(report.Conflicting))
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/import_ofx.html",
+	))
+
+	data := ImportPageData{
+		CurrentPage: "import",
+		Report:      report,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// DeleteProfile remove um perfil de importação salvo (POST)
+func (c *ImportController) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.DeleteProfile(id); err != nil {
+		log.Printf("erro ao remover perfil de importação: %v", err)
+		http.Error(w, "erro ao remover perfil", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/import", http.StatusSeeOther)
+}
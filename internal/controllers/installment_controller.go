@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"errors"
+	"financas/internal/models"
+	"financas/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type InstallmentController struct {
+	service     *services.ExpenseService
+	userService *services.UserService
+}
+
+// InstallmentPageData é a estrutura passada para os templates de compras parceladas
+type InstallmentPageData struct {
+	CurrentPage string
+	Plans       []models.InstallmentPlan
+	Detail      *services.InstallmentPlanDetail
+	Users       []models.User // Membros que podem ser pagadores (rateio)
+	Categories  []models.Category
+	Accounts    []models.Account
+	CSRFToken   string
+}
+
+func NewInstallmentController(service *services.ExpenseService, userService *services.UserService) *InstallmentController {
+	return &InstallmentController{
+		service:     service,
+		userService: userService,
+	}
+}
+
+// Index lista as compras parceladas com as parcelas pagas e restantes
+func (c *InstallmentController) Index(w http.ResponseWriter, r *http.Request) {
+	plans, err := c.service.FindInstallmentPlans(time.Now())
+	if err != nil {
+		log.Printf("erro ao buscar compras parceladas: %v", err)
+		http.Error(w, "erro ao carregar compras parceladas", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/installments.html",
+	))
+
+	data := InstallmentPageData{
+		CurrentPage: "installments",
+		Plans:       plans,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// View mostra as parcelas da compra e os formulários para editar ou cancelar as restantes
+func (c *InstallmentController) View(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	detail, err := c.service.FindInstallmentPlan(id, time.Now())
+	if err != nil {
+		if errors.Is(err, services.ErrInstallmentPlanNotFound) {
+			http.Error(w, "compra parcelada não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao buscar compra parcelada: %v", err)
+		http.Error(w, "erro ao carregar compra parcelada", http.StatusInternalServerError)
+		return
+	}
+
+	users, err := c.userService.FindAll()
+	if err != nil {
+		log.Printf("erro ao buscar usuários: %v", err)
+		http.Error(w, "erro ao carregar membros", http.StatusInternalServerError)
+		return
+	}
+
+	// Pagador arquivado continua disponível para a compra antiga
+	if payer, err := c.userService.FindByID(detail.Plan.PayerUserID); err == nil && payer.IsArchived() {
+		users = append(users, *payer)
+	}
+
+	categories, err := c.service.FindCategories()
+	if err != nil {
+		log.Printf("erro ao buscar categorias: %v", err)
+		http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+		return
+	}
+
+	accounts, err := c.service.FindAccounts()
+	if err != nil {
+		log.Printf("erro ao buscar contas: %v", err)
+		http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/installment.html",
+	))
+
+	data := InstallmentPageData{
+		CurrentPage: "installments",
+		Detail:      detail,
+		Users:       users,
+		Categories:  categories,
+		Accounts:    accounts,
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Update altera as parcelas restantes da compra (POST)
+func (c *InstallmentController) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+	amount, err := parseOptionalMoney(r.FormValue("amount"))
+	if err != nil {
+		http.Error(w, "valor inválido", http.StatusBadRequest)
+		return
+	}
+	payerUserID, err := parsePayerUserID(r.FormValue("payer_user_id"))
+	if err != nil {
+		http.Error(w, "pagador inválido", http.StatusBadRequest)
+		return
+	}
+	accountID, err := parseAccountID(r.FormValue("account_id"))
+	if err != nil {
+		http.Error(w, "conta inválida", http.StatusBadRequest)
+		return
+	}
+
+	plan := &models.InstallmentPlan{
+		ID:          id,
+		Description: r.FormValue("description"),
+		Category:    r.FormValue("category"),
+		PayerUserID: payerUserID,
+		AccountID:   accountID,
+	}
+
+	if err := c.service.UpdateRemainingInstallments(plan, amount, time.Now()); err != nil {
+		if errors.Is(err, services.ErrInstallmentPlanNotFound) {
+			http.Error(w, "compra parcelada não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao atualizar parcelas: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/installments/view?id="+strconv.Itoa(id), http.StatusSeeOther)
+}
+
+// Cancel cancela as parcelas restantes da compra, que vão para a lixeira (POST)
+func (c *InstallmentController) Cancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := c.service.CancelRemainingInstallments(id, time.Now()); err != nil {
+		if errors.Is(err, services.ErrInstallmentPlanNotFound) {
+			http.Error(w, "compra parcelada não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao cancelar parcelas: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/installments/view?id="+strconv.Itoa(id), http.StatusSeeOther)
+}
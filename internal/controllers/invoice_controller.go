@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"errors"
+	"financas/internal/models"
+	"financas/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type InvoiceController struct {
+	service *services.InvoiceService
+}
+
+// InvoicePageData é a estrutura passada para os templates de faturas
+type InvoicePageData struct {
+	CurrentPage string
+	Accounts    []models.Account // Cartões para o filtro e contas correntes para o pagamento
+	AccountID   int              // Cartão filtrado (0 = todos)
+	Invoices    []models.Invoice
+	Detail      *services.InvoiceDetail
+	Today       string
+	CSRFToken   string
+}
+
+func NewInvoiceController(service *services.InvoiceService) *InvoiceController {
+	return &InvoiceController{service: service}
+}
+
+// Index lista as faturas abertas, fechadas e pagas dos cartões (?account=ID filtra um cartão)
+func (c *InvoiceController) Index(w http.ResponseWriter, r *http.Request) {
+	accountID, err := parseAccountID(r.URL.Query().Get("account"))
+	if err != nil {
+		http.Error(w, "cartão inválido", http.StatusBadRequest)
+		return
+	}
+
+	today := time.Now()
+	invoices, err := c.service.FindAll(accountID, today)
+	if err != nil {
+		log.Printf("erro ao buscar faturas: %v", err)
+		http.Error(w, "erro ao carregar faturas", http.StatusInternalServerError)
+		return
+	}
+
+	accounts, err := c.service.FindAccounts(today)
+	if err != nil {
+		log.Printf("erro ao buscar contas: %v", err)
+		http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/invoices.html",
+	))
+
+	data := InvoicePageData{
+		CurrentPage: "invoices",
+		Accounts:    accounts,
+		AccountID:   accountID,
+		Invoices:    invoices,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// View mostra os lançamentos de uma fatura e o formulário de pagamento
+func (c *InvoiceController) View(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	today := time.Now()
+	detail, err := c.service.Detail(id, today)
+	if err != nil {
+		if errors.Is(err, services.ErrInvoiceNotFound) {
+			http.Error(w, "fatura não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao buscar fatura: %v", err)
+		http.Error(w, "erro ao carregar fatura", http.StatusInternalServerError)
+		return
+	}
+
+	accounts, err := c.service.FindAccounts(today)
+	if err != nil {
+		log.Printf("erro ao buscar contas: %v", err)
+		http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/invoice.html",
+	))
+
+	data := InvoicePageData{
+		CurrentPage: "invoices",
+		Accounts:    accounts,
+		Detail:      detail,
+		Today:       today.Format("2006-01-02"),
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Pay paga uma fatura fechada a partir de uma conta corrente (POST)
+func (c *InvoiceController) Pay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+	fromID, err := parseAccountID(r.FormValue("from_account_id"))
+	if err != nil {
+		http.Error(w, "conta de pagamento inválida", http.StatusBadRequest)
+		return
+	}
+	date, err := time.Parse("2006-01-02", r.FormValue("date"))
+	if err != nil {
+		http.Error(w, "data inválida", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.Pay(id, fromID, date, time.Now()); err != nil {
+		if errors.Is(err, services.ErrInvoiceNotFound) {
+			http.Error(w, "fatura não encontrada", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao pagar fatura: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/invoices/view?id="+strconv.Itoa(id), http.StatusSeeOther)
+}
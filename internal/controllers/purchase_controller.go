@@ -1,11 +1,13 @@
 package controllers
 
 import (
+	"errors"
 	"financas/internal/models"
 	"financas/internal/services"
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 )
@@ -20,10 +22,12 @@ type PurchaseController struct {
 type PurchasePageData struct {
 	CurrentPage  string
 	Purchases    []models.Purchase
+	Purchase     *models.Purchase
 	Users        []models.User
 	RateioData   *services.RateioData
 	Months       []string
 	CurrentMonth string
+	Run          *models.GamificationRun // Processamento de pontos do mês (nil se não processado)
 	CSRFToken    string
 }
 
@@ -66,12 +70,23 @@ func (c *PurchaseController) Index(w http.ResponseWriter, r *http.Request) {
 	rateio, err := c.purchaseService.CalculateRateio(month)
 	if err != nil {
 		log.Printf("erro ao calcular rateio: %v", err)
+		if errors.Is(err, services.ErrNoParticipants) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "erro ao calcular rateio", http.StatusInternalServerError)
 		return
 	}
 
 	months, _ := c.purchaseService.GetDistinctMonths()
 
+	run, err := c.gamificationService.GetMonthRun(month)
+	if err != nil {
+		log.Printf("erro ao buscar processamento do mês: %v", err)
+		http.Error(w, "erro ao carregar processamento do mês", http.StatusInternalServerError)
+		return
+	}
+
 	csrfToken, err := generateCSRFToken(w, r)
 	if err != nil {
 		log.Printf("erro ao gerar csrf token: %v", err)
@@ -91,6 +106,7 @@ func (c *PurchaseController) Index(w http.ResponseWriter, r *http.Request) {
 		RateioData:   rateio,
 		Months:       months,
 		CurrentMonth: month,
+		Run:          run,
 		CSRFToken:    csrfToken,
 	}
 
@@ -118,7 +134,7 @@ func (c *PurchaseController) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	amount, err := strconv.ParseFloat(r.FormValue("amount"), 64)
+	amount, err := models.ParseMoney(r.FormValue("amount"))
 	if err != nil {
 		http.Error(w, "valor inválido", http.StatusBadRequest)
 		return
@@ -130,10 +146,17 @@ func (c *PurchaseController) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	participants, err := c.parseParticipants(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	purchase := &models.Purchase{
-		UserID: userID,
-		Amount: amount,
-		Date:   date,
+		UserID:       userID,
+		Amount:       amount,
+		Date:         date,
+		Participants: participants,
 	}
 
 	if err := c.purchaseService.Create(purchase); err != nil {
@@ -142,13 +165,126 @@ func (c *PurchaseController) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Atribuir pontos pela compra (+10)
-	c.gamificationService.AwardPointsForPurchase(userID)
-
 	http.Redirect(w, r, "/purchases", http.StatusSeeOther)
 }
 
-// Delete remove uma compra
+// Edit mostra o formulário de edição de uma compra
+func (c *PurchaseController) Edit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	purchase, err := c.purchaseService.FindByID(id)
+	if err != nil {
+		http.Error(w, "compra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	users, err := c.userService.FindAll()
+	if err != nil {
+		log.Printf("erro ao buscar usuários: %v", err)
+		http.Error(w, "erro ao carregar usuários", http.StatusInternalServerError)
+		return
+	}
+
+	// Se o dono da compra foi arquivado, ele continua disponível no formulário
+	if owner, err := c.userService.FindByID(purchase.UserID); err == nil && owner.IsArchived() {
+		users = append(users, *owner)
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/purchase_edit.html",
+	))
+
+	data := PurchasePageData{
+		CurrentPage:  "purchases",
+		Purchase:     purchase,
+		Users:        users,
+		CurrentMonth: purchase.Month,
+		CSRFToken:    csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro no template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Update altera uma compra; se o dono mudar, os pontos passam para o novo dono
+func (c *PurchaseController) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.Atoi(r.FormValue("user_id"))
+	if err != nil {
+		http.Error(w, "usuário inválido", http.StatusBadRequest)
+		return
+	}
+
+	amount, err := models.ParseMoney(r.FormValue("amount"))
+	if err != nil {
+		http.Error(w, "valor inválido", http.StatusBadRequest)
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", r.FormValue("date"))
+	if err != nil {
+		http.Error(w, "data inválida", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := c.purchaseService.FindByID(id); err != nil {
+		http.Error(w, "compra não encontrada", http.StatusNotFound)
+		return
+	}
+
+	participants, err := c.parseParticipants(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	purchase := &models.Purchase{
+		ID:           id,
+		UserID:       userID,
+		Amount:       amount,
+		Date:         date,
+		Participants: participants,
+	}
+
+	if err := c.purchaseService.Update(purchase); err != nil {
+		log.Printf("erro ao atualizar compra: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/purchases?month="+url.QueryEscape(purchase.Month), http.StatusSeeOther)
+}
+
+// Delete remove uma compra e estorna os pontos que ela gerou
 func (c *PurchaseController) Delete(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
@@ -166,8 +302,17 @@ func (c *PurchaseController) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := c.purchaseService.FindByID(id); err != nil {
+		http.Error(w, "compra não encontrada", http.StatusNotFound)
+		return
+	}
+
 	if err := c.purchaseService.Delete(id); err != nil {
 		log.Printf("erro ao remover compra: %v", err)
+		if errors.Is(err, services.ErrMonthClosed) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "erro ao remover compra", http.StatusInternalServerError)
 		return
 	}
@@ -192,11 +337,193 @@ func (c *PurchaseController) ProcessMonth(w http.ResponseWriter, r *http.Request
 		month = c.purchaseService.GetCurrentMonth()
 	}
 
+	// Congelar o rateio do mês antes de distribuir os pontos
+	carryOver := r.FormValue("carry_over") == "1"
+	if err := c.purchaseService.CloseMonth(month, carryOver); err != nil {
+		log.Printf("erro ao fechar mês: %v", err)
+		if errors.Is(err, services.ErrMonthClosed) || errors.Is(err, services.ErrNoParticipants) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "erro ao fechar mês", http.StatusInternalServerError)
+		return
+	}
+
 	if err := c.gamificationService.ProcessMonthlyGamification(month); err != nil {
 		log.Printf("erro ao processar gamificação: %v", err)
+		// Desfaz o fechamento: um mês fechado sem processamento não poderia ser processado de novo
+		if err := c.purchaseService.ReopenMonth(month); err != nil {
+			log.Printf("erro ao desfazer fechamento do mês: %v", err)
+		}
+		if errors.Is(err, services.ErrMonthAlreadyProcessed) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "erro ao processar mês", http.StatusInternalServerError)
 		return
 	}
 
 	http.Redirect(w, r, "/ranking", http.StatusSeeOther)
 }
+
+// Settle marca uma transferência do plano de acerto como paga
+func (c *PurchaseController) Settle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	fromUserID, err := strconv.Atoi(r.FormValue("from_user_id"))
+	if err != nil {
+		http.Error(w, "devedor inválido", http.StatusBadRequest)
+		return
+	}
+
+	toUserID, err := strconv.Atoi(r.FormValue("to_user_id"))
+	if err != nil {
+		http.Error(w, "credor inválido", http.StatusBadRequest)
+		return
+	}
+
+	amount, err := models.ParseMoney(r.FormValue("amount"))
+	if err != nil {
+		http.Error(w, "valor inválido", http.StatusBadRequest)
+		return
+	}
+
+	settlement := &models.Settlement{
+		Month:      r.FormValue("month"),
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Amount:     amount,
+	}
+
+	if err := c.purchaseService.RecordSettlement(settlement); err != nil {
+		log.Printf("erro ao registrar acerto: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/purchases?month="+url.QueryEscape(settlement.Month), http.StatusSeeOther)
+}
+
+// DeleteSettlement desfaz um acerto registrado por engano
+func (c *PurchaseController) DeleteSettlement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.purchaseService.DeleteSettlement(id); err != nil {
+		log.Printf("erro ao remover acerto: %v", err)
+		http.Error(w, "erro ao remover acerto", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/purchases?month="+url.QueryEscape(r.FormValue("month")), http.StatusSeeOther)
+}
+
+// ReopenMonth reabre um mês fechado, revertendo os pontos e conquistas do processamento
+func (c *PurchaseController) ReopenMonth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	month := r.FormValue("month")
+	if month == "" {
+		http.Error(w, "mês inválido", http.StatusBadRequest)
+		return
+	}
+
+	// Reverter pontos primeiro; um mês fechado sem processamento ainda pode ser reaberto
+	if err := c.gamificationService.ReopenMonth(month); err != nil && !errors.Is(err, services.ErrMonthNotProcessed) {
+		log.Printf("erro ao reverter gamificação: %v", err)
+		http.Error(w, "erro ao reverter pontos do mês", http.StatusInternalServerError)
+		return
+	}
+
+	if err := c.purchaseService.ReopenMonth(month); err != nil {
+		log.Printf("erro ao reabrir mês: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/purchases?month="+url.QueryEscape(month), http.StatusSeeOther)
+}
+
+// parseParticipants lê quem consumiu a compra a partir do formulário
+// Cada membro marcado em "participants" pode ter weight_<id> (padrão 1) e amount_<id> (valor fixo).
+// Todos os membros ativos marcados, sem pesos nem valores, significa dividir entre todos (nenhum participante)
+func (c *PurchaseController) parseParticipants(r *http.Request) ([]models.PurchaseParticipant, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, errors.New("formulário inválido")
+	}
+
+	selected := r.Form["participants"]
+	if len(selected) == 0 {
+		return nil, errors.New("selecione quem consumiu a compra")
+	}
+
+	custom := false
+	var participants []models.PurchaseParticipant
+	for _, value := range selected {
+		userID, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("participante inválido")
+		}
+
+		weight := 1.0
+		if raw := r.FormValue("weight_" + value); raw != "" {
+			if weight, err = strconv.ParseFloat(raw, 64); err != nil {
+				return nil, errors.New("peso do participante inválido")
+			}
+		}
+
+		var amount models.Money
+		if raw := r.FormValue("amount_" + value); raw != "" {
+			if amount, err = models.ParseMoney(raw); err != nil {
+				return nil, errors.New("valor do participante inválido")
+			}
+		}
+
+		if weight != 1 || amount != 0 {
+			custom = true
+		}
+		participants = append(participants, models.PurchaseParticipant{
+			UserID: userID,
+			Weight: weight,
+			Amount: amount,
+		})
+	}
+
+	users, err := c.userService.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	if !custom && len(participants) == len(users) {
+		return nil, nil
+	}
+	return participants, nil
+}
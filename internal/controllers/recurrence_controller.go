@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"errors"
+	"financas/internal/models"
+	"financas/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type RecurrenceController struct {
+	service     *services.ExpenseService
+	userService *services.UserService
+}
+
+// RecurrencePageData é a estrutura passada para os templates de recorrências
+type RecurrencePageData struct {
+	CurrentPage string
+	Recurrences []models.Recurrence
+	Recurrence  *models.Recurrence
+	Users       []models.User // Membros que podem ser pagadores (rateio)
+	Categories  []models.Category
+	Accounts    []models.Account
+	Today       string
+	CSRFToken   string
+}
+
+func NewRecurrenceController(service *services.ExpenseService, userService *services.UserService) *RecurrenceController {
+	return &RecurrenceController{
+		service:     service,
+		userService: userService,
+	}
+}
+
+// parseRecurrenceForm lê o modelo do lançamento e o agendamento enviados pelo formulário
+func parseRecurrenceForm(r *http.Request) (*models.Recurrence, error) {
+	amount, err := models.ParseMoney(r.FormValue("amount"))
+	if err != nil {
+		return nil, errors.New("valor inválido")
+	}
+	startDate, err := time.Parse("2006-01-02", r.FormValue("start_date"))
+	if err != nil {
+		return nil, errors.New("data de início inválida")
+	}
+	endDate, err := parseOptionalDate(r.FormValue("end_date"))
+	if err != nil {
+		return nil, errors.New("data final inválida")
+	}
+	payerUserID, err := parsePayerUserID(r.FormValue("payer_user_id"))
+	if err != nil {
+		return nil, errors.New("pagador inválido")
+	}
+	accountID, err := parseAccountID(r.FormValue("account_id"))
+	if err != nil {
+		return nil, errors.New("conta inválida")
+	}
+
+	rec := &models.Recurrence{
+		Description: r.FormValue("description"),
+		Amount:      amount,
+		Type:        r.FormValue("type"),
+		Category:    r.FormValue("category"),
+		PayerUserID: payerUserID,
+		AccountID:   accountID,
+		Frequency:   r.FormValue("frequency"),
+		StartDate:   startDate,
+		EndDate:     endDate,
+	}
+
+	// Dia do mês e intervalo só se aplicam a algumas frequências
+	if raw := r.FormValue("day_of_month"); raw != "" {
+		if rec.DayOfMonth, err = strconv.Atoi(raw); err != nil {
+			return nil, errors.New("dia do mês inválido")
+		}
+	} else {
+		rec.DayOfMonth = startDate.Day()
+	}
+	if raw := r.FormValue("interval"); raw != "" {
+		if rec.Interval, err = strconv.Atoi(raw); err != nil {
+			return nil, errors.New("intervalo inválido")
+		}
+	}
+	return rec, nil
+}
+
+// Index lista as recorrências e mostra o formulário de cadastro
+func (c *RecurrenceController) Index(w http.ResponseWriter, r *http.Request) {
+	recurrences, err := c.service.FindAllRecurrences()
+	if err != nil {
+		log.Printf("erro ao buscar recorrências: %v", err)
+		http.Error(w, "erro ao carregar recorrências", http.StatusInternalServerError)
+		return
+	}
+
+	users, err := c.userService.FindAll()
+	if err != nil {
+		log.Printf("erro ao buscar usuários: %v", err)
+		http.Error(w, "erro ao carregar membros", http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := c.service.FindCategories()
+	if err != nil {
+		log.Printf("erro ao buscar categorias: %v", err)
+		http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+		return
+	}
+
+	accounts, err := c.service.FindAccounts()
+	if err != nil {
+		log.Printf("erro ao buscar contas: %v", err)
+		http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/recurrences.html",
+	))
+
+	data := RecurrencePageData{
+		CurrentPage: "recurrences",
+		Recurrences: recurrences,
+		Users:       users,
+		Categories:  categories,
+		Accounts:    accounts,
+		Today:       time.Now().Format("2006-01-02"),
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Create cadastra uma recorrência (POST)
+func (c *RecurrenceController) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/recurrences", http.StatusSeeOther)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	rec, err := parseRecurrenceForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.CreateRecurrence(rec, time.Now()); err != nil {
+		log.Printf("erro ao criar recorrência: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/recurrences", http.StatusSeeOther)
+}
+
+// Edit mostra o formulário de edição de uma recorrência
+func (c *RecurrenceController) Edit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	rec, err := c.service.FindRecurrenceByID(id)
+	if err != nil {
+		http.Error(w, "recorrência não encontrada", http.StatusNotFound)
+		return
+	}
+
+	users, err := c.userService.FindAll()
+	if err != nil {
+		log.Printf("erro ao buscar usuários: %v", err)
+		http.Error(w, "erro ao carregar membros", http.StatusInternalServerError)
+		return
+	}
+
+	// Pagador arquivado continua disponível para a recorrência antiga
+	if payer, err := c.userService.FindByID(rec.PayerUserID); err == nil && payer.IsArchived() {
+		users = append(users, *payer)
+	}
+
+	categories, err := c.service.FindCategories()
+	if err != nil {
+		log.Printf("erro ao buscar categorias: %v", err)
+		http.Error(w, "erro ao carregar categorias", http.StatusInternalServerError)
+		return
+	}
+
+	accounts, err := c.service.FindAccounts()
+	if err != nil {
+		log.Printf("erro ao buscar contas: %v", err)
+		http.Error(w, "erro ao carregar contas", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/recurrence_edit.html",
+	))
+
+	data := RecurrencePageData{
+		CurrentPage: "recurrences",
+		Recurrence:  rec,
+		Users:       users,
+		Categories:  categories,
+		Accounts:    accounts,
+		Today:       time.Now().Format("2006-01-02"),
+		CSRFToken:   csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Update altera uma recorrência (POST); lançamentos já gerados não mudam
+func (c *RecurrenceController) Update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	rec, err := parseRecurrenceForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rec.ID = id
+
+	if err := c.service.UpdateRecurrence(rec, time.Now()); err != nil {
+		log.Printf("erro ao atualizar recorrência: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/recurrences", http.StatusSeeOther)
+}
+
+// Pause suspende uma recorrência (POST)
+func (c *RecurrenceController) Pause(w http.ResponseWriter, r *http.Request) {
+	id, ok := c.parseAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.service.PauseRecurrence(id); err != nil {
+		log.Printf("erro ao pausar recorrência: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/recurrences", http.StatusSeeOther)
+}
+
+// Resume retoma uma recorrência pausada a partir de hoje (POST)
+func (c *RecurrenceController) Resume(w http.ResponseWriter, r *http.Request) {
+	id, ok := c.parseAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.service.ResumeRecurrence(id, time.Now()); err != nil {
+		log.Printf("erro ao retomar recorrência: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/recurrences", http.StatusSeeOther)
+}
+
+// End encerra uma recorrência na data informada (padrão: hoje) (POST)
+func (c *RecurrenceController) End(w http.ResponseWriter, r *http.Request) {
+	id, ok := c.parseAction(w, r)
+	if !ok {
+		return
+	}
+
+	endDate, err := parseOptionalDate(r.FormValue("end_date"))
+	if err != nil {
+		http.Error(w, "data final inválida", http.StatusBadRequest)
+		return
+	}
+	if endDate.IsZero() {
+		now := time.Now()
+		endDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	if err := c.service.EndRecurrence(id, endDate); err != nil {
+		log.Printf("erro ao encerrar recorrência: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/recurrences", http.StatusSeeOther)
+}
+
+// parseAction valida método, CSRF e o ID das ações de pausar, retomar e encerrar
+func (c *RecurrenceController) parseAction(w http.ResponseWriter, r *http.Request) (int, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return 0, false
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
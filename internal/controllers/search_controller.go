@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"financas/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+type SearchController struct {
+	service *services.SearchService
+}
+
+// SearchPageData é a estrutura passada para o template da busca
+type SearchPageData struct {
+	CurrentPage string
+	Results     *services.SearchResults
+}
+
+func NewSearchController(service *services.SearchService) *SearchController {
+	return &SearchController{service: service}
+}
+
+// Index mostra os resultados da busca global (?q=)
+func (c *SearchController) Index(w http.ResponseWriter, r *http.Request) {
+	results, err := c.service.Search(r.URL.Query().Get("q"))
+	if err != nil {
+		log.Printf("erro na busca: %v", err)
+		http.Error(w, "erro ao buscar", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/search.html",
+	))
+
+	data := SearchPageData{
+		CurrentPage: "search",
+		Results:     results,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"errors"
+	"financas/internal/models"
+	"financas/internal/services"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type TrashController struct {
+	service       *services.ExpenseService
+	retentionDays int
+}
+
+// TrashPageData é a estrutura passada para o template da lixeira
+type TrashPageData struct {
+	CurrentPage   string
+	Expenses      []models.Expense
+	RetentionDays int
+	CSRFToken     string
+}
+
+func NewTrashController(service *services.ExpenseService, retentionDays int) *TrashController {
+	return &TrashController{service: service, retentionDays: retentionDays}
+}
+
+// Index lista os lançamentos removidos que ainda podem ser restaurados
+func (c *TrashController) Index(w http.ResponseWriter, r *http.Request) {
+	expenses, err := c.service.FindDeleted()
+	if err != nil {
+		log.Printf("erro ao buscar lixeira: %v", err)
+		http.Error(w, "erro ao carregar lixeira", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/trash.html",
+	))
+
+	data := TrashPageData{
+		CurrentPage:   "trash",
+		Expenses:      expenses,
+		RetentionDays: c.retentionDays,
+		CSRFToken:     csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro ao renderizar template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Restore devolve um lançamento da lixeira para a lista (POST)
+func (c *TrashController) Restore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.Restore(id); err != nil {
+		if errors.Is(err, services.ErrExpenseNotFound) {
+			http.Error(w, "lançamento não está na lixeira", http.StatusNotFound)
+			return
+		}
+		log.Printf("erro ao restaurar lançamento: %v", err)
+		http.Error(w, "erro ao restaurar lançamento", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/trash", http.StatusSeeOther)
+}
@@ -1,28 +1,38 @@
 package controllers
 
 import (
+	"errors"
 	"financas/internal/models"
 	"financas/internal/services"
 	"html/template"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type UserController struct {
-	service *services.UserService
+	service         *services.UserService
+	purchaseService *services.PurchaseService
 }
 
 // UserPageData é a estrutura passada para os templates de usuários
 type UserPageData struct {
-	CurrentPage string
-	Users       []models.User
-	User        *models.User
-	CSRFToken   string
+	CurrentPage    string
+	Users          []models.User
+	Archived       []models.User // Membros arquivados (podem ser restaurados)
+	User           *models.User
+	Participations []models.MonthParticipation
+	Balances       map[int]services.MemberRateioStat // Saldo do mês e acumulado por membro
+	CurrentMonth   string
+	CSRFToken      string
 }
 
-func NewUserController(service *services.UserService) *UserController {
-	return &UserController{service: service}
+func NewUserController(service *services.UserService, purchaseService *services.PurchaseService) *UserController {
+	return &UserController{
+		service:         service,
+		purchaseService: purchaseService,
+	}
 }
 
 // Index lista todos os membros da equipe
@@ -34,6 +44,20 @@ func (c *UserController) Index(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	archived, err := c.service.FindArchived()
+	if err != nil {
+		log.Printf("erro ao buscar arquivados: %v", err)
+		http.Error(w, "erro ao carregar membros", http.StatusInternalServerError)
+		return
+	}
+
+	balances, err := c.purchaseService.GetCumulativeBalances()
+	if err != nil {
+		log.Printf("erro ao calcular saldos: %v", err)
+		http.Error(w, "erro ao calcular saldos", http.StatusInternalServerError)
+		return
+	}
+
 	csrfToken, err := generateCSRFToken(w, r)
 	if err != nil {
 		log.Printf("erro ao gerar csrf token: %v", err)
@@ -47,9 +71,12 @@ func (c *UserController) Index(w http.ResponseWriter, r *http.Request) {
 	))
 
 	data := UserPageData{
-		CurrentPage: "users",
-		Users:       users,
-		CSRFToken:   csrfToken,
+		CurrentPage:  "users",
+		Users:        users,
+		Archived:     archived,
+		Balances:     balances,
+		CurrentMonth: c.purchaseService.GetCurrentMonth(),
+		CSRFToken:    csrfToken,
 	}
 
 	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
@@ -83,8 +110,55 @@ func (c *UserController) Create(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/users", http.StatusSeeOther)
 }
 
-// Delete remove um membro da equipe
-func (c *UserController) Delete(w http.ResponseWriter, r *http.Request) {
+// Edit mostra o formulário de edição de um membro (período e participação mensal)
+func (c *UserController) Edit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	user, err := c.service.FindByID(id)
+	if err != nil {
+		http.Error(w, "membro não encontrado", http.StatusNotFound)
+		return
+	}
+
+	participations, err := c.service.GetParticipations(id)
+	if err != nil {
+		log.Printf("erro ao buscar participações: %v", err)
+		http.Error(w, "erro ao carregar participações", http.StatusInternalServerError)
+		return
+	}
+
+	csrfToken, err := generateCSRFToken(w, r)
+	if err != nil {
+		log.Printf("erro ao gerar csrf token: %v", err)
+		http.Error(w, "erro interno", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl := template.Must(template.ParseFiles(
+		"web/templates/layout.html",
+		"web/templates/user_edit.html",
+	))
+
+	data := UserPageData{
+		CurrentPage:    "users",
+		User:           user,
+		Participations: participations,
+		CurrentMonth:   c.purchaseService.GetCurrentMonth(),
+		CSRFToken:      csrfToken,
+	}
+
+	if err := tmpl.ExecuteTemplate(w, "layout", data); err != nil {
+		log.Printf("erro no template: %v", err)
+		http.Error(w, "erro ao renderizar página", http.StatusInternalServerError)
+	}
+}
+
+// Update altera nome e período de participação de um membro
+func (c *UserController) Update(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
 		return
@@ -101,11 +175,170 @@ func (c *UserController) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := c.service.Delete(id); err != nil {
-		log.Printf("erro ao remover usuário: %v", err)
-		http.Error(w, "erro ao remover membro", http.StatusInternalServerError)
+	joinedAt, err := parseOptionalDate(r.FormValue("joined_at"))
+	if err != nil {
+		http.Error(w, "data de entrada inválida", http.StatusBadRequest)
+		return
+	}
+
+	leftAt, err := parseOptionalDate(r.FormValue("left_at"))
+	if err != nil {
+		http.Error(w, "data de saída inválida", http.StatusBadRequest)
+		return
+	}
+
+	user := &models.User{
+		ID:       id,
+		Name:     r.FormValue("name"),
+		JoinedAt: joinedAt,
+		LeftAt:   leftAt,
+	}
+
+	if err := c.service.Update(user); err != nil {
+		log.Printf("erro ao atualizar usuário: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/users/edit?id="+strconv.Itoa(id), http.StatusSeeOther)
+}
+
+// SetParticipation define o peso de um membro em um mês (opt-out ou cota parcial)
+func (c *UserController) SetParticipation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	userID, err := strconv.Atoi(r.FormValue("user_id"))
+	if err != nil {
+		http.Error(w, "usuário inválido", http.StatusBadRequest)
+		return
+	}
+
+	weight, err := strconv.ParseFloat(r.FormValue("weight"), 64)
+	if err != nil {
+		http.Error(w, "peso inválido", http.StatusBadRequest)
+		return
+	}
+
+	participation := &models.MonthParticipation{
+		UserID: userID,
+		Month:  r.FormValue("month"),
+		Weight: weight,
+	}
+
+	if err := c.service.SetParticipation(participation); err != nil {
+		log.Printf("erro ao definir participação: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/users/edit?id="+strconv.Itoa(userID), http.StatusSeeOther)
+}
+
+// DeleteParticipation volta o membro para a cota cheia em um mês
+func (c *UserController) DeleteParticipation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	userID, err := strconv.Atoi(r.FormValue("user_id"))
+	if err != nil {
+		http.Error(w, "usuário inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.DeleteParticipation(userID, r.FormValue("month")); err != nil {
+		log.Printf("erro ao remover participação: %v", err)
+		http.Error(w, "erro ao remover participação", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/users/edit?id="+strconv.Itoa(userID), http.StatusSeeOther)
+}
+
+// Archive arquiva um membro da equipe (recusa se houver saldo em aberto, a menos que force=1)
+func (c *UserController) Archive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	balances, err := c.purchaseService.GetCumulativeBalances()
+	if err != nil {
+		log.Printf("erro ao calcular saldos: %v", err)
+		http.Error(w, "erro ao calcular saldos", http.StatusInternalServerError)
+		return
+	}
+
+	force := r.FormValue("force") == "1"
+	if err := c.service.Archive(id, balances[id].Outstanding, force); err != nil {
+		log.Printf("erro ao arquivar usuário: %v", err)
+		if errors.Is(err, services.ErrMemberHasBalance) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "erro ao arquivar membro", http.StatusInternalServerError)
 		return
 	}
 
 	http.Redirect(w, r, "/users", http.StatusSeeOther)
 }
+
+// Restore reativa um membro arquivado
+func (c *UserController) Restore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "método não permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !validateCSRFToken(w, r) {
+		http.Error(w, "requisição inválida", http.StatusForbidden)
+		return
+	}
+
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		http.Error(w, "ID inválido", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.service.Restore(id); err != nil {
+		log.Printf("erro ao restaurar usuário: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	http.Redirect(w, r, "/users", http.StatusSeeOther)
+}
+
+// parseOptionalDate converte uma data do formulário; campo vazio vira a data zero
+func parseOptionalDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// Tipos de conta
+const (
+	AccountChecking   = "corrente"
+	AccountSavings    = "poupanca"
+	AccountCash       = "dinheiro"
+	AccountCreditCard = "cartao"
+)
+
+// Account é uma conta de onde o dinheiro sai ou para onde entra (banco, carteira, cartão)
+// O saldo é o saldo inicial mais as receitas, menos as despesas, mais/menos as transferências
+type Account struct {
+	ID             int       `json:"id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`            // Uma das constantes Account*
+	OpeningBalance Money     `json:"opening_balance"` // Saldo antes do primeiro lançamento (negativo = dívida do cartão)
+	Balance        Money     `json:"balance"`         // Saldo atual, calculado (lançamentos até hoje)
+	Usage          int       `json:"usage"`           // Lançamentos e transferências da conta (inclusive na lixeira)
+	ClosingDay     int       `json:"closing_day"`     // Dia de fechamento da fatura (só cartão; 0 = não se aplica)
+	DueDay         int       `json:"due_day"`         // Dia de vencimento da fatura (só cartão; 0 = não se aplica)
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TypeLabel é o nome do tipo de conta para exibição
+func (a Account) TypeLabel() string {
+	switch a.Type {
+	case AccountChecking:
+		return "Conta corrente"
+	case AccountSavings:
+		return "Poupança"
+	case AccountCash:
+		return "Dinheiro"
+	case AccountCreditCard:
+		return "Cartão de crédito"
+	default:
+		return a.Type
+	}
+}
+
+// IsCreditCard indica se a conta é um cartão de crédito, com faturas
+func (a Account) IsCreditCard() bool {
+	return a.Type == AccountCreditCard
+}
+
+// InvoiceDates retorna o fechamento e o vencimento da fatura de uma compra no cartão
+// Compras a partir do dia de fechamento vão para a fatura seguinte; o vencimento cai no mês
+// do fechamento quando o dia de vencimento vem depois do de fechamento, senão no mês seguinte
+func (a Account) InvoiceDates(purchase time.Time) (closing, due time.Time) {
+	purchase = dateOnly(purchase)
+	closing = monthDay(purchase.Year(), purchase.Month(), a.ClosingDay)
+	if !purchase.Before(closing) {
+		closing = monthDay(purchase.Year(), purchase.Month()+1, a.ClosingDay)
+	}
+	dueMonth := closing.Month()
+	if a.DueDay <= a.ClosingDay {
+		dueMonth++
+	}
+	due = monthDay(closing.Year(), dueMonth, a.DueDay)
+	return closing, due
+}
+
+// Transfer move dinheiro entre duas contas; não é receita nem despesa
+type Transfer struct {
+	ID              int       `json:"id"`
+	FromAccountID   int       `json:"from_account_id"`
+	FromAccountName string    `json:"from_account_name"` // Para exibição
+	ToAccountID     int       `json:"to_account_id"`
+	ToAccountName   string    `json:"to_account_name"` // Para exibição
+	Amount          Money     `json:"amount"`
+	Date            time.Time `json:"date"`
+	Description     string    `json:"description"`
+	GoalID          int       `json:"goal_id"` // Meta de economia do aporte (0 = nenhuma)
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// AccountEntry é uma linha do extrato de uma conta, com o saldo depois do movimento
+type AccountEntry struct {
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Kind        string    `json:"kind"` // "receita", "despesa" ou "transferencia"
+	Category    string    `json:"category"`
+	Amount      Money     `json:"amount"`  // Positivo = entrada, negativo = saída
+	Balance     Money     `json:"balance"` // Saldo corrente depois do movimento
+	ExpenseID   int       `json:"expense_id"`
+	TransferID  int       `json:"transfer_id"`
+}
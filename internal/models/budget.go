@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Budget é o limite mensal de despesas de uma categoria
+// Com Rollover, o que sobra do limite em um mês é somado ao limite do mês seguinte
+type Budget struct {
+	Category   string    `json:"category"`
+	Amount     Money     `json:"amount"`      // Limite mensal
+	Rollover   bool      `json:"rollover"`    // Acumula a sobra dos meses anteriores
+	StartMonth string    `json:"start_month"` // Primeiro mês do orçamento ("2026-02"), início do acúmulo
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Tipos de categoria (mesmos valores do tipo do lançamento)
+const (
+	CategoryIncome  = "receita"
+	CategoryExpense = "despesa"
+)
+
+// DefaultCategoryColor é a cor usada quando a categoria não define uma
+const DefaultCategoryColor = "#8b8fa3"
+
+// Category é uma categoria de lançamentos definida pelo usuário
+// Os lançamentos, orçamentos e recorrências guardam o nome da categoria (único, sem diferenciar
+// maiúsculas); renomear ou juntar categorias atualiza esses registros
+type Category struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Color      string    `json:"color"`       // Hexadecimal, ex: "#e67e22"
+	Icon       string    `json:"icon"`        // Emoji opcional
+	ParentID   int       `json:"parent_id"`   // Categoria pai (0 = categoria principal)
+	ParentName string    `json:"parent_name"` // Para exibição
+	Type       string    `json:"type"`        // "receita" ou "despesa"
+	Usage      int       `json:"usage"`       // Lançamentos que usam a categoria (inclusive na lixeira)
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// IsSubcategory indica se a categoria está dentro de outra
+func (c Category) IsSubcategory() bool {
+	return c.ParentID > 0
+}
+
+// Label é o nome para listas e selects: ícone e recuo das subcategorias
+func (c Category) Label() string {
+	label := c.Name
+	if c.Icon != "" {
+		label = c.Icon + " " + label
+	}
+	if c.IsSubcategory() {
+		label = "↳ " + label
+	}
+	return label
+}
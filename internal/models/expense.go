@@ -3,14 +3,24 @@ package models
 import "time"
 
 type Expense struct {
-	ID          int       `json:"id"`
-	Description string    `json:"description"`
-	Amount      float64   `json:"amount"`
-	Type        string    `json:"type"`
-	Category    string    `json:"category"`
-	Payer       string    `json:"payer"` // Novo campo para o rateio
-	Date        time.Time `json:"date"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	DeletedAt   time.Time `json:"deleted_at"`
+	ID                int       `json:"id"`
+	Description       string    `json:"description"`
+	Amount            Money     `json:"amount"` // Centavos
+	Type              string    `json:"type"`
+	Category          string    `json:"category"`
+	Payer             string    `json:"payer"`               // Nome de quem pagou (para exibição)
+	PayerUserID       int       `json:"payer_user_id"`       // Membro que pagou (0 = fora do rateio)
+	RecurrenceID      int       `json:"recurrence_id"`       // Recorrência que gerou o lançamento (0 = manual)
+	AccountID         int       `json:"account_id"`          // Conta de onde o dinheiro saiu ou para onde entrou
+	AccountName       string    `json:"account_name"`        // Para exibição
+	InstallmentPlanID int       `json:"installment_plan_id"` // Compra parcelada do lançamento (0 = à vista)
+	InstallmentNumber int       `json:"installment_number"`  // Número da parcela (1 = primeira)
+	InstallmentCount  int       `json:"installment_count"`   // Total de parcelas do plano (para exibição)
+	GoalID            int       `json:"goal_id"`             // Meta de economia do aporte (0 = nenhuma)
+	FITID             string    `json:"fitid"`               // ID da transação no extrato OFX do banco ("" = manual)
+	OFXAccount        string    `json:"ofx_account"`         // Conta do extrato OFX (o FITID é único por conta)
+	Date              time.Time `json:"date"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	DeletedAt         time.Time `json:"deleted_at"`
 }
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Ordenações da lista de lançamentos
+const (
+	SortDateDesc    = "date_desc" // Mais recentes primeiro (padrão)
+	SortDateAsc     = "date_asc"
+	SortAmountDesc  = "amount_desc"
+	SortAmountAsc   = "amount_asc"
+	SortDescription = "description" // Ordem alfabética
+)
+
+// ExpenseFilter restringe a lista de lançamentos (tela inicial e exportação)
+// Campos vazios não filtram
+type ExpenseFilter struct {
+	From        time.Time `json:"from"`          // Data inicial, inclusive
+	To          time.Time `json:"to"`            // Data final, inclusive
+	Type        string    `json:"type"`          // "receita" ou "despesa"
+	Category    string    `json:"category"`      // Inclui as subcategorias
+	PayerUserID int       `json:"payer_user_id"` // Membro que pagou
+	AccountID   int       `json:"account_id"`
+	MinAmount   Money     `json:"min_amount"` // Valor mínimo, inclusive (0 = sem mínimo)
+	MaxAmount   Money     `json:"max_amount"` // Valor máximo, inclusive (0 = sem máximo)
+	Search      string    `json:"search"`     // Palavras da descrição (busca textual, sem acentos, por prefixo)
+	Sort        string    `json:"sort"`       // Uma das constantes Sort* ("" = SortDateDesc)
+}
+
+// IsEmpty indica se nenhum filtro foi informado (a ordenação não conta)
+func (f ExpenseFilter) IsEmpty() bool {
+	return f.From.IsZero() && f.To.IsZero() && f.Type == "" && f.Category == "" &&
+		f.PayerUserID == 0 && f.AccountID == 0 && f.MinAmount == 0 && f.MaxAmount == 0 && f.Search == ""
+}
+
+// ExpensePage é uma página da lista de lançamentos (paginação por cursor)
+type ExpensePage struct {
+	Expenses   []Expense `json:"expenses"`
+	NextCursor string    `json:"next_cursor"` // Cursor da próxima página ("" = última página)
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ForecastMonth é a projeção de caixa de um mês: o que deve entrar e sair e o saldo no fim do mês
+// Valores positivos entram no caixa e negativos saem
+type ForecastMonth struct {
+	Month        string `json:"month"`        // Formato "2026-11"
+	Recurring    Money  `json:"recurring"`    // Ocorrências das recorrências ativas
+	Installments Money  `json:"installments"` // Parcelas a vencer fora do cartão
+	Invoices     Money  `json:"invoices"`     // Faturas de cartão em aberto que vencem no mês
+	Average      Money  `json:"average"`      // Média histórica por categoria (sem recorrências e parcelas)
+	Net          Money  `json:"net"`          // Soma das fontes acima
+	Balance      Money  `json:"balance"`      // Saldo projetado no fim do mês
+}
+
+// Forecast é a projeção do saldo em caixa mês a mês, a partir do saldo de hoje
+// das contas que não são cartão (a dívida do cartão entra pelas faturas)
+type Forecast struct {
+	Today   time.Time       `json:"today"`
+	Opening Money           `json:"opening"` // Saldo de hoje das contas fora os cartões
+	Months  []ForecastMonth `json:"months"`  // Começa no mês atual (só o que falta dele)
+}
+
+// NegativeMonth retorna o primeiro mês projetado com saldo negativo, ou nil
+func (f Forecast) NegativeMonth() *ForecastMonth {
+	for i := range f.Months {
+		if f.Months[i].Balance < 0 {
+			return &f.Months[i]
+		}
+	}
+	return nil
+}
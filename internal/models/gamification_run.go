@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// GamificationRun registra o processamento de pontos e conquistas de um mês
+type GamificationRun struct {
+	Month       string       `json:"month"` // Formato "2026-02"
+	ProcessedAt time.Time    `json:"processed_at"`
+	Deltas      []PointDelta `json:"deltas"`
+
+	// Conquistas do mês, gravadas junto com a execução (não são lidas de volta por FindByMonth)
+	Achievements []RunAchievement `json:"-"`
+}
+
+// RunAchievement é uma conquista atribuída a um membro durante uma execução
+type RunAchievement struct {
+	UserID int
+	Name   string // Nome da conquista (ex: "Mecenas")
+}
+
+// PointDelta é um ajuste de pontos aplicado a um membro durante uma execução
+type PointDelta struct {
+	UserID   int    `json:"user_id"`
+	UserName string `json:"user_name"` // Para exibição
+	Points   int    `json:"points"`
+	Reason   string `json:"reason"` // Código do motivo (ex: "top_creditor")
+}
+
+// ReasonLabel retorna a descrição do motivo para exibição
+func (d PointDelta) ReasonLabel() string {
+	return PointReasonLabel(d.Reason)
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// GoalAchievement é a conquista dada ao responsável quando a meta é concluída
+const GoalAchievement = "Meta Cumprida"
+
+// Goal é uma meta de economia ("reserva de emergência", "viagem dezembro")
+// Os aportes são lançamentos e transferências ligados à meta: despesas e transferências
+// somam, receitas ligadas à meta são resgates e subtraem
+type Goal struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	Target        Money     `json:"target"`        // Valor a juntar
+	Deadline      time.Time `json:"deadline"`      // Prazo
+	OwnerUserID   int       `json:"owner_user_id"` // Responsável, que ganha a conquista (0 = ninguém)
+	OwnerName     string    `json:"owner_name"`    // Para exibição
+	Saved         Money     `json:"saved"`         // Soma dos aportes
+	Contributions int       `json:"contributions"` // Quantidade de aportes
+	CompletedAt   time.Time `json:"completed_at"`  // Zero = ainda não concluída
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Calculados para hoje por Plan
+	Remaining  Money   `json:"remaining"`   // Quanto falta para o valor da meta
+	Percent    float64 `json:"percent"`     // Saved / Target
+	BarPercent float64 `json:"bar_percent"` // Percent limitado a 100 (largura da barra)
+	MonthsLeft int     `json:"months_left"` // Meses até o prazo, contando o atual (0 = prazo vencido)
+	Monthly    Money   `json:"monthly"`     // Aporte mensal necessário para chegar no prazo
+}
+
+// IsCompleted indica se a meta já foi concluída (mesmo que um resgate tenha baixado o valor depois)
+func (g Goal) IsCompleted() bool {
+	return !g.CompletedAt.IsZero()
+}
+
+// IsOverdue indica se o prazo passou sem a meta ser concluída
+func (g Goal) IsOverdue() bool {
+	return !g.IsCompleted() && g.MonthsLeft == 0 && g.Remaining > 0
+}
+
+// Plan calcula o progresso e o aporte mensal necessário na data informada
+// O aporte divide o que falta pelos meses até o prazo (o mês atual conta), arredondando
+// para cima; com o prazo vencido, o que falta é cobrado de uma vez
+func (g *Goal) Plan(today time.Time) {
+	g.Remaining = g.Target - g.Saved
+	if g.Remaining < 0 {
+		g.Remaining = 0
+	}
+	g.Percent = 0
+	if g.Target > 0 {
+		g.Percent = float64(g.Saved) / float64(g.Target) * 100
+	}
+	g.BarPercent = g.Percent
+	if g.BarPercent > 100 {
+		g.BarPercent = 100
+	}
+	if g.BarPercent < 0 {
+		g.BarPercent = 0
+	}
+
+	today = dateOnly(today)
+	g.MonthsLeft = 0
+	if !g.Deadline.Before(today) {
+		g.MonthsLeft = (g.Deadline.Year()-today.Year())*12 + int(g.Deadline.Month()-today.Month()) + 1
+	}
+	switch {
+	case g.Remaining == 0 || g.IsCompleted():
+		g.Monthly = 0
+	case g.MonthsLeft == 0:
+		g.Monthly = g.Remaining
+	default:
+		g.Monthly = (g.Remaining + Money(g.MonthsLeft) - 1) / Money(g.MonthsLeft)
+	}
+}
+
+// GoalContribution é um aporte (ou resgate) de uma meta
+type GoalContribution struct {
+	Date        time.Time `json:"date"`
+	Kind        string    `json:"kind"`        // "lancamento" ou "transferencia"
+	ExpenseID   int       `json:"expense_id"`  // Lançamento do aporte (0 = transferência)
+	TransferID  int       `json:"transfer_id"` // Transferência do aporte (0 = lançamento)
+	Description string    `json:"description"`
+	Account     string    `json:"account"` // Conta do lançamento ou destino da transferência
+	Amount      Money     `json:"amount"`  // Positivo = aporte, negativo = resgate
+}
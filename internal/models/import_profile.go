@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Modos de leitura do valor em um extrato CSV
+const (
+	AmountSigned = "signed" // Uma coluna de valor: negativo = despesa, positivo = receita
+	AmountSplit  = "split"  // Colunas separadas de débito (despesa) e crédito (receita)
+)
+
+// ImportProfile é o mapeamento de colunas do CSV de um banco, salvo para reaproveitar
+// Colunas são indicadas pelo número (1 = primeira) ou pelo nome no cabeçalho
+type ImportProfile struct {
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`      // Ex: "Nubank", "Itaú conta corrente"
+	Delimiter         string    `json:"delimiter"` // "," ";" "\t" ou "" para detectar
+	HasHeader         bool      `json:"has_header"`
+	DateColumn        string    `json:"date_column"`
+	DateFormat        string    `json:"date_format"` // Layout Go, ex: "02/01/2006"
+	DescriptionColumn string    `json:"description_column"`
+	AmountMode        string    `json:"amount_mode"`
+	AmountColumn      string    `json:"amount_column"` // AmountSigned
+	DebitColumn       string    `json:"debit_column"`  // AmountSplit
+	CreditColumn      string    `json:"credit_column"` // AmountSplit
+	DecimalComma      bool      `json:"decimal_comma"` // "1.234,56" em vez de "1,234.56"
+	Negate            bool      `json:"negate"`        // Inverte o sinal (faturas com compras positivas)
+	CreatedAt         time.Time `json:"created_at"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Onde fica a sobra de centavos da divisão do total em parcelas
+const (
+	RemainderFirst = "first" // Na primeira parcela
+	RemainderLast  = "last"  // Na última parcela
+)
+
+// InstallmentPlan é uma compra parcelada ("R$ 1.200 em 10x"): o ExpenseService grava uma
+// despesa por parcela, mês a mês a partir da primeira, ligadas ao plano
+type InstallmentPlan struct {
+	ID           int       `json:"id"`
+	Description  string    `json:"description"`
+	Total        Money     `json:"total"`        // Valor da compra (centavos)
+	Installments int       `json:"installments"` // Quantidade de parcelas
+	Category     string    `json:"category"`
+	Payer        string    `json:"payer"`         // Nome de quem paga (para exibição)
+	PayerUserID  int       `json:"payer_user_id"` // Membro que paga (0 = fora do rateio)
+	AccountID    int       `json:"account_id"`    // Conta das parcelas
+	AccountName  string    `json:"account_name"`  // Para exibição
+	FirstDate    time.Time `json:"first_date"`    // Data da primeira parcela
+	Remainder    string    `json:"remainder"`     // RemainderFirst ou RemainderLast
+	Paid         int       `json:"paid"`          // Parcelas ativas com data até hoje (para exibição)
+	Remaining    int       `json:"remaining"`     // Parcelas ativas depois de hoje (para exibição)
+	Outstanding  Money     `json:"outstanding"`   // Soma das parcelas ativas depois de hoje
+	Cancelled    bool      `json:"cancelled"`     // As parcelas restantes foram canceladas
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SplitInstallments divide o total em n parcelas de centavos exatos; a sobra da divisão
+// fica na primeira ou na última parcela e as parcelas somam exatamente o total
+func SplitInstallments(total Money, n int, remainder string) []Money {
+	parts := make([]Money, n)
+	if n <= 0 {
+		return parts
+	}
+	base := total / Money(n)
+	for i := range parts {
+		parts[i] = base
+	}
+	rest := total - base*Money(n)
+	if remainder == RemainderFirst {
+		parts[0] += rest
+	} else {
+		parts[n-1] += rest
+	}
+	return parts
+}
+
+// InstallmentDates retorna as datas das n parcelas, todo mês no dia da primeira
+// (meses mais curtos usam o último dia, e o dia original volta no mês seguinte)
+func InstallmentDates(first time.Time, n int) []time.Time {
+	first = dateOnly(first)
+	dates := make([]time.Time, n)
+	for i := range dates {
+		dates[i] = monthDay(first.Year(), first.Month()+time.Month(i), first.Day())
+	}
+	return dates
+}
+
+// InstallmentLabel é o rótulo "3/10" de uma parcela ("" fora de compra parcelada)
+func (e Expense) InstallmentLabel() string {
+	if e.InstallmentPlanID == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", e.InstallmentNumber, e.InstallmentCount)
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitInstallments(t *testing.T) {
+	tests := []struct {
+		total     Money
+		n         int
+		remainder string
+		want      []Money
+	}{
+		{120000, 10, RemainderLast, []Money{12000, 12000, 12000, 12000, 12000, 12000, 12000, 12000, 12000, 12000}},
+		{10000, 3, RemainderLast, []Money{3333, 3333, 3334}},
+		{10000, 3, RemainderFirst, []Money{3334, 3333, 3333}},
+		{99999, 7, RemainderFirst, []Money{14289, 14285, 14285, 14285, 14285, 14285, 14285}},
+		{2, 2, RemainderLast, []Money{1, 1}},
+		{5, 0, RemainderLast, []Money{}},
+	}
+	for _, tt := range tests {
+		got := SplitInstallments(tt.total, tt.n, tt.remainder)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitInstallments(%d, %d, %s) = %v, quer %v", tt.total, tt.n, tt.remainder, got, tt.want)
+		}
+		var sum Money
+		for _, part := range got {
+			sum += part
+		}
+		if tt.n > 0 && sum != tt.total {
+			t.Errorf("SplitInstallments(%d, %d) soma %d", tt.total, tt.n, sum)
+		}
+	}
+}
+
+func TestInstallmentDates(t *testing.T) {
+	tests := []struct {
+		first string
+		n     int
+		want  []string
+	}{
+		{"2026-01-15", 3, []string{"2026-01-15", "2026-02-15", "2026-03-15"}},
+		{"2026-01-31", 4, []string{"2026-01-31", "2026-02-28", "2026-03-31", "2026-04-30"}},
+		{"2026-11-30", 4, []string{"2026-11-30", "2026-12-30", "2027-01-30", "2027-02-28"}},
+		{"2027-12-29", 3, []string{"2027-12-29", "2028-01-29", "2028-02-29"}},
+	}
+	for _, tt := range tests {
+		first, _ := time.Parse("2006-01-02", tt.first)
+		got := []string{}
+		for _, date := range InstallmentDates(first.Add(14*time.Hour), tt.n) {
+			got = append(got, date.Format("2006-01-02"))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("InstallmentDates(%s, %d) = %v, quer %v", tt.first, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Situações da fatura do cartão
+const (
+	InvoiceOpen   = "aberta"  // Ainda recebe compras
+	InvoiceClosed = "fechada" // Fechou e aguarda pagamento
+	InvoicePaid   = "paga"    // Paga com uma transferência da conta corrente
+)
+
+// Invoice é a fatura de um cartão de crédito: as compras entre dois fechamentos,
+// que saem do caixa de uma vez no vencimento
+type Invoice struct {
+	ID          int       `json:"id"`
+	AccountID   int       `json:"account_id"`
+	AccountName string    `json:"account_name"` // Para exibição
+	Month       string    `json:"month"`        // Mês do vencimento ("2026-11")
+	ClosingDate time.Time `json:"closing_date"`
+	DueDate     time.Time `json:"due_date"`
+	Total       Money     `json:"total"`       // Despesas menos estornos (receitas) da fatura
+	Count       int       `json:"count"`       // Lançamentos na fatura
+	TransferID  int       `json:"transfer_id"` // Transferência que pagou a fatura (0 = não paga)
+	PaidAmount  Money     `json:"paid_amount"`
+	PaidDate    time.Time `json:"paid_date"`
+	Status      string    `json:"status"` // Uma das constantes Invoice*, calculada para hoje
+}
+
+// StatusOn calcula a situação da fatura na data informada
+func (i Invoice) StatusOn(today time.Time) string {
+	switch {
+	case i.TransferID > 0:
+		return InvoicePaid
+	case dateOnly(today).Before(i.ClosingDate):
+		return InvoiceOpen
+	default:
+		return InvoiceClosed
+	}
+}
+
+// Pending é o que falta pagar: o total, menos o pagamento (compras lançadas depois do pagamento)
+func (i Invoice) Pending() Money {
+	return i.Total - i.PaidAmount
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Money é um valor monetário em centavos (R$ 1,00 = 100)
+// Inteiros evitam resíduos de ponto flutuante como -0.0000001 nas cotas e nos saldos
+type Money int64
+
+// ErrInvalidMoney indica um valor monetário que não pôde ser interpretado
+var ErrInvalidMoney = errors.New("valor inválido")
+
+// maxParsedReais é o maior valor aceito por ParseMoney (quase R$ 1 trilhão),
+// bem abaixo do limite do int64 em centavos
+const maxParsedReais = 999_999_999_999
+
+// ParseMoney interpreta valores digitados em formato brasileiro ("1.234,56", "R$ 10,5")
+// e também o formato enviado por campos numéricos do navegador ("1234.56")
+// Ponto de milhar só é aceito junto com vírgula decimal ou em vários grupos ("1.234.567"):
+// "12.345" é ambíguo e retorna ErrInvalidMoney
+func ParseMoney(input string) (Money, error) {
+	s := strings.ReplaceAll(input, "\u00a0", "")
+	s = strings.ReplaceAll(s, " ", "")
+
+	// O sinal pode vir antes ou depois do prefixo ("-R$ 12,50" ou "R$ -12,50"), mas só uma vez
+	negative := false
+	if strings.HasPrefix(s, "-") {
+		negative = true
+		s = s[1:]
+	}
+	s = strings.TrimPrefix(s, "R$")
+	if !negative && strings.HasPrefix(s, "-") {
+		negative = true
+		s = s[1:]
+	}
+	if s == "" {
+		return 0, ErrInvalidMoney
+	}
+
+	switch {
+	case strings.Contains(s, ","):
+		// Formato brasileiro: ponto é milhar, vírgula é decimal
+		whole, frac, _ := strings.Cut(s, ",")
+		if strings.Contains(whole, ".") && !validThousands(whole) {
+			return 0, ErrInvalidMoney
+		}
+		s = strings.ReplaceAll(whole, ".", "") + "." + frac
+	case strings.Count(s, ".") > 1:
+		// Apenas pontos de milhar ("1.234.567")
+		if !validThousands(s) {
+			return 0, ErrInvalidMoney
+		}
+		s = strings.ReplaceAll(s, ".", "")
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	if len(frac) > 2 || !onlyDigits(whole) || !onlyDigits(frac) {
+		return 0, ErrInvalidMoney
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+
+	reais, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || reais > maxParsedReais {
+		return 0, ErrInvalidMoney
+	}
+	cents, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidMoney
+	}
+
+	value := Money(reais*100 + cents)
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+// onlyDigits indica se a string tem apenas dígitos ASCII (sem sinal, espaço ou separador)
+func onlyDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validThousands confere os grupos de milhar: o primeiro com 1 a 3 dígitos, os demais com 3
+func validThousands(s string) bool {
+	groups := strings.Split(s, ".")
+	for i, g := range groups {
+		if g == "" || len(g) > 3 || (i > 0 && len(g) != 3) {
+			return false
+		}
+	}
+	return true
+}
+
+// MoneyFromFloat converte um valor em reais (float) para centavos, arredondando
+func MoneyFromFloat(reais float64) Money {
+	return Money(math.Round(reais * 100))
+}
+
+// Float retorna o valor em reais (para gráficos e cálculos de proporção)
+func (m Money) Float() float64 {
+	return float64(m) / 100
+}
+
+// Abs retorna o valor absoluto
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// String formata no padrão brasileiro, sem símbolo: "1.234,56" / "-0,50"
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	reais := strconv.FormatInt(cents/100, 10)
+	var grouped strings.Builder
+	for i, digit := range reais {
+		if i > 0 && (len(reais)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s,%02d", sign, grouped.String(), cents%100)
+}
+
+// Input formata para campos de formulário, sem separador de milhar: "1234,56"
+func (m Money) Input() string {
+	return strings.ReplaceAll(m.String(), ".", "")
+}
+
+// MarshalJSON expõe o valor em reais (número), como antes da migração para centavos
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Float())
+}
+
+// Allocate divide o valor proporcionalmente aos pesos, em centavos exatos
+// Cada parte recebe o piso da sua fração; os centavos que sobram vão, um a um, para as
+// maiores sobras fracionárias (empate: menor índice). A soma das partes é sempre igual ao total
+// Pesos zero, negativos ou não finitos (NaN, ±Inf) ficam fora da divisão
+func (m Money) Allocate(weights []float64) []Money {
+	parts := make([]Money, len(weights))
+
+	usable := func(w float64) bool {
+		return w > 0 && !math.IsInf(w, 0)
+	}
+	totalWeight := 0.0
+	for _, w := range weights {
+		if usable(w) {
+			totalWeight += w
+		}
+	}
+	if totalWeight <= 0 || math.IsInf(totalWeight, 0) || m == 0 {
+		return parts
+	}
+
+	type remainder struct {
+		index int
+		frac  float64
+	}
+	remainders := make([]remainder, 0, len(weights))
+
+	total := int64(m)
+	allocated := int64(0)
+	for i, w := range weights {
+		if !usable(w) {
+			continue
+		}
+		exact := float64(total) * w / totalWeight
+		floor := int64(math.Floor(exact))
+		parts[i] = Money(floor)
+		allocated += floor
+		remainders = append(remainders, remainder{index: i, frac: exact - float64(floor)})
+	}
+
+	// Os pisos ficam abaixo do total em no máximo um centavo por parte
+	sort.SliceStable(remainders, func(a, b int) bool {
+		return remainders[a].frac > remainders[b].frac
+	})
+	for k := 0; k < len(remainders) && allocated < total; k++ {
+		parts[remainders[k].index]++
+		allocated++
+	}
+	return parts
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Money
+	}{
+		{"10", 1000},
+		{"10,5", 1050},
+		{"10,50", 1050},
+		{"0,01", 1},
+		{"1.234,56", 123456},
+		{"1.234.567,89", 123456789},
+		{"1.234.567", 123456700},
+		{"R$ 1.234,56", 123456},
+		{"R$10,00", 1000},
+		{"-12,50", -1250},
+		{"R$ -12,50", -1250},
+		{"-R$ 12,50", -1250},
+		{"-R$12,50", -1250},
+		{"R$\u00a01.234,56", 123456},
+		{"999.999.999.999,99", 99999999999999},
+		{"1234.56", 123456},
+		{"1234.5", 123450},
+		{"0.5", 50},
+		{"  42  ", 4200},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.input)
+		if err != nil {
+			t.Errorf("ParseMoney(%q) retornou erro: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, quer %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseMoneyInvalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"   ",
+		"R$",
+		"-",
+		"R$ -",
+		"abc",
+		"12.345", // ambíguo: milhar sem vírgula decimal
+		"0.123",
+		"1,234",
+		"10,555",
+		"1,234.56",
+		"1.23,45",
+		"12.34.567",
+		"1..234",
+		"--5",
+		"-R$ -12,50",
+		"R$ --12,50",
+		"12,50-",
+		"1.+5",
+		"1,+5",
+		"1,-5",
+		"+5",
+		"1,5a",
+		"1.000.000.000.000,00", // acima do limite
+		"92233720368547758,07", // estouraria o int64 em centavos
+		"99999999999999999999",
+	}
+	for _, input := range inputs {
+		if got, err := ParseMoney(input); !errors.Is(err, ErrInvalidMoney) {
+			t.Errorf("ParseMoney(%q) = %d, %v; quer ErrInvalidMoney", input, got, err)
+		}
+	}
+}
+
+func sumMoney(parts []Money) Money {
+	var sum Money
+	for _, p := range parts {
+		sum += p
+	}
+	return sum
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   Money
+		weights []float64
+		want    []Money
+	}{
+		{"partes iguais", 1000, []float64{1, 1, 1}, []Money{334, 333, 333}},
+		{"pesos diferentes", 1000, []float64{1, 2}, []Money{333, 667}},
+		{"total negativo", -1000, []float64{1, 1, 1}, []Money{-333, -333, -334}},
+		{"peso zero fica fora", 1000, []float64{1, 0, 1}, []Money{500, 0, 500}},
+		{"peso negativo fica fora", 1000, []float64{-1, 1}, []Money{0, 1000}},
+		{"NaN fica fora", 1000, []float64{1, math.NaN()}, []Money{1000, 0}},
+		{"+Inf fica fora", 1000, []float64{1, math.Inf(1)}, []Money{1000, 0}},
+		{"-Inf fica fora", 1000, []float64{math.Inf(-1), 1}, []Money{0, 1000}},
+		{"sem pesos válidos", 1000, []float64{0, math.NaN()}, []Money{0, 0}},
+		{"total zero", 0, []float64{1, 1}, []Money{0, 0}},
+		{"sem pesos", 1000, nil, []Money{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.total.Allocate(tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate(%d, %v) = %v, quer %v", tt.total, tt.weights, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocateSumEqualsTotal(t *testing.T) {
+	weights := [][]float64{
+		{1, 1, 1},
+		{1, 1, 1, 1, 1, 1, 1},
+		{0.3, 0.3, 0.4},
+		{0.1, 2.5, 7, 0.01},
+		{1e-9, 1},
+		{1e300, 1e300},
+	}
+	totals := []Money{1, 2, 99, 100, 1001, 123457, -1, -1001, -123457}
+	for _, w := range weights {
+		for _, total := range totals {
+			parts := total.Allocate(w)
+			if len(parts) != len(w) {
+				t.Fatalf("Allocate(%d, %v) retornou %d partes", total, w, len(parts))
+			}
+			if sum := sumMoney(parts); sum != total {
+				t.Errorf("Allocate(%d, %v) = %v, soma %d", total, w, parts, sum)
+			}
+		}
+	}
+}
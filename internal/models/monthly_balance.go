@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // MonthlyBalance armazena o balanço mensal de cada membro
 // Balance positivo = Crédito (pagou mais que a cota)
 // Balance negativo = Débito (pagou menos que a cota)
@@ -7,7 +9,19 @@ type MonthlyBalance struct {
 	UserID     int     `json:"user_id"`
 	UserName   string  `json:"user_name"` // Para exibição
 	Month      string  `json:"month"`     // Formato "2026-02"
-	TotalPaid  float64 `json:"total_paid"`
-	ShareValue float64 `json:"share_value"` // Cota = TotalMês / 6
-	Balance    float64 `json:"balance"`     // TotalPaid - ShareValue
+	TotalPaid  Money   `json:"total_paid"`
+	ShareValue Money   `json:"share_value"` // Cota do membro (proporcional à participação no mês)
+	Balance    Money   `json:"balance"`     // TotalPaid - ShareValue
+	Weight     float64 `json:"weight"`      // Fator de participação no mês (-1 = snapshot antigo, sem fator)
+}
+
+// MonthClosing registra o fechamento de um mês do rateio
+// Depois de fechado, o mês passa a ser exibido a partir do snapshot em monthly_balances
+type MonthClosing struct {
+	Month          string    `json:"month"` // Formato "2026-02"
+	TotalSpent     Money     `json:"total_spent"`
+	MemberCount    int       `json:"member_count"`
+	SharePerPerson Money     `json:"share_per_person"` // Cota cheia do mês
+	CarryOver      bool      `json:"carry_over"`       // Saldo em aberto passa para os meses seguintes
+	ClosedAt       time.Time `json:"closed_at"`
 }
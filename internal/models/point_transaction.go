@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Códigos de motivo dos lançamentos de pontos
+const (
+	ReasonPaidSnack       = "paid_snack"       // Pagou o lanche do dia
+	ReasonAboveAverage    = "above_average"    // Pagou acima da média do mês
+	ReasonTopCreditor     = "top_creditor"     // Maior crédito do mês
+	ReasonTopDebtor       = "top_debtor"       // Maior débito do mês
+	ReasonNoParticipation = "no_participation" // Não participou de nenhuma compra no mês
+	ReasonPurchaseRevoked = "purchase_revoked" // Estorno dos pontos de uma compra removida ou transferida
+	ReasonMonthReopened   = "month_reopened"   // Estorno do processamento de um mês reaberto
+	ReasonLegacyBalance   = "legacy_balance"   // Saldo anterior à criação do extrato
+)
+
+// PointTransaction é um lançamento no extrato de pontos de um membro
+// O total de pontos de cada membro é sempre a soma dos seus lançamentos
+type PointTransaction struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	UserName   string    `json:"user_name"` // Para exibição
+	Delta      int       `json:"delta"`
+	Reason     string    `json:"reason"`
+	Month      string    `json:"month"`       // Mês de referência ("" quando não se aplica)
+	PurchaseID int       `json:"purchase_id"` // Compra relacionada (0 quando não há)
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ReasonLabel retorna a descrição do motivo para exibição
+func (t PointTransaction) ReasonLabel() string {
+	return PointReasonLabel(t.Reason)
+}
+
+// PointReasonLabel traduz um código de motivo para exibição
+func PointReasonLabel(reason string) string {
+	switch reason {
+	case ReasonPaidSnack:
+		return "💰 Pagou o lanche"
+	case ReasonAboveAverage:
+		return "🔥 Pagou acima da média"
+	case ReasonTopCreditor:
+		return "🤝 Maior crédito do mês"
+	case ReasonTopDebtor:
+		return "❌ Maior débito do mês"
+	case ReasonNoParticipation:
+		return "😴 Não participou no mês"
+	case ReasonPurchaseRevoked:
+		return "↩️ Estorno de compra"
+	case ReasonMonthReopened:
+		return "🔓 Estorno de mês reaberto"
+	case ReasonLegacyBalance:
+		return "📦 Saldo anterior"
+	}
+	return reason
+}
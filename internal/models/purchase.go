@@ -7,8 +7,37 @@ type Purchase struct {
 	ID        int       `json:"id"`
 	UserID    int       `json:"user_id"`
 	UserName  string    `json:"user_name"` // Para exibição (preenchido via JOIN)
-	Amount    float64   `json:"amount"`
+	Amount    Money     `json:"amount"`
 	Date      time.Time `json:"date"`
 	Month     string    `json:"month"` // Formato "2026-02" para agrupamento mensal
 	CreatedAt time.Time `json:"created_at"`
+	DeletedAt time.Time `json:"deleted_at"`
+
+	// Participants define quem consumiu a compra; vazio = dividida entre todos do mês
+	Participants []PurchaseParticipant `json:"participants"`
+}
+
+// PurchaseParticipant é um membro que consumiu uma compra
+// Amount > 0 é o valor fixo do item dele; senão paga o restante proporcional ao Weight
+type PurchaseParticipant struct {
+	PurchaseID int     `json:"purchase_id"`
+	UserID     int     `json:"user_id"`
+	UserName   string  `json:"user_name"` // Para exibição
+	Weight     float64 `json:"weight"`
+	Amount     Money   `json:"amount"`
+}
+
+// SplitAmongAll indica se a compra é dividida entre todos os membros do mês
+func (p Purchase) SplitAmongAll() bool {
+	return len(p.Participants) == 0
+}
+
+// Participant retorna o participante da compra com o ID informado (nil se não participa)
+func (p Purchase) Participant(userID int) *PurchaseParticipant {
+	for i := range p.Participants {
+		if p.Participants[i].UserID == userID {
+			return &p.Participants[i]
+		}
+	}
+	return nil
 }
@@ -0,0 +1,126 @@
+package models
+
+import "time"
+
+// Frequências aceitas por uma recorrência
+const (
+	FrequencyMonthly = "monthly" // Todo mês, no dia DayOfMonth (ajustado ao fim do mês)
+	FrequencyWeekly  = "weekly"  // A cada 7 dias a partir do início
+	FrequencyYearly  = "yearly"  // Todo ano, no dia e mês do início
+	FrequencyDays    = "days"    // A cada Interval dias a partir do início
+)
+
+// Recurrence é o modelo de um lançamento que se repete (salário, aluguel, assinaturas)
+// O ExpenseService gera as despesas/receitas reais quando cada ocorrência vence.
+// Alterar, pausar ou encerrar a recorrência não mexe nos lançamentos já gerados
+type Recurrence struct {
+	ID          int       `json:"id"`
+	Description string    `json:"description"`
+	Amount      Money     `json:"amount"`
+	Type        string    `json:"type"`
+	Category    string    `json:"category"`
+	Payer       string    `json:"payer"`         // Nome de quem paga (para exibição)
+	PayerUserID int       `json:"payer_user_id"` // Membro que paga (0 = fora do rateio)
+	AccountID   int       `json:"account_id"`    // Conta dos lançamentos gerados
+	AccountName string    `json:"account_name"`  // Para exibição
+	Frequency   string    `json:"frequency"`
+	Interval    int       `json:"interval"`     // Dias entre ocorrências (FrequencyDays)
+	DayOfMonth  int       `json:"day_of_month"` // 1-31; meses mais curtos usam o último dia
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`  // Zero = sem fim
+	NextDate    time.Time `json:"next_date"` // Próxima ocorrência ainda não gerada
+	Paused      bool      `json:"paused"`
+	Generated   int       `json:"generated"` // Lançamentos já gerados (para exibição)
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IsEnded indica se a recorrência não gera mais lançamentos (passou da data final)
+func (r Recurrence) IsEnded() bool {
+	return !r.EndDate.IsZero() && r.NextDate.After(r.EndDate)
+}
+
+// Status retorna a situação da recorrência para exibição
+func (r Recurrence) Status() string {
+	switch {
+	case r.IsEnded():
+		return "Encerrada"
+	case r.Paused:
+		return "Pausada"
+	default:
+		return "Ativa"
+	}
+}
+
+// FrequencyLabel descreve a frequência em português
+func (r Recurrence) FrequencyLabel() string {
+	switch r.Frequency {
+	case FrequencyMonthly:
+		return "Mensal"
+	case FrequencyWeekly:
+		return "Semanal"
+	case FrequencyYearly:
+		return "Anual"
+	case FrequencyDays:
+		return "A cada dias"
+	}
+	return r.Frequency
+}
+
+// First retorna a primeira ocorrência da recorrência (a partir de StartDate)
+func (r Recurrence) First() time.Time {
+	start := dateOnly(r.StartDate)
+	if r.Frequency != FrequencyMonthly {
+		return start
+	}
+	first := monthDay(start.Year(), start.Month(), r.DayOfMonth)
+	if first.Before(start) {
+		first = monthDay(start.Year(), start.Month()+1, r.DayOfMonth)
+	}
+	return first
+}
+
+// After retorna a ocorrência seguinte a uma ocorrência
+// Meses e anos são calculados a partir do dia configurado, então um dia 31 que virou 28/02
+// volta a ser 31/03 no mês seguinte
+func (r Recurrence) After(occurrence time.Time) time.Time {
+	switch r.Frequency {
+	case FrequencyMonthly:
+		return monthDay(occurrence.Year(), occurrence.Month()+1, r.DayOfMonth)
+	case FrequencyYearly:
+		return monthDay(occurrence.Year()+1, r.StartDate.Month(), r.DayOfMonth)
+	case FrequencyWeekly:
+		return occurrence.AddDate(0, 0, 7)
+	default:
+		interval := r.Interval
+		if interval < 1 {
+			interval = 1
+		}
+		return occurrence.AddDate(0, 0, interval)
+	}
+}
+
+// FirstOnOrAfter retorna a primeira ocorrência na data informada ou depois dela
+func (r Recurrence) FirstOnOrAfter(date time.Time) time.Time {
+	date = dateOnly(date)
+	occurrence := r.First()
+	for occurrence.Before(date) {
+		occurrence = r.After(occurrence)
+	}
+	return occurrence
+}
+
+// monthDay monta a data do dia informado no mês, usando o último dia quando o mês é mais curto
+// (o mês pode passar de 12, como em time.Date)
+func monthDay(year int, month time.Month, day int) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// dateOnly descarta o horário, mantendo a data do calendário
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
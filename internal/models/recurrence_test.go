@@ -0,0 +1,72 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceSchedule(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name string
+		rec  Recurrence
+		want []string // Primeiras ocorrências a partir do início
+	}{
+		{"mensal no dia 5", Recurrence{Frequency: FrequencyMonthly, DayOfMonth: 5, StartDate: day("2026-01-05")},
+			[]string{"2026-01-05", "2026-02-05", "2026-03-05"}},
+		{"mensal com dia já passado no mês de início", Recurrence{Frequency: FrequencyMonthly, DayOfMonth: 5, StartDate: day("2026-01-20")},
+			[]string{"2026-02-05", "2026-03-05", "2026-04-05"}},
+		{"dia 31 usa o último dia e volta ao 31", Recurrence{Frequency: FrequencyMonthly, DayOfMonth: 31, StartDate: day("2026-01-01")},
+			[]string{"2026-01-31", "2026-02-28", "2026-03-31", "2026-04-30", "2026-05-31"}},
+		{"dia 29 em ano bissexto", Recurrence{Frequency: FrequencyMonthly, DayOfMonth: 29, StartDate: day("2028-01-29")},
+			[]string{"2028-01-29", "2028-02-29", "2028-03-29"}},
+		{"virada do ano", Recurrence{Frequency: FrequencyMonthly, DayOfMonth: 15, StartDate: day("2026-11-15")},
+			[]string{"2026-11-15", "2026-12-15", "2027-01-15"}},
+		{"semanal", Recurrence{Frequency: FrequencyWeekly, StartDate: day("2026-02-24")},
+			[]string{"2026-02-24", "2026-03-03", "2026-03-10"}},
+		{"a cada 10 dias", Recurrence{Frequency: FrequencyDays, Interval: 10, StartDate: day("2026-02-25")},
+			[]string{"2026-02-25", "2026-03-07", "2026-03-17"}},
+		{"anual em 29/02", Recurrence{Frequency: FrequencyYearly, DayOfMonth: 29, StartDate: day("2028-02-29")},
+			[]string{"2028-02-29", "2029-02-28", "2030-02-28", "2031-02-28", "2032-02-29"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			occurrence := tt.rec.First()
+			for range tt.want {
+				got = append(got, occurrence.Format("2006-01-02"))
+				occurrence = tt.rec.After(occurrence)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ocorrências = %v, quer %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceFirstOnOrAfter(t *testing.T) {
+	monthly := Recurrence{Frequency: FrequencyMonthly, DayOfMonth: 31, StartDate: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2025-12-01", "2026-01-31"}, // antes do início
+		{"2026-02-28", "2026-02-28"}, // na própria ocorrência
+		{"2026-03-01", "2026-03-31"},
+		{"2026-04-30", "2026-04-30"},
+	}
+	for _, tt := range tests {
+		date, _ := time.Parse("2006-01-02", tt.date)
+		if got := monthly.FirstOnOrAfter(date.Add(15 * time.Hour)).Format("2006-01-02"); got != tt.want {
+			t.Errorf("FirstOnOrAfter(%s) = %s, quer %s", tt.date, got, tt.want)
+		}
+	}
+}
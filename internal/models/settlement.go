@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Settlement registra uma transferência paga de um devedor para um credor
+// Acertos pagos abatem o saldo em aberto dos dois membros no mês
+type Settlement struct {
+	ID           int       `json:"id"`
+	Month        string    `json:"month"` // Formato "2026-02"
+	FromUserID   int       `json:"from_user_id"`
+	FromUserName string    `json:"from_user_name"` // Para exibição
+	ToUserID     int       `json:"to_user_id"`
+	ToUserName   string    `json:"to_user_name"` // Para exibição
+	Amount       Money     `json:"amount"`
+	PaidAt       time.Time `json:"paid_at"`
+}
@@ -4,9 +4,26 @@ import "time"
 
 // User representa um membro da equipe no sistema de rateio
 type User struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Points    int       `json:"points"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Points     int       `json:"points"`
+	JoinedAt   time.Time `json:"joined_at"`   // Início da participação (zero = desde sempre)
+	LeftAt     time.Time `json:"left_at"`     // Fim da participação (zero = ainda participa)
+	ArchivedAt time.Time `json:"archived_at"` // Zero = membro ativo
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// IsArchived indica se o membro foi arquivado
+func (u User) IsArchived() bool {
+	return !u.ArchivedAt.IsZero()
+}
+
+// MonthParticipation define o peso de um membro no rateio de um mês específico
+// Weight 0 = não participa (férias, ausência); 0.5 = meia cota; 1 = cota cheia
+type MonthParticipation struct {
+	UserID   int     `json:"user_id"`
+	UserName string  `json:"user_name"` // Para exibição
+	Month    string  `json:"month"`     // Formato "2026-02"
+	Weight   float64 `json:"weight"`
 }
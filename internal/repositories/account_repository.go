@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+	"time"
+)
+
+type AccountRepository struct {
+	db *sql.DB
+}
+
+func NewAccountRepository(db *sql.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// accountSelect lista as contas com o saldo até a data informada (primeiro parâmetro, "2006-01-02")
+// e a quantidade de movimentos
+const accountSelect = `
+	SELECT a.id, a.name, a.type, a.opening_balance,
+		a.opening_balance
+			+ COALESCE((SELECT SUM(CASE WHEN e.type = 'receita' THEN e.amount ELSE -e.amount END)
+				FROM expenses e WHERE e.account_id = a.id AND e.deleted_at IS NULL AND substr(e.date, 1, 10) <= ?1), 0)
+			+ COALESCE((SELECT SUM(t.amount) FROM transfers t WHERE t.to_account_id = a.id AND substr(t.date, 1, 10) <= ?1), 0)
+			- COALESCE((SELECT SUM(t.amount) FROM transfers t WHERE t.from_account_id = a.id AND substr(t.date, 1, 10) <= ?1), 0),
+		(SELECT count(*) FROM expenses e WHERE e.account_id = a.id)
+			+ (SELECT count(*) FROM transfers t WHERE a.id IN (t.from_account_id, t.to_account_id))
+			+ (SELECT count(*) FROM recurrences r WHERE r.account_id = a.id),
+		a.closing_day, a.due_day, a.created_at
+	FROM accounts a
+`
+
+func scanAccount(scanner rowScanner) (models.Account, error) {
+	var a models.Account
+	var createdAt string
+	err := scanner.Scan(&a.ID, &a.Name, &a.Type, &a.OpeningBalance, &a.Balance, &a.Usage, &a.ClosingDay, &a.DueDay, &createdAt)
+	a.CreatedAt = parseSQLiteTime(createdAt)
+	return a, err
+}
+
+// Create insere uma nova conta
+func (r *AccountRepository) Create(account *models.Account) error {
+	query := `INSERT INTO accounts (name, type, opening_balance, closing_day, due_day) VALUES (?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, account.Name, account.Type, account.OpeningBalance, account.ClosingDay, account.DueDay)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	account.ID = int(id)
+	return nil
+}
+
+// Update altera nome, tipo, saldo inicial e dias da fatura de uma conta
+func (r *AccountRepository) Update(account *models.Account) error {
+	query := `UPDATE accounts SET name = ?, type = ?, opening_balance = ?, closing_day = ?, due_day = ? WHERE id = ?`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, account.Name, account.Type, account.OpeningBalance, account.ClosingDay, account.DueDay, account.ID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	// Cartão que passou a ter dia de fechamento: as compras sem fatura entram nas faturas agora
+	if err := assignInvoices(tx, "e.account_id = ?", account.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindAll retorna as contas com o saldo na data informada
+func (r *AccountRepository) FindAll(asOf time.Time) ([]models.Account, error) {
+	rows, err := r.db.Query(accountSelect+` ORDER BY a.name COLLATE NOCASE`, asOf.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []models.Account
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, nil
+}
+
+// FindByID retorna uma conta com o saldo na data informada
+func (r *AccountRepository) FindByID(id int, asOf time.Time) (*models.Account, error) {
+	a, err := scanAccount(r.db.QueryRow(accountSelect+` WHERE a.id = ?2`, asOf.Format("2006-01-02"), id))
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// NameExists indica se outra conta (diferente de exceptID) já usa o nome, sem diferenciar maiúsculas
+func (r *AccountRepository) NameExists(name string, exceptID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM accounts WHERE name = ? AND id <> ?)`, name, exceptID).Scan(&exists)
+	return exists, err
+}
+
+// CountInvoices retorna quantas faturas o cartão tem
+func (r *AccountRepository) CountInvoices(id int) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT count(*) FROM invoices WHERE account_id = ?`, id).Scan(&count)
+	return count, err
+}
+
+// Delete remove uma conta
+func (r *AccountRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM accounts WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// FindEntries retorna os movimentos da conta (lançamentos ativos e transferências) em ordem de data,
+// sem o saldo corrente, que é calculado pelo service a partir do saldo inicial
+func (r *AccountRepository) FindEntries(accountID int) ([]models.AccountEntry, error) {
+	query := `
+		SELECT substr(e.date, 1, 10) AS day, e.description, e.type, e.category,
+			CASE WHEN e.type = 'receita' THEN e.amount ELSE -e.amount END, e.id, 0
+		FROM expenses e
+		WHERE e.account_id = ?1 AND e.deleted_at IS NULL
+		UNION ALL
+		SELECT substr(t.date, 1, 10), t.description, 'transferencia', '',
+			CASE WHEN t.to_account_id = ?1 THEN t.amount ELSE -t.amount END, 0, t.id
+		FROM transfers t
+		WHERE ?1 IN (t.from_account_id, t.to_account_id)
+		ORDER BY day, 6, 7
+	`
+	rows, err := r.db.Query(query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AccountEntry
+	for rows.Next() {
+		var entry models.AccountEntry
+		var day string
+		if err := rows.Scan(&day, &entry.Description, &entry.Kind, &entry.Category, &entry.Amount, &entry.ExpenseID, &entry.TransferID); err != nil {
+			return nil, err
+		}
+		entry.Date = parseSQLiteTime(day)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// CreateTransfer registra uma transferência entre contas
+// Um aporte que completa a meta a conclui na mesma transação
+func (r *AccountRepository) CreateTransfer(transfer *models.Transfer) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO transfers (from_account_id, to_account_id, amount, date, description, goal_id) VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := tx.Exec(query, transfer.FromAccountID, transfer.ToAccountID, transfer.Amount,
+		transfer.Date.Format("2006-01-02"), transfer.Description, nullID(transfer.GoalID))
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	transfer.ID = int(id)
+
+	if err := completeGoals(tx, "g.id = ?", transfer.GoalID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindTransfers retorna as transferências mais recentes
+func (r *AccountRepository) FindTransfers(limit int) ([]models.Transfer, error) {
+	query := `
+		SELECT t.id, t.from_account_id, f.name, t.to_account_id, d.name, t.amount, t.date, t.description, t.created_at
+		FROM transfers t
+		JOIN accounts f ON f.id = t.from_account_id
+		JOIN accounts d ON d.id = t.to_account_id
+		ORDER BY t.date DESC, t.id DESC
+		LIMIT ?
+	`
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []models.Transfer
+	for rows.Next() {
+		var t models.Transfer
+		var dateStr, createdAtStr string
+		if err := rows.Scan(&t.ID, &t.FromAccountID, &t.FromAccountName, &t.ToAccountID, &t.ToAccountName,
+			&t.Amount, &dateStr, &t.Description, &createdAtStr); err != nil {
+			return nil, err
+		}
+		t.Date = parseSQLiteTime(dateStr)
+		t.CreatedAt = parseSQLiteTime(createdAtStr)
+		transfers = append(transfers, t)
+	}
+	return transfers, nil
+}
+
+// DeleteTransfer remove uma transferência; se ela pagava uma fatura, a fatura volta a ficar em aberto
+func (r *AccountRepository) DeleteTransfer(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE invoices SET transfer_id = NULL WHERE transfer_id = ?`, id); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM transfers WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
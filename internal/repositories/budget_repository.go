@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+)
+
+type BudgetRepository struct {
+	db *sql.DB
+}
+
+func NewBudgetRepository(db *sql.DB) *BudgetRepository {
+	return &BudgetRepository{db: db}
+}
+
+// Save define (ou altera) o orçamento de uma categoria
+// O mês de início é mantido ao alterar, para não perder a sobra acumulada
+func (r *BudgetRepository) Save(budget *models.Budget) error {
+	query := `INSERT INTO budgets (category, amount, rollover, start_month) VALUES (?, ?, ?, ?)
+		ON CONFLICT (category) DO UPDATE SET
+			amount = excluded.amount,
+			rollover = excluded.rollover,
+			updated_at = CURRENT_TIMESTAMP`
+	_, err := r.db.Exec(query, budget.Category, budget.Amount, budget.Rollover, budget.StartMonth)
+	return err
+}
+
+// FindAll retorna os orçamentos de todas as categorias
+func (r *BudgetRepository) FindAll() ([]models.Budget, error) {
+	query := `SELECT category, amount, rollover, start_month, created_at, updated_at FROM budgets ORDER BY category`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var budgets []models.Budget
+	for rows.Next() {
+		var b models.Budget
+		var createdAt, updatedAt string
+		if err := rows.Scan(&b.Category, &b.Amount, &b.Rollover, &b.StartMonth, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		b.CreatedAt = parseSQLiteTime(createdAt)
+		b.UpdatedAt = parseSQLiteTime(updatedAt)
+		budgets = append(budgets, b)
+	}
+	return budgets, nil
+}
+
+// Delete remove o orçamento de uma categoria
+func (r *BudgetRepository) Delete(category string) error {
+	_, err := r.db.Exec(`DELETE FROM budgets WHERE category = ?`, category)
+	return err
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+)
+
+type CategoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// categorySelect lista as categorias com o nome da categoria pai e a quantidade de lançamentos
+const categorySelect = `
+	SELECT c.id, c.name, c.color, c.icon, COALESCE(c.parent_id, 0), COALESCE(p.name, ''), c.type,
+		(SELECT count(*) FROM expenses e WHERE e.category = c.name), c.created_at
+	FROM categories c
+	LEFT JOIN categories p ON c.parent_id = p.id
+`
+
+func scanCategory(scanner rowScanner) (models.Category, error) {
+	var c models.Category
+	var createdAt string
+	err := scanner.Scan(&c.ID, &c.Name, &c.Color, &c.Icon, &c.ParentID, &c.ParentName, &c.Type, &c.Usage, &createdAt)
+	c.CreatedAt = parseSQLiteTime(createdAt)
+	return c, err
+}
+
+// categoryTables são as tabelas que guardam o nome da categoria
+var categoryTables = []string{"expenses", "recurrences", "budgets", "installment_plans"}
+
+// Create insere uma nova categoria
+func (r *CategoryRepository) Create(category *models.Category) error {
+	query := `INSERT INTO categories (name, color, icon, parent_id, type) VALUES (?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, category.Name, category.Color, category.Icon, parentOrNull(category.ParentID), category.Type)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	category.ID = int(id)
+	return nil
+}
+
+// Update altera uma categoria; ao renomear, os lançamentos, recorrências, orçamentos e
+// compras parceladas passam a usar o novo nome
+func (r *CategoryRepository) Update(category *models.Category) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldName string
+	if err := tx.QueryRow(`SELECT name FROM categories WHERE id = ?`, category.ID).Scan(&oldName); err != nil {
+		return err
+	}
+
+	query := `UPDATE categories SET name = ?, color = ?, icon = ?, parent_id = ?, type = ? WHERE id = ?`
+	if _, err := tx.Exec(query, category.Name, category.Color, category.Icon, parentOrNull(category.ParentID), category.Type, category.ID); err != nil {
+		return err
+	}
+
+	if oldName != category.Name {
+		for _, table := range categoryTables {
+			if _, err := tx.Exec(`UPDATE `+table+` SET category = ? WHERE category = ?`, category.Name, oldName); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// FindAll retorna todas as categorias
+func (r *CategoryRepository) FindAll() ([]models.Category, error) {
+	rows, err := r.db.Query(categorySelect + ` ORDER BY c.type DESC, c.name COLLATE NOCASE`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []models.Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
+
+// FindByID retorna uma categoria pelo ID
+func (r *CategoryRepository) FindByID(id int) (*models.Category, error) {
+	c, err := scanCategory(r.db.QueryRow(categorySelect+` WHERE c.id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// FindByName retorna uma categoria pelo nome, sem diferenciar maiúsculas
+func (r *CategoryRepository) FindByName(name string) (*models.Category, error) {
+	c, err := scanCategory(r.db.QueryRow(categorySelect+` WHERE c.name = ?`, name))
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CountChildren retorna quantas subcategorias a categoria tem
+func (r *CategoryRepository) CountChildren(id int) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT count(*) FROM categories WHERE parent_id = ?`, id).Scan(&count)
+	return count, err
+}
+
+// IsInUse indica se algum lançamento, recorrência, orçamento ou compra parcelada usa a categoria
+func (r *CategoryRepository) IsInUse(name string) (bool, error) {
+	var used bool
+	err := r.db.QueryRow(`SELECT
+		EXISTS (SELECT 1 FROM expenses WHERE category = ?) OR
+		EXISTS (SELECT 1 FROM recurrences WHERE category = ?) OR
+		EXISTS (SELECT 1 FROM budgets WHERE category = ?) OR
+		EXISTS (SELECT 1 FROM installment_plans WHERE category = ?)`, name, name, name, name).Scan(&used)
+	return used, err
+}
+
+// Delete remove uma categoria
+func (r *CategoryRepository) Delete(id int) error {
+	result, err := r.db.Exec(`DELETE FROM categories WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// Merge move os lançamentos, recorrências e compras parceladas da categoria de origem para a de destino e
+// remove a origem. O orçamento da origem passa para o destino se ele ainda não tiver um
+func (r *CategoryRepository) Merge(sourceID, targetID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var source, target string
+	if err := tx.QueryRow(`SELECT name FROM categories WHERE id = ?`, sourceID).Scan(&source); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(`SELECT name FROM categories WHERE id = ?`, targetID).Scan(&target); err != nil {
+		return err
+	}
+
+	for _, table := range []string{"expenses", "recurrences", "installment_plans"} {
+		if _, err := tx.Exec(`UPDATE `+table+` SET category = ? WHERE category = ?`, target, source); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE OR IGNORE budgets SET category = ? WHERE category = ?`, target, source); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM budgets WHERE category = ?`, source); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM categories WHERE id = ?`, sourceID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// parentOrNull grava a categoria pai: 0 (categoria principal) vira NULL
+func parentOrNull(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id > 0}
+}
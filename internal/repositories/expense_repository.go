@@ -2,9 +2,14 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"errors"
 	"financas/internal/models"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 )
 
 type ExpenseRepository struct {
@@ -15,17 +20,314 @@ func NewExpenseRepository(db *sql.DB) *ExpenseRepository {
 	return &ExpenseRepository{db: db}
 }
 
+// Create grava o lançamento e, se for no cartão, já o coloca na fatura, em uma única transação
+// Um aporte que completa a meta a conclui na mesma transação
 func (r *ExpenseRepository) Create(expense *models.Expense) error {
-	query := `INSERT INTO expenses (description, amount, type, category, payer, date) VALUES (?, ?, ?, ?, ?, ?)`
-	_, err := r.db.Exec(query, expense.Description, expense.Amount, expense.Type, expense.Category, expense.Payer, expense.Date)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO expenses (description, amount, type, category, payer, payer_user_id, account_id, date, goal_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := tx.Exec(query, expense.Description, expense.Amount, expense.Type, expense.Category, expense.Payer, payerUserID(expense), expense.AccountID, expense.Date,
+		nullID(expense.GoalID))
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	expense.ID = int(id)
+
+	if err := assignInvoices(tx, "e.id = ?", expense.ID); err != nil {
+		return err
+	}
+	if err := completeGoals(tx, "g.id = ?", expense.GoalID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateBatch insere vários lançamentos em uma única transação (tudo ou nada), já nas faturas dos cartões
+func (r *ExpenseRepository) CreateBatch(expenses []models.Expense) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO expenses (description, amount, type, category, payer, payer_user_id, account_id, date, fitid, ofx_account)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for i := range expenses {
+		expense := &expenses[i]
+		result, err := tx.Exec(query, expense.Description, expense.Amount, expense.Type, expense.Category, expense.Payer, payerUserID(expense), expense.AccountID, expense.Date,
+			nullString(expense.FITID), nullString(expense.OFXAccount))
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		expense.ID = int(id)
+		if err := assignInvoices(tx, "e.id = ?", expense.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// FindByFITID retorna os lançamentos já importados de uma conta OFX, pelo FITID
+// Inclui os removidos, para que reimportar o extrato não traga de volta o que foi apagado
+func (r *ExpenseRepository) FindByFITID(account string) (map[string]models.Expense, error) {
+	query := `SELECT id, description, amount, type, category, date, fitid
+		FROM expenses
+		WHERE fitid IS NOT NULL AND ofx_account = ?`
+	rows, err := r.db.Query(query, account)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expenses := make(map[string]models.Expense)
+	for rows.Next() {
+		var expense models.Expense
+		var dateStr string
+		if err := rows.Scan(&expense.ID, &expense.Description, &expense.Amount, &expense.Type, &expense.Category, &dateStr, &expense.FITID); err != nil {
+			return nil, err
+		}
+		expense.Date = parseSQLiteTime(dateStr)
+		expense.OFXAccount = account
+		expenses[expense.FITID] = expense
+	}
+	return expenses, nil
 }
 
-func (r *ExpenseRepository) FindAll() ([]models.Expense, error) {
-	// Eliminar despesas deletadas
-	query := `SELECT id, description, amount, type, category, payer, date FROM expenses WHERE deleted_at IS NULL`
+// FindByDateRange retorna os lançamentos (não removidos) entre duas datas, inclusive
+func (r *ExpenseRepository) FindByDateRange(from, to time.Time) ([]models.Expense, error) {
+	query := `SELECT id, description, amount, type, category, date
+		FROM expenses
+		WHERE deleted_at IS NULL AND substr(date, 1, 10) BETWEEN ? AND ?`
+	rows, err := r.db.Query(query, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []models.Expense
+	for rows.Next() {
+		var expense models.Expense
+		var dateStr string
+		if err := rows.Scan(&expense.ID, &expense.Description, &expense.Amount, &expense.Type, &expense.Category, &dateStr); err != nil {
+			return nil, err
+		}
+		expense.Date = parseSQLiteTime(dateStr)
+		expenses = append(expenses, expense)
+	}
+	return expenses, nil
+}
+
+// expenseListSelect é a consulta base da lista de lançamentos (sem os removidos)
+const expenseListSelect = `SELECT e.id, e.description, e.amount, e.type, e.category, COALESCE(u.name, e.payer), COALESCE(e.payer_user_id, 0), COALESCE(e.recurrence_id, 0),
+		COALESCE(e.account_id, 0), COALESCE(a.name, ''), COALESCE(e.installment_plan_id, 0), COALESCE(e.installment_number, 0), COALESCE(ip.installments, 0), e.date
+		FROM expenses e
+		LEFT JOIN users u ON u.id = e.payer_user_id
+		LEFT JOIN accounts a ON a.id = e.account_id
+		LEFT JOIN installment_plans ip ON ip.id = e.installment_plan_id
+		WHERE e.deleted_at IS NULL`
+
+// FindAll retorna todos os lançamentos que atendem ao filtro, na ordem pedida
+func (r *ExpenseRepository) FindAll(filter models.ExpenseFilter) ([]models.Expense, error) {
+	where, args := expenseFilterClause(filter)
+	sort := expenseSortFor(filter.Sort)
+	query := expenseListSelect + where + sort.orderBy()
 	fmt.Println("Executing FindAll query...")
-	rows, err := r.db.Query(query)
+	return r.queryExpenseList(query, args...)
+}
+
+// FindPage retorna uma página da lista, continuando do cursor da página anterior ("" = primeira)
+// Paginação por cursor (keyset): a próxima página começa depois da chave de ordenação e do ID
+// do último item, então o custo não cresce com o número da página como no OFFSET
+func (r *ExpenseRepository) FindPage(filter models.ExpenseFilter, cursor string, limit int) (*models.ExpensePage, error) {
+	where, args := expenseFilterClause(filter)
+	sort := expenseSortFor(filter.Sort)
+
+	if cursor != "" {
+		key, id, err := decodeExpenseCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		var keyArg interface{} = key
+		if sort.numeric {
+			if keyArg, err = strconv.ParseInt(key, 10, 64); err != nil {
+				return nil, ErrInvalidCursor
+			}
+		}
+		op := ">"
+		if sort.desc {
+			op = "<"
+		}
+		where += fmt.Sprintf(` AND (%s %s ? OR (%s = ? AND e.id %s ?))`, sort.key, op, sort.key, op)
+		args = append(args, keyArg, keyArg, id)
+	}
+
+	// Um item a mais indica se existe próxima página
+	query := expenseListSelect + where + sort.orderBy() + ` LIMIT ?`
+	expenses, err := r.queryExpenseList(query, append(args, limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ExpensePage{Expenses: expenses}
+	if len(expenses) > limit {
+		page.Expenses = expenses[:limit]
+		page.NextCursor = encodeExpenseCursor(sort.value(page.Expenses[limit-1]), page.Expenses[limit-1].ID)
+	}
+	return page, nil
+}
+
+// expenseFilterClause monta as condições do filtro (a partir de um WHERE já aberto)
+func expenseFilterClause(filter models.ExpenseFilter) (string, []interface{}) {
+	var where string
+	var args []interface{}
+	if !filter.From.IsZero() {
+		where += ` AND substr(e.date, 1, 10) >= ?`
+		args = append(args, filter.From.Format("2006-01-02"))
+	}
+	if !filter.To.IsZero() {
+		where += ` AND substr(e.date, 1, 10) <= ?`
+		args = append(args, filter.To.Format("2006-01-02"))
+	}
+	if filter.Type != "" {
+		where += ` AND e.type = ?`
+		args = append(args, filter.Type)
+	}
+	if filter.Category != "" {
+		// Uma categoria principal inclui as subcategorias
+		where += ` AND e.category IN (SELECT c.name FROM categories c
+			WHERE c.name = ? OR c.parent_id = (SELECT p.id FROM categories p WHERE p.name = ?))`
+		args = append(args, filter.Category, filter.Category)
+	}
+	if filter.PayerUserID > 0 {
+		where += ` AND e.payer_user_id = ?`
+		args = append(args, filter.PayerUserID)
+	}
+	if filter.AccountID > 0 {
+		where += ` AND e.account_id = ?`
+		args = append(args, filter.AccountID)
+	}
+	if filter.MinAmount > 0 {
+		where += ` AND e.amount >= ?`
+		args = append(args, filter.MinAmount)
+	}
+	if filter.MaxAmount > 0 {
+		where += ` AND e.amount <= ?`
+		args = append(args, filter.MaxAmount)
+	}
+	if match := ftsQuery(filter.Search); match != "" {
+		where += ` AND e.id IN (SELECT rowid FROM expenses_fts WHERE expenses_fts MATCH ?)`
+		args = append(args, match)
+	}
+	return where, args
+}
+
+// ftsQuery converte o texto digitado em uma consulta FTS5: cada palavra vira um prefixo
+// ("farm mar" -> "farm"* "mar"*) e todas precisam aparecer. Pontuação é descartada, então
+// a sintaxe do FTS (aspas, NEAR, parênteses) não vaza para a consulta. "" = nada a buscar
+func ftsQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + word + `"*`
+	}
+	return strings.Join(terms, " ")
+}
+
+// Search busca lançamentos pela descrição (FTS5), do mais relevante para o menos relevante
+func (r *ExpenseRepository) Search(text string, limit int) ([]models.Expense, error) {
+	match := ftsQuery(text)
+	if match == "" {
+		return nil, nil
+	}
+	query := `SELECT e.id, e.description, e.amount, e.type, e.category, COALESCE(u.name, e.payer), COALESCE(e.payer_user_id, 0), COALESCE(e.recurrence_id, 0),
+		COALESCE(e.account_id, 0), COALESCE(a.name, ''), COALESCE(e.installment_plan_id, 0), COALESCE(e.installment_number, 0), COALESCE(ip.installments, 0), e.date
+		FROM expenses_fts
+		JOIN expenses e ON e.id = expenses_fts.rowid
+		LEFT JOIN users u ON u.id = e.payer_user_id
+		LEFT JOIN accounts a ON a.id = e.account_id
+		LEFT JOIN installment_plans ip ON ip.id = e.installment_plan_id
+		WHERE expenses_fts MATCH ? AND e.deleted_at IS NULL
+		ORDER BY expenses_fts.rank, e.date DESC
+		LIMIT ?`
+	return r.queryExpenseList(query, match, limit)
+}
+
+// expenseSort descreve uma ordenação: a chave SQL, o sentido e como ler a chave de um lançamento
+// O ID desempata itens com a mesma chave, o que torna a ordem total e o cursor estável
+type expenseSort struct {
+	key     string
+	desc    bool
+	numeric bool
+	value   func(e models.Expense) string
+}
+
+func (s expenseSort) orderBy() string {
+	dir := "ASC"
+	if s.desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf(` ORDER BY %s %s, e.id %s`, s.key, dir, dir)
+}
+
+func expenseSortFor(sort string) expenseSort {
+	byDate := func(e models.Expense) string { return e.Date.Format("2006-01-02") }
+	byAmount := func(e models.Expense) string { return strconv.FormatInt(int64(e.Amount), 10) }
+	switch sort {
+	case models.SortDateAsc:
+		return expenseSort{key: "substr(e.date, 1, 10)", value: byDate}
+	case models.SortAmountDesc:
+		return expenseSort{key: "e.amount", desc: true, numeric: true, value: byAmount}
+	case models.SortAmountAsc:
+		return expenseSort{key: "e.amount", numeric: true, value: byAmount}
+	case models.SortDescription:
+		// NOCASE na chave vale também para a comparação do cursor, que recebe a descrição original
+		return expenseSort{key: "e.description COLLATE NOCASE", value: func(e models.Expense) string { return e.Description }}
+	default:
+		return expenseSort{key: "substr(e.date, 1, 10)", desc: true, value: byDate}
+	}
+}
+
+// ErrInvalidCursor indica um cursor de paginação que não foi gerado por FindPage
+var ErrInvalidCursor = errors.New("cursor de paginação inválido")
+
+// encodeExpenseCursor codifica a chave de ordenação e o ID do último item da página
+func encodeExpenseCursor(key string, id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key + "\x00" + strconv.Itoa(id)))
+}
+
+func decodeExpenseCursor(cursor string) (string, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+	key, idStr, ok := strings.Cut(string(raw), "\x00")
+	if !ok {
+		return "", 0, ErrInvalidCursor
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return "", 0, ErrInvalidCursor
+	}
+	return key, id, nil
+}
+
+// queryExpenseList executa uma consulta baseada em expenseListSelect
+func (r *ExpenseRepository) queryExpenseList(query string, args ...interface{}) ([]models.Expense, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		fmt.Printf("Query error: %v\n", err)
 		return nil, err
@@ -36,7 +338,8 @@ func (r *ExpenseRepository) FindAll() ([]models.Expense, error) {
 	for rows.Next() {
 		var expense models.Expense
 		var dateStr string
-		if err := rows.Scan(&expense.ID, &expense.Description, &expense.Amount, &expense.Type, &expense.Category, &expense.Payer, &dateStr); err != nil {
+		if err := rows.Scan(&expense.ID, &expense.Description, &expense.Amount, &expense.Type, &expense.Category, &expense.Payer, &expense.PayerUserID, &expense.RecurrenceID, &expense.AccountID, &expense.AccountName,
+			&expense.InstallmentPlanID, &expense.InstallmentNumber, &expense.InstallmentCount, &dateStr); err != nil {
 			fmt.Printf("Scan error: %v\n", err)
 			return nil, err
 		}
@@ -56,14 +359,23 @@ func (r *ExpenseRepository) FindAll() ([]models.Expense, error) {
 }
 
 func (r *ExpenseRepository) FindByID(id int) (*models.Expense, error) {
-	query := `SELECT id, description, amount, type, category, payer, date, created_at, updated_at, deleted_at FROM expenses WHERE id = ?`
+	query := `SELECT e.id, e.description, e.amount, e.type, e.category, COALESCE(u.name, e.payer), COALESCE(e.payer_user_id, 0),
+		COALESCE(e.recurrence_id, 0), COALESCE(e.account_id, 0), COALESCE(a.name, ''),
+		COALESCE(e.installment_plan_id, 0), COALESCE(e.installment_number, 0), COALESCE(ip.installments, 0), COALESCE(e.goal_id, 0),
+		e.date, e.created_at, e.updated_at, e.deleted_at
+		FROM expenses e
+		LEFT JOIN users u ON u.id = e.payer_user_id
+		LEFT JOIN accounts a ON a.id = e.account_id
+		LEFT JOIN installment_plans ip ON ip.id = e.installment_plan_id
+		WHERE e.id = ? AND e.deleted_at IS NULL`
 	row := r.db.QueryRow(query, id)
 
 	var expense models.Expense
 	var dateStr string
 	var createdAtStr, updatedAtStr, deletedAtStr sql.NullString
 
-	if err := row.Scan(&expense.ID, &expense.Description, &expense.Amount, &expense.Type, &expense.Category, &expense.Payer, &dateStr, &createdAtStr, &updatedAtStr, &deletedAtStr); err != nil {
+	if err := row.Scan(&expense.ID, &expense.Description, &expense.Amount, &expense.Type, &expense.Category, &expense.Payer, &expense.PayerUserID, &expense.RecurrenceID, &expense.AccountID, &expense.AccountName,
+		&expense.InstallmentPlanID, &expense.InstallmentNumber, &expense.InstallmentCount, &expense.GoalID, &dateStr, &createdAtStr, &updatedAtStr, &deletedAtStr); err != nil {
 		fmt.Printf("FindByID Scan Error: %v\n", err)
 		return nil, err
 	}
@@ -81,26 +393,119 @@ func (r *ExpenseRepository) FindByID(id int) (*models.Expense, error) {
 	return &expense, nil
 }
 
+// Update altera um lançamento ativo; lançamentos na lixeira retornam sql.ErrNoRows
+// Mudar a conta ou a data tira o lançamento da fatura do cartão e o coloca na fatura nova, na mesma transação,
+// e um aporte que completa a meta a conclui
 func (r *ExpenseRepository) Update(expense *models.Expense) error {
-	query := `UPDATE expenses SET description = ?, amount = ?, type = ?, category = ?, payer = ?, date = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	_, err := r.db.Exec(query, expense.Description, expense.Amount, expense.Type, expense.Category, expense.Payer, expense.Date, expense.ID)
-	return err
+	query := `UPDATE expenses SET description = ?, amount = ?, type = ?, category = ?, payer = ?, payer_user_id = ?, account_id = ?, date = ?, goal_id = ?,
+		invoice_id = CASE WHEN account_id = ? AND substr(date, 1, 10) = ? THEN invoice_id END,
+		updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, expense.Description, expense.Amount, expense.Type, expense.Category, expense.Payer, payerUserID(expense), expense.AccountID, expense.Date,
+		nullID(expense.GoalID), expense.AccountID, expense.Date.Format("2006-01-02"), expense.ID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	if err := assignInvoices(tx, "e.id = ?", expense.ID); err != nil {
+		return err
+	}
+	if err := completeGoals(tx, "g.id = ?", expense.GoalID); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// payerUserID converte o pagador da despesa para a coluna (NULL = fora do rateio)
+func payerUserID(expense *models.Expense) sql.NullInt64 {
+	if expense.PayerUserID <= 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(expense.PayerUserID), Valid: true}
+}
+
+// Delete move o lançamento para a lixeira (deleted_at); remover de novo não muda a data da remoção
 func (r *ExpenseRepository) Delete(id int) error {
-	query := `UPDATE expenses SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?`
+	query := `UPDATE expenses SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL`
 	_, err := r.db.Exec(query, id)
 	return err
 }
 
+// FindDeleted retorna os lançamentos na lixeira, removidos mais recentemente primeiro
+func (r *ExpenseRepository) FindDeleted() ([]models.Expense, error) {
+	query := `SELECT e.id, e.description, e.amount, e.type, e.category, COALESCE(u.name, e.payer), e.date, e.deleted_at
+		FROM expenses e
+		LEFT JOIN users u ON u.id = e.payer_user_id
+		WHERE e.deleted_at IS NOT NULL
+		ORDER BY e.deleted_at DESC, e.id DESC`
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []models.Expense
+	for rows.Next() {
+		var expense models.Expense
+		var dateStr, deletedAtStr string
+		if err := rows.Scan(&expense.ID, &expense.Description, &expense.Amount, &expense.Type, &expense.Category, &expense.Payer, &dateStr, &deletedAtStr); err != nil {
+			return nil, err
+		}
+		expense.Date = parseSQLiteTime(dateStr)
+		expense.DeletedAt = parseSQLiteTime(deletedAtStr)
+		expenses = append(expenses, expense)
+	}
+	return expenses, nil
+}
+
+// Restore tira o lançamento da lixeira; sql.ErrNoRows se ele não estiver lá
+func (r *ExpenseRepository) Restore(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE expenses SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	// O aporte restaurado volta a contar na meta
+	if err := completeGoals(tx, "g.id = (SELECT goal_id FROM expenses WHERE id = ?)", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Purge apaga definitivamente os lançamentos que estão na lixeira desde antes de cutoff
+// Lançamentos de extrato OFX apagados aqui deixam de bloquear o FITID e voltam se o extrato for reimportado
+func (r *ExpenseRepository) Purge(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM expenses WHERE deleted_at IS NOT NULL AND deleted_at < ?`,
+		cutoff.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 // GetSummary retorna métricas de resumo total
-func (r *ExpenseRepository) GetSummary() (float64, float64, float64, error) {
+func (r *ExpenseRepository) GetSummary() (models.Money, models.Money, models.Money, error) {
 	query := `SELECT 
 		COALESCE(SUM(CASE WHEN type = 'receita' THEN amount ELSE 0 END), 0) as total_income,
 		COALESCE(SUM(CASE WHEN type = 'despesa' THEN amount ELSE 0 END), 0) as total_expense
 	FROM expenses WHERE deleted_at IS NULL`
 
-	var income, expense float64
+	var income, expense models.Money
 	err := r.db.QueryRow(query).Scan(&income, &expense)
 	if err != nil {
 		return 0, 0, 0, err
@@ -111,20 +516,46 @@ func (r *ExpenseRepository) GetSummary() (float64, float64, float64, error) {
 
 // CategoryMetric representa estatísticas de agrupamento
 type CategoryMetric struct {
-	Category string  `json:"category"`
-	Total    float64 `json:"total"`
-	Type     string  `json:"type"`
+	Category string       `json:"category"`
+	Total    models.Money `json:"total"`
+	Type     string       `json:"type"`
 }
 
 // GetCategoryBreakdown returns expenses grouped by category
 func (r *ExpenseRepository) GetCategoryBreakdown() ([]CategoryMetric, error) {
+	return r.categoryBreakdown(`deleted_at IS NULL`)
+}
+
+// GetCategoryBreakdownForMonth agrupa por categoria os lançamentos de um mês ("2026-02")
+func (r *ExpenseRepository) GetCategoryBreakdownForMonth(month string) ([]CategoryMetric, error) {
+	return r.categoryBreakdown(`deleted_at IS NULL AND substr(date, 1, 7) = ?`, month)
+}
+
+// GetUnscheduledCategoryTotals agrupa por categoria os lançamentos entre from e to (inclusive)
+// que não vieram de recorrências nem de compras parceladas
+func (r *ExpenseRepository) GetUnscheduledCategoryTotals(from, to time.Time) ([]CategoryMetric, error) {
+	return r.categoryBreakdown(`deleted_at IS NULL AND recurrence_id IS NULL AND installment_plan_id IS NULL
+		AND substr(date, 1, 10) BETWEEN ? AND ?`, from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+// CountMonthsWithEntries conta os meses entre from e to (inclusive) com algum lançamento ativo
+func (r *ExpenseRepository) CountMonthsWithEntries(from, to time.Time) (int, error) {
+	var months int
+	err := r.db.QueryRow(`SELECT count(DISTINCT substr(date, 1, 7)) FROM expenses
+		WHERE deleted_at IS NULL AND substr(date, 1, 10) BETWEEN ? AND ?`,
+		from.Format("2006-01-02"), to.Format("2006-01-02")).Scan(&months)
+	return months, err
+}
+
+// categoryBreakdown soma os lançamentos por categoria e tipo com o filtro informado
+func (r *ExpenseRepository) categoryBreakdown(condition string, args ...any) ([]CategoryMetric, error) {
 	query := `SELECT category, type, SUM(amount) as total 
 			  FROM expenses 
-			  WHERE deleted_at IS NULL 
+			  WHERE ` + condition + ` 
 			  GROUP BY category, type 
 			  ORDER BY total DESC`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -143,16 +574,25 @@ func (r *ExpenseRepository) GetCategoryBreakdown() ([]CategoryMetric, error) {
 
 // MonthlyMetric representa agregação mensal
 type MonthlyMetric struct {
-	Month   string  `json:"month"`
-	Income  float64 `json:"income"`
-	Expense float64 `json:"expense"`
-	Balance float64 `json:"balance"`
+	Month   string       `json:"month"`
+	Income  models.Money `json:"income"`
+	Expense models.Money `json:"expense"`
+	Balance models.Money `json:"balance"`
 }
 
+// cashDate é a data em que o lançamento mexe no caixa: o vencimento da fatura
+// para compras no cartão, a data do lançamento para o resto
+const cashDate = `COALESCE((SELECT i.due_date FROM invoices i WHERE i.id = expenses.invoice_id), expenses.date)`
+
 // GetMonthlyBreakdown returns expenses grouped by month
-func (r *ExpenseRepository) GetMonthlyBreakdown() ([]MonthlyMetric, error) {
+// byCashDate agrupa pela data de caixa (vencimento da fatura) em vez da data da compra
+func (r *ExpenseRepository) GetMonthlyBreakdown(byCashDate bool) ([]MonthlyMetric, error) {
+	date := "date"
+	if byCashDate {
+		date = cashDate
+	}
 	query := `SELECT 
-		substr(date, 1, 7) as month,
+		substr(` + date + `, 1, 7) as month,
 		COALESCE(SUM(CASE WHEN type = 'receita' THEN amount ELSE 0 END), 0) as income,
 		COALESCE(SUM(CASE WHEN type = 'despesa' THEN amount ELSE 0 END), 0) as expense
 	FROM expenses 
@@ -181,9 +621,9 @@ func (r *ExpenseRepository) GetMonthlyBreakdown() ([]MonthlyMetric, error) {
 
 // TypeMetric representa totais por tipo
 type TypeMetric struct {
-	Type  string  `json:"type"`
-	Total float64 `json:"total"`
-	Count int     `json:"count"`
+	Type  string       `json:"type"`
+	Total models.Money `json:"total"`
+	Count int          `json:"count"`
 }
 
 // GetTypeBreakdown returns totals by type
@@ -216,10 +656,11 @@ func (r *ExpenseRepository) GetTypeBreakdown() ([]TypeMetric, error) {
 
 // GetTopExpenses retorna as top N despesas
 func (r *ExpenseRepository) GetTopExpenses(limit int) ([]models.Expense, error) {
-	query := `SELECT id, description, amount, type, category, payer, date 
-			  FROM expenses 
-			  WHERE deleted_at IS NULL 
-			  ORDER BY amount DESC 
+	query := `SELECT e.id, e.description, e.amount, e.type, e.category, COALESCE(u.name, e.payer), COALESCE(e.payer_user_id, 0), COALESCE(e.recurrence_id, 0), e.date
+			  FROM expenses e
+			  LEFT JOIN users u ON u.id = e.payer_user_id
+			  WHERE e.deleted_at IS NULL 
+			  ORDER BY e.amount DESC 
 			  LIMIT ?`
 
 	rows, err := r.db.Query(query, limit)
@@ -233,7 +674,7 @@ func (r *ExpenseRepository) GetTopExpenses(limit int) ([]models.Expense, error)
 		var expense models.Expense
 		var dateStr string
 
-		if err := rows.Scan(&expense.ID, &expense.Description, &expense.Amount, &expense.Type, &expense.Category, &expense.Payer, &dateStr); err != nil {
+		if err := rows.Scan(&expense.ID, &expense.Description, &expense.Amount, &expense.Type, &expense.Category, &expense.Payer, &expense.PayerUserID, &expense.RecurrenceID, &dateStr); err != nil {
 			return nil, err
 		}
 		if dateStr != "" {
@@ -246,3 +687,58 @@ func (r *ExpenseRepository) GetTopExpenses(limit int) ([]models.Expense, error)
 	}
 	return expenses, nil
 }
+
+// GetPaidByPayer retorna, para o mês ("2026-02"), o total de despesas pago por cada membro
+// e o total das despesas sem pagador (que ficam fora do rateio)
+func (r *ExpenseRepository) GetPaidByPayer(month string) (map[int]models.Money, models.Money, error) {
+	query := `SELECT COALESCE(payer_user_id, 0), SUM(amount)
+		FROM expenses
+		WHERE deleted_at IS NULL AND type = 'despesa' AND substr(date, 1, 7) = ?
+		GROUP BY COALESCE(payer_user_id, 0)`
+
+	rows, err := r.db.Query(query, month)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	totals := make(map[int]models.Money)
+	unassigned := models.Money(0)
+	for rows.Next() {
+		var userID int
+		var total models.Money
+		if err := rows.Scan(&userID, &total); err != nil {
+			return nil, 0, err
+		}
+		if userID == 0 {
+			unassigned = total
+			continue
+		}
+		totals[userID] = total
+	}
+	return totals, unassigned, nil
+}
+
+// GetDespesaMonths retorna os meses que têm despesas, do mais recente para o mais antigo
+func (r *ExpenseRepository) GetDespesaMonths() ([]string, error) {
+	query := `SELECT DISTINCT substr(date, 1, 7) as month
+		FROM expenses
+		WHERE deleted_at IS NULL AND type = 'despesa'
+		ORDER BY month DESC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var months []string
+	for rows.Next() {
+		var month string
+		if err := rows.Scan(&month); err != nil {
+			return nil, err
+		}
+		months = append(months, month)
+	}
+	return months, nil
+}
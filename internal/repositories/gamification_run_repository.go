@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+)
+
+type GamificationRunRepository struct {
+	db *sql.DB
+}
+
+func NewGamificationRunRepository(db *sql.DB) *GamificationRunRepository {
+	return &GamificationRunRepository{db: db}
+}
+
+// Save grava a execução do mês, lança os pontos de cada delta no extrato e atribui as conquistas
+// em uma única transação: ou o mês fica todo processado, ou nada é gravado
+// Falha se o mês já tiver uma execução registrada (month é PRIMARY KEY)
+func (r *GamificationRunRepository) Save(run *models.GamificationRun) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO gamification_runs (month) VALUES (?)`, run.Month); err != nil {
+		return err
+	}
+
+	for _, d := range run.Deltas {
+		_, err := tx.Exec(
+			`INSERT INTO gamification_run_deltas (month, user_id, points, reason) VALUES (?, ?, ?, ?)`,
+			run.Month, d.UserID, d.Points, d.Reason,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`INSERT INTO point_transactions (user_id, delta, reason, month) VALUES (?, ?, ?, ?)`,
+			d.UserID, d.Points, d.Reason, run.Month,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, a := range run.Achievements {
+		var achievementID int
+		if err := tx.QueryRow(`SELECT id FROM achievements WHERE name = ?`, a.Name).Scan(&achievementID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(
+			`INSERT OR IGNORE INTO user_achievements (user_id, achievement_id, month) VALUES (?, ?, ?)`,
+			a.UserID, achievementID, run.Month,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindByMonth retorna a execução de um mês com seus deltas (sql.ErrNoRows se não processado)
+func (r *GamificationRunRepository) FindByMonth(month string) (*models.GamificationRun, error) {
+	var run models.GamificationRun
+	var processedAtStr string
+	err := r.db.QueryRow(`SELECT month, processed_at FROM gamification_runs WHERE month = ?`, month).
+		Scan(&run.Month, &processedAtStr)
+	if err != nil {
+		return nil, err
+	}
+	run.ProcessedAt = parseSQLiteTime(processedAtStr)
+
+	query := `
+		SELECT d.user_id, COALESCE(u.name, 'Membro removido'), d.points, d.reason
+		FROM gamification_run_deltas d
+		LEFT JOIN users u ON d.user_id = u.id
+		WHERE d.month = ?
+		ORDER BY u.name
+	`
+	rows, err := r.db.Query(query, month)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.PointDelta
+		if err := rows.Scan(&d.UserID, &d.UserName, &d.Points, &d.Reason); err != nil {
+			return nil, err
+		}
+		run.Deltas = append(run.Deltas, d)
+	}
+	return &run, nil
+}
+
+// Revert estorna no extrato exatamente os pontos aplicados na execução do mês,
+// remove as conquistas atribuídas naquele mês e apaga o registro da execução
+// A conquista de meta concluída não vem da execução do mês e é mantida (a meta não a dá de novo)
+func (r *GamificationRunRepository) Revert(month string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO point_transactions (user_id, delta, reason, month)
+		SELECT user_id, -SUM(points), ?, month
+		FROM gamification_run_deltas
+		WHERE month = ?
+		GROUP BY user_id
+		HAVING SUM(points) <> 0
+	`, models.ReasonMonthReopened, month)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM gamification_run_deltas WHERE month = ?`, month); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		DELETE FROM user_achievements
+		WHERE month = ? AND achievement_id NOT IN (SELECT id FROM achievements WHERE name = ?)
+	`, month, models.GoalAchievement)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM gamification_runs WHERE month = ?`, month); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
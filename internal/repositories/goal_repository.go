@@ -0,0 +1,218 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+)
+
+type GoalRepository struct {
+	db *sql.DB
+}
+
+func NewGoalRepository(db *sql.DB) *GoalRepository {
+	return &GoalRepository{db: db}
+}
+
+// goalSelect lista as metas com a soma e a quantidade de aportes (lançamentos ativos e transferências)
+const goalSelect = `
+	SELECT g.id, g.name, g.target, substr(g.deadline, 1, 10), COALESCE(g.user_id, 0), COALESCE(u.name, ''),
+		` + goalSaved + `,
+		(SELECT count(*) FROM expenses e WHERE e.goal_id = g.id AND e.deleted_at IS NULL)
+			+ (SELECT count(*) FROM transfers t WHERE t.goal_id = g.id),
+		COALESCE(g.completed_at, ''), g.created_at
+	FROM goals g
+	LEFT JOIN users u ON u.id = g.user_id
+`
+
+func scanGoal(scanner rowScanner) (models.Goal, error) {
+	var g models.Goal
+	var deadline, completedAt, createdAt string
+	err := scanner.Scan(&g.ID, &g.Name, &g.Target, &deadline, &g.OwnerUserID, &g.OwnerName,
+		&g.Saved, &g.Contributions, &completedAt, &createdAt)
+	g.Deadline = parseSQLiteTime(deadline)
+	g.CompletedAt = parseSQLiteTime(completedAt)
+	g.CreatedAt = parseSQLiteTime(createdAt)
+	return g, err
+}
+
+// Create insere uma nova meta
+func (r *GoalRepository) Create(goal *models.Goal) error {
+	query := `INSERT INTO goals (name, target, deadline, user_id) VALUES (?, ?, ?, ?)`
+	result, err := r.db.Exec(query, goal.Name, goal.Target, goal.Deadline.Format("2006-01-02"), nullID(goal.OwnerUserID))
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	goal.ID = int(id)
+	return nil
+}
+
+// Update altera nome, valor, prazo e responsável de uma meta
+// reopen volta a meta concluída para em andamento (o valor passou do que foi juntado);
+// uma meta cujo valor baixou até o que já foi juntado é concluída na mesma transação
+func (r *GoalRepository) Update(goal *models.Goal, reopen bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE goals SET name = ?, target = ?, deadline = ?, user_id = ?,
+		completed_at = CASE WHEN ? THEN NULL ELSE completed_at END
+		WHERE id = ?`
+	result, err := tx.Exec(query, goal.Name, goal.Target, goal.Deadline.Format("2006-01-02"), nullID(goal.OwnerUserID),
+		reopen, goal.ID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	if err := completeGoals(tx, "g.id = ?", goal.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindAll retorna as metas em andamento (pelo prazo) e depois as concluídas
+func (r *GoalRepository) FindAll() ([]models.Goal, error) {
+	rows, err := r.db.Query(goalSelect + ` ORDER BY g.completed_at IS NOT NULL, g.deadline, g.name COLLATE NOCASE`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []models.Goal
+	for rows.Next() {
+		g, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, g)
+	}
+	return goals, nil
+}
+
+// FindByID retorna uma meta pelo ID
+func (r *GoalRepository) FindByID(id int) (*models.Goal, error) {
+	g, err := scanGoal(r.db.QueryRow(goalSelect+` WHERE g.id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// NameExists indica se outra meta (diferente de exceptID) já usa o nome, sem diferenciar maiúsculas
+func (r *GoalRepository) NameExists(name string, exceptID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM goals WHERE name = ? AND id <> ?)`, name, exceptID).Scan(&exists)
+	return exists, err
+}
+
+// goalSaved é o quanto foi juntado na meta g: lançamentos ativos (receita desconta) e transferências
+const goalSaved = `
+	COALESCE((SELECT SUM(CASE WHEN e.type = 'receita' THEN -e.amount ELSE e.amount END)
+		FROM expenses e WHERE e.goal_id = g.id AND e.deleted_at IS NULL), 0)
+	+ COALESCE((SELECT SUM(t.amount) FROM transfers t WHERE t.goal_id = g.id), 0)`
+
+// completeGoals conclui as metas em andamento que chegaram ao valor e atendem à condição (sobre "g",
+// a tabela goals), e dá a conquista de meta cumprida ao responsável no mês corrente
+// Roda na transação de quem gravou o aporte, então o aporte, a conclusão e a conquista são gravados juntos
+func completeGoals(tx *sql.Tx, condition string, args ...any) error {
+	rows, err := tx.Query(`SELECT g.id, COALESCE(g.user_id, 0) FROM goals g
+		WHERE g.completed_at IS NULL AND `+goalSaved+` >= g.target AND `+condition, args...)
+	if err != nil {
+		return err
+	}
+	type reached struct{ goalID, ownerID int }
+	var goals []reached
+	for rows.Next() {
+		var g reached
+		if err := rows.Scan(&g.goalID, &g.ownerID); err != nil {
+			rows.Close()
+			return err
+		}
+		goals = append(goals, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, g := range goals {
+		if _, err := tx.Exec(`UPDATE goals SET completed_at = CURRENT_TIMESTAMP WHERE id = ?`, g.goalID); err != nil {
+			return err
+		}
+		if g.ownerID == 0 {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO user_achievements (user_id, achievement_id, month)
+			SELECT ?, id, strftime('%Y-%m', 'now', 'localtime') FROM achievements WHERE name = ?`,
+			g.ownerID, models.GoalAchievement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete remove a meta; os aportes continuam lançados, só perdem o vínculo
+func (r *GoalRepository) Delete(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE expenses SET goal_id = NULL WHERE goal_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE transfers SET goal_id = NULL WHERE goal_id = ?`, id); err != nil {
+		return err
+	}
+	result, err := tx.Exec(`DELETE FROM goals WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindContributions retorna os aportes da meta (lançamentos ativos e transferências), do mais recente
+// para o mais antigo
+func (r *GoalRepository) FindContributions(goalID int) ([]models.GoalContribution, error) {
+	query := `
+		SELECT substr(e.date, 1, 10) AS day, 'lancamento', e.id, 0, e.description, COALESCE(a.name, ''),
+			CASE WHEN e.type = 'receita' THEN -e.amount ELSE e.amount END
+		FROM expenses e
+		LEFT JOIN accounts a ON a.id = e.account_id
+		WHERE e.goal_id = ?1 AND e.deleted_at IS NULL
+		UNION ALL
+		SELECT substr(t.date, 1, 10), 'transferencia', 0, t.id, t.description, a.name, t.amount
+		FROM transfers t
+		JOIN accounts a ON a.id = t.to_account_id
+		WHERE t.goal_id = ?1
+		ORDER BY day DESC, 3 DESC, 4 DESC
+	`
+	rows, err := r.db.Query(query, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contributions []models.GoalContribution
+	for rows.Next() {
+		var c models.GoalContribution
+		var day string
+		if err := rows.Scan(&day, &c.Kind, &c.ExpenseID, &c.TransferID, &c.Description, &c.Account, &c.Amount); err != nil {
+			return nil, err
+		}
+		c.Date = parseSQLiteTime(day)
+		contributions = append(contributions, c)
+	}
+	return contributions, nil
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+// parseSQLiteTime converte datas vindas do SQLite, que podem chegar em formatos diferentes
+// conforme o tipo da coluna (DATE/DATETIME) e como o valor foi gravado
+func parseSQLiteTime(value string) time.Time {
+	layouts := []string{
+		"2006-01-02T15:04:05Z",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dateOrNull grava datas opcionais: a data zero vira NULL
+func dateOrNull(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: t.Format("2006-01-02"), Valid: true}
+}
+
+// nullString grava textos opcionais: o texto vazio vira NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// requireAffected converte um UPDATE/DELETE que não alterou nenhuma linha em sql.ErrNoRows
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// execer é o que *sql.DB e *sql.Tx têm em comum para gravar, permitindo reusar um INSERT
+// dentro e fora de transações
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// nullID grava referências opcionais: o ID 0 vira NULL
+func nullID(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id > 0}
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+)
+
+type ImportProfileRepository struct {
+	db *sql.DB
+}
+
+func NewImportProfileRepository(db *sql.DB) *ImportProfileRepository {
+	return &ImportProfileRepository{db: db}
+}
+
+// Save grava um perfil de importação; um perfil com o mesmo nome é substituído
+func (r *ImportProfileRepository) Save(p *models.ImportProfile) error {
+	query := `INSERT INTO import_profiles
+		(name, delimiter, has_header, date_column, date_format, description_column,
+		 amount_mode, amount_column, debit_column, credit_column, decimal_comma, negate)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			delimiter = excluded.delimiter,
+			has_header = excluded.has_header,
+			date_column = excluded.date_column,
+			date_format = excluded.date_format,
+			description_column = excluded.description_column,
+			amount_mode = excluded.amount_mode,
+			amount_column = excluded.amount_column,
+			debit_column = excluded.debit_column,
+			credit_column = excluded.credit_column,
+			decimal_comma = excluded.decimal_comma,
+			negate = excluded.negate`
+	_, err := r.db.Exec(query, p.Name, p.Delimiter, p.HasHeader, p.DateColumn, p.DateFormat, p.DescriptionColumn,
+		p.AmountMode, p.AmountColumn, p.DebitColumn, p.CreditColumn, p.DecimalComma, p.Negate)
+	return err
+}
+
+const importProfileColumns = `id, name, delimiter, has_header, date_column, date_format, description_column,
+	amount_mode, amount_column, debit_column, credit_column, decimal_comma, negate, created_at`
+
+// scanImportProfile lê um perfil a partir das colunas de importProfileColumns
+func scanImportProfile(row rowScanner) (models.ImportProfile, error) {
+	var p models.ImportProfile
+	var createdAt string
+	err := row.Scan(&p.ID, &p.Name, &p.Delimiter, &p.HasHeader, &p.DateColumn, &p.DateFormat, &p.DescriptionColumn,
+		&p.AmountMode, &p.AmountColumn, &p.DebitColumn, &p.CreditColumn, &p.DecimalComma, &p.Negate, &createdAt)
+	p.CreatedAt = parseSQLiteTime(createdAt)
+	return p, err
+}
+
+// FindAll retorna os perfis de importação salvos
+func (r *ImportProfileRepository) FindAll() ([]models.ImportProfile, error) {
+	rows, err := r.db.Query(`SELECT ` + importProfileColumns + ` FROM import_profiles ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []models.ImportProfile
+	for rows.Next() {
+		p, err := scanImportProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// FindByID busca um perfil de importação pelo ID
+func (r *ImportProfileRepository) FindByID(id int) (*models.ImportProfile, error) {
+	p, err := scanImportProfile(r.db.QueryRow(`SELECT `+importProfileColumns+` FROM import_profiles WHERE id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Delete remove um perfil de importação
+func (r *ImportProfileRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM import_profiles WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+	"time"
+)
+
+type InstallmentRepository struct {
+	db *sql.DB
+}
+
+func NewInstallmentRepository(db *sql.DB) *InstallmentRepository {
+	return &InstallmentRepository{db: db}
+}
+
+// installmentPlanSelect lista os planos com as parcelas ativas até a data informada
+// (primeiro parâmetro, "2006-01-02") e depois dela
+const installmentPlanSelect = `
+	SELECT p.id, p.description, p.total, p.installments, p.category, COALESCE(u.name, p.payer), COALESCE(p.payer_user_id, 0),
+		COALESCE(p.account_id, 0), COALESCE(a.name, ''), substr(p.first_date, 1, 10), p.remainder,
+		(SELECT count(*) FROM expenses e WHERE e.installment_plan_id = p.id AND e.deleted_at IS NULL AND substr(e.date, 1, 10) <= ?1),
+		(SELECT count(*) FROM expenses e WHERE e.installment_plan_id = p.id AND e.deleted_at IS NULL AND substr(e.date, 1, 10) > ?1),
+		COALESCE((SELECT SUM(e.amount) FROM expenses e WHERE e.installment_plan_id = p.id AND e.deleted_at IS NULL AND substr(e.date, 1, 10) > ?1), 0),
+		p.cancelled_at IS NOT NULL, p.created_at
+	FROM installment_plans p
+	LEFT JOIN users u ON u.id = p.payer_user_id
+	LEFT JOIN accounts a ON a.id = p.account_id
+`
+
+func scanInstallmentPlan(scanner rowScanner) (models.InstallmentPlan, error) {
+	var p models.InstallmentPlan
+	var firstDate, createdAt string
+	err := scanner.Scan(&p.ID, &p.Description, &p.Total, &p.Installments, &p.Category, &p.Payer, &p.PayerUserID,
+		&p.AccountID, &p.AccountName, &firstDate, &p.Remainder, &p.Paid, &p.Remaining, &p.Outstanding, &p.Cancelled, &createdAt)
+	p.FirstDate = parseSQLiteTime(firstDate)
+	p.CreatedAt = parseSQLiteTime(createdAt)
+	return p, err
+}
+
+// planPayerID converte o pagador do plano para a coluna (NULL = fora do rateio)
+func planPayerID(plan *models.InstallmentPlan) sql.NullInt64 {
+	if plan.PayerUserID <= 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(plan.PayerUserID), Valid: true}
+}
+
+// Create grava o plano e as parcelas (já com valor e data, e nas faturas se for no cartão) em uma única transação
+func (r *InstallmentRepository) Create(plan *models.InstallmentPlan, installments []models.Expense) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO installment_plans (description, total, installments, category, payer, payer_user_id, account_id, first_date, remainder)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := tx.Exec(query, plan.Description, plan.Total, plan.Installments, plan.Category, plan.Payer,
+		planPayerID(plan), plan.AccountID, plan.FirstDate.Format("2006-01-02"), plan.Remainder)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	plan.ID = int(id)
+
+	query = `INSERT INTO expenses (description, amount, type, category, payer, payer_user_id, account_id, date, installment_plan_id, installment_number)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for i := range installments {
+		expense := &installments[i]
+		expense.InstallmentPlanID = plan.ID
+		result, err := tx.Exec(query, expense.Description, expense.Amount, expense.Type, expense.Category, expense.Payer,
+			payerUserID(expense), expense.AccountID, expense.Date, plan.ID, expense.InstallmentNumber)
+		if err != nil {
+			return err
+		}
+		if id, err := result.LastInsertId(); err == nil {
+			expense.ID = int(id)
+		}
+	}
+	if err := assignInvoices(tx, "e.installment_plan_id = ?", plan.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindAll retorna os planos, dos mais recentes para os mais antigos, contando as parcelas até a data informada
+func (r *InstallmentRepository) FindAll(asOf time.Time) ([]models.InstallmentPlan, error) {
+	rows, err := r.db.Query(installmentPlanSelect+` ORDER BY p.first_date DESC, p.id DESC`, asOf.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plans []models.InstallmentPlan
+	for rows.Next() {
+		p, err := scanInstallmentPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
+
+// FindByID retorna um plano, contando as parcelas até a data informada
+func (r *InstallmentRepository) FindByID(id int, asOf time.Time) (*models.InstallmentPlan, error) {
+	p, err := scanInstallmentPlan(r.db.QueryRow(installmentPlanSelect+` WHERE p.id = ?2`, asOf.Format("2006-01-02"), id))
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// FindInstallments retorna as parcelas ativas do plano, em ordem
+func (r *InstallmentRepository) FindInstallments(planID int) ([]models.Expense, error) {
+	return r.findInstallments(`e.installment_plan_id = ? ORDER BY e.installment_number`, planID)
+}
+
+// FindUpcoming retorna as parcelas ativas de todos os planos com data depois de after e até until, por data
+func (r *InstallmentRepository) FindUpcoming(after, until time.Time) ([]models.Expense, error) {
+	return r.findInstallments(`substr(e.date, 1, 10) > ? AND substr(e.date, 1, 10) <= ? ORDER BY e.date, e.id`,
+		after.Format("2006-01-02"), until.Format("2006-01-02"))
+}
+
+// findInstallments lista as parcelas ativas com o filtro (e a ordem) informados
+func (r *InstallmentRepository) findInstallments(condition string, args ...any) ([]models.Expense, error) {
+	query := `SELECT e.id, e.description, e.amount, e.type, e.category, COALESCE(e.account_id, 0), COALESCE(a.name, ''),
+			e.installment_plan_id, e.installment_number, p.installments, substr(e.date, 1, 10)
+		FROM expenses e
+		JOIN installment_plans p ON p.id = e.installment_plan_id
+		LEFT JOIN accounts a ON a.id = e.account_id
+		WHERE e.deleted_at IS NULL AND ` + condition
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []models.Expense
+	for rows.Next() {
+		var e models.Expense
+		var date string
+		if err := rows.Scan(&e.ID, &e.Description, &e.Amount, &e.Type, &e.Category, &e.AccountID, &e.AccountName,
+			&e.InstallmentPlanID, &e.InstallmentNumber, &e.InstallmentCount, &date); err != nil {
+			return nil, err
+		}
+		e.Date = parseSQLiteTime(date)
+		expenses = append(expenses, e)
+	}
+	return expenses, nil
+}
+
+// UpdateRemaining altera o plano e as parcelas ativas com data depois de after, em uma única transação
+// amount > 0 troca o valor de cada parcela restante; mudar a conta passa as parcelas para as faturas do novo cartão
+func (r *InstallmentRepository) UpdateRemaining(plan *models.InstallmentPlan, amount models.Money, after time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE installment_plans SET description = ?, category = ?, payer = ?, payer_user_id = ?, account_id = ? WHERE id = ?`
+	result, err := tx.Exec(query, plan.Description, plan.Category, plan.Payer, planPayerID(plan), plan.AccountID, plan.ID)
+	if err != nil {
+		return 0, err
+	}
+	if err := requireAffected(result); err != nil {
+		return 0, err
+	}
+
+	query = `UPDATE expenses SET description = ?, category = ?, payer = ?, payer_user_id = ?,
+			amount = CASE WHEN ? > 0 THEN ? ELSE amount END,
+			invoice_id = CASE WHEN account_id = ? THEN invoice_id END,
+			account_id = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE installment_plan_id = ? AND deleted_at IS NULL AND substr(date, 1, 10) > ?`
+	result, err = tx.Exec(query, plan.Description, plan.Category, plan.Payer, planPayerID(plan),
+		amount, amount, plan.AccountID, plan.AccountID, plan.ID, after.Format("2006-01-02"))
+	if err != nil {
+		return 0, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := assignInvoices(tx, "e.installment_plan_id = ?", plan.ID); err != nil {
+		return 0, err
+	}
+	return updated, tx.Commit()
+}
+
+// CancelRemaining move para a lixeira as parcelas ativas com data depois de after e marca o plano
+// como cancelado, em uma única transação. Retorna quantas parcelas foram canceladas
+func (r *InstallmentRepository) CancelRemaining(planID int, after time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE expenses SET deleted_at = CURRENT_TIMESTAMP
+		WHERE installment_plan_id = ? AND deleted_at IS NULL AND substr(date, 1, 10) > ?`, planID, after.Format("2006-01-02"))
+	if err != nil {
+		return 0, err
+	}
+	cancelled, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`UPDATE installment_plans SET cancelled_at = CURRENT_TIMESTAMP WHERE id = ?`, planID); err != nil {
+		return 0, err
+	}
+	return cancelled, tx.Commit()
+}
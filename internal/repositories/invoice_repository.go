@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+	"time"
+)
+
+type InvoiceRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+// invoiceSelect lista as faturas com o total e a quantidade de lançamentos ativos e o pagamento
+const invoiceSelect = `
+	SELECT i.id, i.account_id, a.name, i.month, substr(i.closing_date, 1, 10), substr(i.due_date, 1, 10),
+		COALESCE(SUM(CASE WHEN e.type = 'receita' THEN -e.amount ELSE e.amount END), 0), count(e.id),
+		COALESCE(i.transfer_id, 0), COALESCE(t.amount, 0), COALESCE(substr(t.date, 1, 10), '')
+	FROM invoices i
+	JOIN accounts a ON a.id = i.account_id
+	LEFT JOIN expenses e ON e.invoice_id = i.id AND e.deleted_at IS NULL
+	LEFT JOIN transfers t ON t.id = i.transfer_id
+`
+
+func scanInvoice(scanner rowScanner) (models.Invoice, error) {
+	var inv models.Invoice
+	var closing, due, paid string
+	err := scanner.Scan(&inv.ID, &inv.AccountID, &inv.AccountName, &inv.Month, &closing, &due,
+		&inv.Total, &inv.Count, &inv.TransferID, &inv.PaidAmount, &paid)
+	inv.ClosingDate = parseSQLiteTime(closing)
+	inv.DueDate = parseSQLiteTime(due)
+	inv.PaidDate = parseSQLiteTime(paid)
+	return inv, err
+}
+
+// FindAll retorna as faturas do cartão (0 = todos os cartões), da mais recente para a mais antiga
+// Faturas que ficaram vazias (compras movidas ou removidas) e não foram pagas são omitidas
+func (r *InvoiceRepository) FindAll(accountID int) ([]models.Invoice, error) {
+	query := invoiceSelect + `
+		WHERE ?1 = 0 OR i.account_id = ?1
+		GROUP BY i.id
+		HAVING count(e.id) > 0 OR i.transfer_id IS NOT NULL
+		ORDER BY i.due_date DESC, a.name COLLATE NOCASE`
+	rows, err := r.db.Query(query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invoices []models.Invoice
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, inv)
+	}
+	return invoices, nil
+}
+
+// FindByID retorna uma fatura pelo ID
+func (r *InvoiceRepository) FindByID(id int) (*models.Invoice, error) {
+	inv, err := scanInvoice(r.db.QueryRow(invoiceSelect+` WHERE i.id = ? GROUP BY i.id`, id))
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// FindExpenses retorna os lançamentos ativos da fatura, por data
+func (r *InvoiceRepository) FindExpenses(invoiceID int) ([]models.Expense, error) {
+	query := `SELECT id, description, amount, type, category, substr(date, 1, 10)
+		FROM expenses
+		WHERE invoice_id = ? AND deleted_at IS NULL
+		ORDER BY date, id`
+	rows, err := r.db.Query(query, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var expenses []models.Expense
+	for rows.Next() {
+		var e models.Expense
+		var date string
+		if err := rows.Scan(&e.ID, &e.Description, &e.Amount, &e.Type, &e.Category, &date); err != nil {
+			return nil, err
+		}
+		e.Date = parseSQLiteTime(date)
+		expenses = append(expenses, e)
+	}
+	return expenses, nil
+}
+
+// AssignPending coloca na fatura certa todos os lançamentos em cartões de crédito ainda sem fatura
+// Usado só ao iniciar o servidor, para lançamentos gravados antes das faturas existirem;
+// quem grava lançamentos chama assignInvoices na própria transação
+func (r *InvoiceRepository) AssignPending() error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := assignInvoices(tx, "1 = 1"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// assignInvoices coloca na fatura do fechamento certo os lançamentos ativos em cartões de crédito,
+// ainda sem fatura, que atendem à condição (sobre "e", a tabela expenses), criando a fatura se preciso
+// Roda na transação de quem gravou os lançamentos, então o lançamento e a fatura são gravados juntos
+func assignInvoices(tx *sql.Tx, condition string, args ...any) error {
+	query := `SELECT e.id, substr(e.date, 1, 10), a.id, a.closing_day, a.due_day
+		FROM expenses e
+		JOIN accounts a ON a.id = e.account_id
+		WHERE a.type = 'cartao' AND a.closing_day > 0 AND e.invoice_id IS NULL AND e.deleted_at IS NULL
+			AND ` + condition + `
+		ORDER BY e.id`
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	type pending struct {
+		expenseID int
+		date      time.Time
+		card      models.Account
+	}
+	var expenses []pending
+	for rows.Next() {
+		var p pending
+		var date string
+		if err := rows.Scan(&p.expenseID, &date, &p.card.ID, &p.card.ClosingDay, &p.card.DueDay); err != nil {
+			rows.Close()
+			return err
+		}
+		p.date = parseSQLiteTime(date)
+		expenses = append(expenses, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range expenses {
+		closing, due := p.card.InvoiceDates(p.date)
+		month := due.Format("2006-01")
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO invoices (account_id, month, closing_date, due_date) VALUES (?, ?, ?, ?)`,
+			p.card.ID, month, closing.Format("2006-01-02"), due.Format("2006-01-02")); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE expenses SET invoice_id = (SELECT id FROM invoices WHERE account_id = ? AND month = ?) WHERE id = ?`,
+			p.card.ID, month, p.expenseID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pay grava a transferência de pagamento e a liga à fatura, em uma única transação
+// Uma fatura já paga retorna sql.ErrNoRows
+func (r *InvoiceRepository) Pay(invoiceID int, transfer *models.Transfer) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO transfers (from_account_id, to_account_id, amount, date, description) VALUES (?, ?, ?, ?, ?)`
+	result, err := tx.Exec(query, transfer.FromAccountID, transfer.ToAccountID, transfer.Amount,
+		transfer.Date.Format("2006-01-02"), transfer.Description)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	transfer.ID = int(id)
+
+	result, err = tx.Exec(`UPDATE invoices SET transfer_id = ? WHERE id = ? AND transfer_id IS NULL`, transfer.ID, invoiceID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+)
+
+type MonthlyBalanceRepository struct {
+	db *sql.DB
+}
+
+func NewMonthlyBalanceRepository(db *sql.DB) *MonthlyBalanceRepository {
+	return &MonthlyBalanceRepository{db: db}
+}
+
+// CloseMonth grava o fechamento do mês e o snapshot dos balanços em uma única transação
+func (r *MonthlyBalanceRepository) CloseMonth(closing *models.MonthClosing, balances []models.MonthlyBalance) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO month_closings (month, total_spent, member_count, share_per_person, carry_over) VALUES (?, ?, ?, ?, ?)`,
+		closing.Month, closing.TotalSpent, closing.MemberCount, closing.SharePerPerson, closing.CarryOver,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Remove snapshot antigo do mês (se houver) antes de gravar o novo
+	if _, err := tx.Exec(`DELETE FROM monthly_balances WHERE month = ?`, closing.Month); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO monthly_balances (user_id, month, total_paid, share_value, balance, weight) VALUES (?, ?, ?, ?, ?, ?)`
+	for _, b := range balances {
+		if _, err := tx.Exec(query, b.UserID, closing.Month, b.TotalPaid, b.ShareValue, b.Balance, b.Weight); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReopenMonth remove o fechamento e o snapshot do mês, voltando ao cálculo ao vivo
+func (r *MonthlyBalanceRepository) ReopenMonth(month string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM monthly_balances WHERE month = ?`, month); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM month_closings WHERE month = ?`, month); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindClosing retorna o fechamento de um mês (sql.ErrNoRows se o mês está aberto)
+func (r *MonthlyBalanceRepository) FindClosing(month string) (*models.MonthClosing, error) {
+	query := `
+		SELECT month, total_spent, member_count, COALESCE(share_per_person, 0), carry_over, closed_at
+		FROM month_closings
+		WHERE month = ?
+	`
+	row := r.db.QueryRow(query, month)
+
+	var c models.MonthClosing
+	var closedAtStr string
+	if err := row.Scan(&c.Month, &c.TotalSpent, &c.MemberCount, &c.SharePerPerson, &c.CarryOver, &closedAtStr); err != nil {
+		return nil, err
+	}
+	c.ClosedAt = parseSQLiteTime(closedAtStr)
+	return &c, nil
+}
+
+// IsClosed indica se o mês já foi fechado
+func (r *MonthlyBalanceRepository) IsClosed(month string) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM month_closings WHERE month = ?`, month).Scan(&count)
+	return count > 0, err
+}
+
+// FindByMonth retorna o snapshot dos balanços de um mês fechado
+func (r *MonthlyBalanceRepository) FindByMonth(month string) ([]models.MonthlyBalance, error) {
+	query := `
+		SELECT mb.user_id, COALESCE(u.name, 'Membro removido'), mb.month, mb.total_paid, mb.share_value, mb.balance, COALESCE(mb.weight, -1)
+		FROM monthly_balances mb
+		LEFT JOIN users u ON mb.user_id = u.id
+		WHERE mb.month = ?
+		ORDER BY u.name
+	`
+	rows, err := r.db.Query(query, month)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var balances []models.MonthlyBalance
+	for rows.Next() {
+		var b models.MonthlyBalance
+		if err := rows.Scan(&b.UserID, &b.UserName, &b.Month, &b.TotalPaid, &b.ShareValue, &b.Balance, &b.Weight); err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+	return balances, nil
+}
+
+// GetCarriedBalances retorna, por membro, o saldo em aberto herdado pelo mês informado
+// Só meses fechados com carry-over passam saldo adiante: o herdado é o acumulado (saldo do mês +
+// acertos pagos nele) dos meses com carry-over depois do último fechamento sem carry-over, que
+// zera a conta (o acerto daquele mês é feito nele mesmo, pelo plano de acerto)
+func (r *MonthlyBalanceRepository) GetCarriedBalances(beforeMonth string) (map[int]models.Money, error) {
+	query := `
+		WITH carried AS (
+			SELECT month FROM month_closings
+			WHERE carry_over = 1 AND month < ?1
+				AND month > (SELECT COALESCE(MAX(month), '') FROM month_closings WHERE carry_over = 0 AND month < ?1)
+		)
+		SELECT user_id, SUM(amount) FROM (
+			SELECT mb.user_id, mb.balance AS amount
+			FROM monthly_balances mb
+			WHERE mb.month IN carried
+			UNION ALL
+			SELECT s.from_user_id, s.amount
+			FROM settlements s
+			WHERE s.month IN carried
+			UNION ALL
+			SELECT s.to_user_id, -s.amount
+			FROM settlements s
+			WHERE s.month IN carried
+		)
+		GROUP BY user_id
+	`
+	rows, err := r.db.Query(query, beforeMonth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[int]models.Money)
+	for rows.Next() {
+		var userID int
+		var amount models.Money
+		if err := rows.Scan(&userID, &amount); err != nil {
+			return nil, err
+		}
+		balances[userID] = amount
+	}
+	return balances, nil
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+)
+
+type ParticipationRepository struct {
+	db *sql.DB
+}
+
+func NewParticipationRepository(db *sql.DB) *ParticipationRepository {
+	return &ParticipationRepository{db: db}
+}
+
+// Save define (ou substitui) o peso de um membro em um mês
+func (r *ParticipationRepository) Save(p *models.MonthParticipation) error {
+	query := `INSERT OR REPLACE INTO user_month_weights (user_id, month, weight) VALUES (?, ?, ?)`
+	_, err := r.db.Exec(query, p.UserID, p.Month, p.Weight)
+	return err
+}
+
+// Delete remove o peso configurado, voltando à cota cheia no mês
+func (r *ParticipationRepository) Delete(userID int, month string) error {
+	_, err := r.db.Exec(`DELETE FROM user_month_weights WHERE user_id = ? AND month = ?`, userID, month)
+	return err
+}
+
+// GetWeightsByMonth retorna os pesos configurados em um mês, por membro
+func (r *ParticipationRepository) GetWeightsByMonth(month string) (map[int]float64, error) {
+	rows, err := r.db.Query(`SELECT user_id, weight FROM user_month_weights WHERE month = ?`, month)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weights := make(map[int]float64)
+	for rows.Next() {
+		var userID int
+		var weight float64
+		if err := rows.Scan(&userID, &weight); err != nil {
+			return nil, err
+		}
+		weights[userID] = weight
+	}
+	return weights, nil
+}
+
+// FindByUser retorna os pesos configurados de um membro (mais recentes primeiro)
+func (r *ParticipationRepository) FindByUser(userID int) ([]models.MonthParticipation, error) {
+	query := `
+		SELECT w.user_id, u.name, w.month, w.weight
+		FROM user_month_weights w
+		JOIN users u ON w.user_id = u.id
+		WHERE w.user_id = ?
+		ORDER BY w.month DESC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var participations []models.MonthParticipation
+	for rows.Next() {
+		var p models.MonthParticipation
+		if err := rows.Scan(&p.UserID, &p.UserName, &p.Month, &p.Weight); err != nil {
+			return nil, err
+		}
+		participations = append(participations, p)
+	}
+	return participations, nil
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+)
+
+type PointTransactionRepository struct {
+	db *sql.DB
+}
+
+func NewPointTransactionRepository(db *sql.DB) *PointTransactionRepository {
+	return &PointTransactionRepository{db: db}
+}
+
+// Create registra um lançamento no extrato de pontos
+func (r *PointTransactionRepository) Create(t *models.PointTransaction) error {
+	return insertPointTransaction(r.db, t)
+}
+
+// insertPointTransaction grava um lançamento do extrato, no banco ou dentro de uma transação
+func insertPointTransaction(db execer, t *models.PointTransaction) error {
+	query := `INSERT INTO point_transactions (user_id, delta, reason, month, purchase_id) VALUES (?, ?, ?, ?, ?)`
+	result, err := db.Exec(query, t.UserID, t.Delta, t.Reason, t.Month, nullID(t.PurchaseID))
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	t.ID = int(id)
+	return nil
+}
+
+// revokePurchasePoints estorna, na transação da compra, o saldo de pontos que cada membro
+// ainda tem por ela; o estorno vai para o mês atual da compra
+func revokePurchasePoints(tx *sql.Tx, purchaseID int) error {
+	query := `
+		INSERT INTO point_transactions (user_id, delta, reason, month, purchase_id)
+		SELECT pt.user_id, -SUM(pt.delta), ?, p.month, pt.purchase_id
+		FROM point_transactions pt
+		JOIN purchases p ON p.id = pt.purchase_id
+		WHERE pt.purchase_id = ?
+		GROUP BY pt.user_id
+		HAVING SUM(pt.delta) <> 0
+	`
+	_, err := tx.Exec(query, models.ReasonPurchaseRevoked, purchaseID)
+	return err
+}
+
+// SumByPurchase retorna o saldo de pontos gerado por uma compra, por membro
+func (r *PointTransactionRepository) SumByPurchase(purchaseID int) (map[int]int, error) {
+	query := `
+		SELECT user_id, SUM(delta)
+		FROM point_transactions
+		WHERE purchase_id = ?
+		GROUP BY user_id
+	`
+	rows, err := r.db.Query(query, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sums := make(map[int]int)
+	for rows.Next() {
+		var userID, sum int
+		if err := rows.Scan(&userID, &sum); err != nil {
+			return nil, err
+		}
+		sums[userID] = sum
+	}
+	return sums, nil
+}
+
+// FindByUser retorna o extrato completo de um membro (mais recentes primeiro)
+func (r *PointTransactionRepository) FindByUser(userID int) ([]models.PointTransaction, error) {
+	query := `
+		SELECT pt.id, pt.user_id, u.name, pt.delta, pt.reason, pt.month, pt.purchase_id, pt.created_at
+		FROM point_transactions pt
+		JOIN users u ON pt.user_id = u.id
+		WHERE pt.user_id = ?
+		ORDER BY pt.created_at DESC, pt.id DESC
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []models.PointTransaction
+	for rows.Next() {
+		var t models.PointTransaction
+		var purchaseID sql.NullInt64
+		var createdAtStr string
+		if err := rows.Scan(&t.ID, &t.UserID, &t.UserName, &t.Delta, &t.Reason, &t.Month, &purchaseID, &createdAtStr); err != nil {
+			return nil, err
+		}
+		t.PurchaseID = int(purchaseID.Int64)
+		t.CreatedAt = parseSQLiteTime(createdAtStr)
+		transactions = append(transactions, t)
+	}
+	return transactions, nil
+}
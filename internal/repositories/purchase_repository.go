@@ -3,7 +3,7 @@ package repositories
 import (
 	"database/sql"
 	"financas/internal/models"
-	"time"
+	"strings"
 )
 
 type PurchaseRepository struct {
@@ -14,13 +14,20 @@ func NewPurchaseRepository(db *sql.DB) *PurchaseRepository {
 	return &PurchaseRepository{db: db}
 }
 
-// Create insere uma nova compra de lanche
-func (r *PurchaseRepository) Create(purchase *models.Purchase) error {
+// Create insere uma nova compra de lanche junto com seus participantes
+// award, quando não nil, é lançado no extrato na mesma transação, ligado à compra
+func (r *PurchaseRepository) Create(purchase *models.Purchase, award *models.PointTransaction) error {
 	// Extrair mês da data (formato "2026-02")
 	purchase.Month = purchase.Date.Format("2006-01")
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `INSERT INTO purchases (user_id, amount, date, month) VALUES (?, ?, ?, ?)`
-	result, err := r.db.Exec(query, purchase.UserID, purchase.Amount, purchase.Date.Format("2006-01-02"), purchase.Month)
+	result, err := tx.Exec(query, purchase.UserID, purchase.Amount, purchase.Date.Format("2006-01-02"), purchase.Month)
 	if err != nil {
 		return err
 	}
@@ -29,6 +36,34 @@ func (r *PurchaseRepository) Create(purchase *models.Purchase) error {
 		return err
 	}
 	purchase.ID = int(id)
+
+	if err := replaceParticipants(tx, purchase); err != nil {
+		return err
+	}
+	if award != nil {
+		award.PurchaseID = purchase.ID
+		award.Month = purchase.Month
+		if err := insertPointTransaction(tx, award); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// replaceParticipants regrava os participantes de uma compra
+func replaceParticipants(tx *sql.Tx, purchase *models.Purchase) error {
+	if _, err := tx.Exec(`DELETE FROM purchase_participants WHERE purchase_id = ?`, purchase.ID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO purchase_participants (purchase_id, user_id, weight, amount) VALUES (?, ?, ?, ?)`
+	for i := range purchase.Participants {
+		p := &purchase.Participants[i]
+		p.PurchaseID = purchase.ID
+		if _, err := tx.Exec(query, p.PurchaseID, p.UserID, p.Weight, p.Amount); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -38,6 +73,7 @@ func (r *PurchaseRepository) FindAll() ([]models.Purchase, error) {
 		SELECT p.id, p.user_id, u.name, p.amount, p.date, p.month, p.created_at
 		FROM purchases p
 		JOIN users u ON p.user_id = u.id
+		WHERE p.deleted_at IS NULL
 		ORDER BY p.date DESC
 	`
 	rows, err := r.db.Query(query)
@@ -53,8 +89,8 @@ func (r *PurchaseRepository) FindAll() ([]models.Purchase, error) {
 		if err := rows.Scan(&p.ID, &p.UserID, &p.UserName, &p.Amount, &dateStr, &p.Month, &createdAtStr); err != nil {
 			return nil, err
 		}
-		p.Date, _ = time.Parse("2006-01-02", dateStr)
-		p.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAtStr)
+		p.Date = parseSQLiteTime(dateStr)
+		p.CreatedAt = parseSQLiteTime(createdAtStr)
 		purchases = append(purchases, p)
 	}
 	return purchases, nil
@@ -66,7 +102,7 @@ func (r *PurchaseRepository) FindByMonth(month string) ([]models.Purchase, error
 		SELECT p.id, p.user_id, u.name, p.amount, p.date, p.month, p.created_at
 		FROM purchases p
 		JOIN users u ON p.user_id = u.id
-		WHERE p.month = ?
+		WHERE p.month = ? AND p.deleted_at IS NULL
 		ORDER BY p.date DESC
 	`
 	rows, err := r.db.Query(query, month)
@@ -82,20 +118,68 @@ func (r *PurchaseRepository) FindByMonth(month string) ([]models.Purchase, error
 		if err := rows.Scan(&p.ID, &p.UserID, &p.UserName, &p.Amount, &dateStr, &p.Month, &createdAtStr); err != nil {
 			return nil, err
 		}
-		p.Date, _ = time.Parse("2006-01-02", dateStr)
-		p.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAtStr)
+		p.Date = parseSQLiteTime(dateStr)
+		p.CreatedAt = parseSQLiteTime(createdAtStr)
 		purchases = append(purchases, p)
 	}
+
+	participants, err := r.findParticipantsByMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	for i := range purchases {
+		purchases[i].Participants = participants[purchases[i].ID]
+	}
 	return purchases, nil
 }
 
-// FindByID retorna uma compra pelo ID
+// FindByUsers retorna as compras mais recentes pagas pelos membros informados
+func (r *PurchaseRepository) FindByUsers(userIDs []int, limit int) ([]models.Purchase, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(userIDs)), ",")
+	args := make([]interface{}, 0, len(userIDs)+1)
+	for _, id := range userIDs {
+		args = append(args, id)
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT p.id, p.user_id, u.name, p.amount, p.date, p.month, p.created_at
+		FROM purchases p
+		JOIN users u ON p.user_id = u.id
+		WHERE p.user_id IN (` + placeholders + `) AND p.deleted_at IS NULL
+		ORDER BY p.date DESC, p.id DESC
+		LIMIT ?
+	`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var purchases []models.Purchase
+	for rows.Next() {
+		var p models.Purchase
+		var dateStr, createdAtStr string
+		if err := rows.Scan(&p.ID, &p.UserID, &p.UserName, &p.Amount, &dateStr, &p.Month, &createdAtStr); err != nil {
+			return nil, err
+		}
+		p.Date = parseSQLiteTime(dateStr)
+		p.CreatedAt = parseSQLiteTime(createdAtStr)
+		purchases = append(purchases, p)
+	}
+	return purchases, nil
+}
+
+// FindByID retorna uma compra ativa pelo ID
 func (r *PurchaseRepository) FindByID(id int) (*models.Purchase, error) {
 	query := `
 		SELECT p.id, p.user_id, u.name, p.amount, p.date, p.month, p.created_at
 		FROM purchases p
 		JOIN users u ON p.user_id = u.id
-		WHERE p.id = ?
+		WHERE p.id = ? AND p.deleted_at IS NULL
 	`
 	row := r.db.QueryRow(query, id)
 
@@ -104,24 +188,128 @@ func (r *PurchaseRepository) FindByID(id int) (*models.Purchase, error) {
 	if err := row.Scan(&p.ID, &p.UserID, &p.UserName, &p.Amount, &dateStr, &p.Month, &createdAtStr); err != nil {
 		return nil, err
 	}
-	p.Date, _ = time.Parse("2006-01-02", dateStr)
-	p.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAtStr)
+	p.Date = parseSQLiteTime(dateStr)
+	p.CreatedAt = parseSQLiteTime(createdAtStr)
+
+	participants, err := r.FindParticipants(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	p.Participants = participants
 	return &p, nil
 }
 
-// Delete remove uma compra
+// FindParticipants retorna os participantes de uma compra (vazio = dividida entre todos)
+func (r *PurchaseRepository) FindParticipants(purchaseID int) ([]models.PurchaseParticipant, error) {
+	query := `
+		SELECT pp.purchase_id, pp.user_id, COALESCE(u.name, 'Membro removido'), pp.weight, pp.amount
+		FROM purchase_participants pp
+		LEFT JOIN users u ON pp.user_id = u.id
+		WHERE pp.purchase_id = ?
+		ORDER BY u.name
+	`
+	rows, err := r.db.Query(query, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var participants []models.PurchaseParticipant
+	for rows.Next() {
+		var pp models.PurchaseParticipant
+		if err := rows.Scan(&pp.PurchaseID, &pp.UserID, &pp.UserName, &pp.Weight, &pp.Amount); err != nil {
+			return nil, err
+		}
+		participants = append(participants, pp)
+	}
+	return participants, nil
+}
+
+// findParticipantsByMonth retorna os participantes das compras do mês, agrupados por compra
+func (r *PurchaseRepository) findParticipantsByMonth(month string) (map[int][]models.PurchaseParticipant, error) {
+	query := `
+		SELECT pp.purchase_id, pp.user_id, COALESCE(u.name, 'Membro removido'), pp.weight, pp.amount
+		FROM purchase_participants pp
+		JOIN purchases p ON pp.purchase_id = p.id
+		LEFT JOIN users u ON pp.user_id = u.id
+		WHERE p.month = ? AND p.deleted_at IS NULL
+		ORDER BY u.name
+	`
+	rows, err := r.db.Query(query, month)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	participants := make(map[int][]models.PurchaseParticipant)
+	for rows.Next() {
+		var pp models.PurchaseParticipant
+		if err := rows.Scan(&pp.PurchaseID, &pp.UserID, &pp.UserName, &pp.Weight, &pp.Amount); err != nil {
+			return nil, err
+		}
+		participants[pp.PurchaseID] = append(participants[pp.PurchaseID], pp)
+	}
+	return participants, nil
+}
+
+// Update altera dono, valor, data e participantes de uma compra
+// Com award (troca de dono), os pontos da compra são estornados e award é lançado na mesma transação
+func (r *PurchaseRepository) Update(purchase *models.Purchase, award *models.PointTransaction) error {
+	purchase.Month = purchase.Date.Format("2006-01")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE purchases SET user_id = ?, amount = ?, date = ?, month = ? WHERE id = ? AND deleted_at IS NULL`
+	if _, err := tx.Exec(query, purchase.UserID, purchase.Amount, purchase.Date.Format("2006-01-02"), purchase.Month, purchase.ID); err != nil {
+		return err
+	}
+
+	if err := replaceParticipants(tx, purchase); err != nil {
+		return err
+	}
+
+	if award != nil {
+		if err := revokePurchasePoints(tx, purchase.ID); err != nil {
+			return err
+		}
+		award.PurchaseID = purchase.ID
+		award.Month = purchase.Month
+		if err := insertPointTransaction(tx, award); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Delete marca uma compra como removida (soft delete) e estorna os pontos que ela gerou,
+// na mesma transação para o extrato não ficar com pontos de compra removida
 func (r *PurchaseRepository) Delete(id int) error {
-	query := `DELETE FROM purchases WHERE id = ?`
-	_, err := r.db.Exec(query, id)
-	return err
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE purchases SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?`
+	if _, err := tx.Exec(query, id); err != nil {
+		return err
+	}
+	if err := revokePurchasePoints(tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // GetMonthlyTotalByUser retorna o total pago por cada usuário em um mês
-func (r *PurchaseRepository) GetMonthlyTotalByUser(month string) (map[int]float64, error) {
+func (r *PurchaseRepository) GetMonthlyTotalByUser(month string) (map[int]models.Money, error) {
 	query := `
 		SELECT user_id, SUM(amount) as total
 		FROM purchases
-		WHERE month = ?
+		WHERE month = ? AND deleted_at IS NULL
 		GROUP BY user_id
 	`
 	rows, err := r.db.Query(query, month)
@@ -130,10 +318,10 @@ func (r *PurchaseRepository) GetMonthlyTotalByUser(month string) (map[int]float6
 	}
 	defer rows.Close()
 
-	totals := make(map[int]float64)
+	totals := make(map[int]models.Money)
 	for rows.Next() {
 		var userID int
-		var total float64
+		var total models.Money
 		if err := rows.Scan(&userID, &total); err != nil {
 			return nil, err
 		}
@@ -143,9 +331,9 @@ func (r *PurchaseRepository) GetMonthlyTotalByUser(month string) (map[int]float6
 }
 
 // GetMonthlyTotal retorna o total gasto no mês
-func (r *PurchaseRepository) GetMonthlyTotal(month string) (float64, error) {
-	var total float64
-	err := r.db.QueryRow(`SELECT COALESCE(SUM(amount), 0) FROM purchases WHERE month = ?`, month).Scan(&total)
+func (r *PurchaseRepository) GetMonthlyTotal(month string) (models.Money, error) {
+	var total models.Money
+	err := r.db.QueryRow(`SELECT COALESCE(SUM(amount), 0) FROM purchases WHERE month = ? AND deleted_at IS NULL`, month).Scan(&total)
 	return total, err
 }
 
@@ -154,7 +342,7 @@ func (r *PurchaseRepository) GetPurchaseCountByUser(month string) (map[int]int,
 	query := `
 		SELECT user_id, COUNT(*) as count
 		FROM purchases
-		WHERE month = ?
+		WHERE month = ? AND deleted_at IS NULL
 		GROUP BY user_id
 	`
 	rows, err := r.db.Query(query, month)
@@ -177,7 +365,7 @@ func (r *PurchaseRepository) GetPurchaseCountByUser(month string) (map[int]int,
 
 // GetDistinctMonths retorna lista de meses com compras
 func (r *PurchaseRepository) GetDistinctMonths() ([]string, error) {
-	query := `SELECT DISTINCT month FROM purchases ORDER BY month DESC`
+	query := `SELECT DISTINCT month FROM purchases WHERE deleted_at IS NULL ORDER BY month DESC`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+	"time"
+)
+
+type RecurrenceRepository struct {
+	db *sql.DB
+}
+
+func NewRecurrenceRepository(db *sql.DB) *RecurrenceRepository {
+	return &RecurrenceRepository{db: db}
+}
+
+// recurrenceColumns é a lista de colunas lida por todas as consultas de recorrências
+const recurrenceColumns = `
+	r.id, r.description, r.amount, r.type, r.category, COALESCE(u.name, ''), COALESCE(r.payer_user_id, 0),
+	COALESCE(r.account_id, 0), COALESCE(a.name, ''), r.frequency, r.interval_days, r.day_of_month, r.start_date, r.end_date, r.next_date, r.paused,
+	(SELECT COUNT(*) FROM expenses e WHERE e.recurrence_id = r.id AND e.deleted_at IS NULL),
+	r.created_at, r.updated_at
+`
+
+// scanRecurrence lê uma recorrência a partir das colunas de recurrenceColumns
+func scanRecurrence(row rowScanner) (models.Recurrence, error) {
+	var rec models.Recurrence
+	var startDate, nextDate, createdAt, updatedAt string
+	var endDate sql.NullString
+	err := row.Scan(&rec.ID, &rec.Description, &rec.Amount, &rec.Type, &rec.Category, &rec.Payer, &rec.PayerUserID,
+		&rec.AccountID, &rec.AccountName, &rec.Frequency, &rec.Interval, &rec.DayOfMonth, &startDate, &endDate, &nextDate, &rec.Paused,
+		&rec.Generated, &createdAt, &updatedAt)
+	if err != nil {
+		return rec, err
+	}
+	rec.StartDate = parseSQLiteTime(startDate)
+	rec.EndDate = parseSQLiteTime(endDate.String)
+	rec.NextDate = parseSQLiteTime(nextDate)
+	rec.CreatedAt = parseSQLiteTime(createdAt)
+	rec.UpdatedAt = parseSQLiteTime(updatedAt)
+	return rec, nil
+}
+
+// recurrencePayerID converte o pagador da recorrência para a coluna (NULL = fora do rateio)
+func recurrencePayerID(rec *models.Recurrence) sql.NullInt64 {
+	if rec.PayerUserID <= 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(rec.PayerUserID), Valid: true}
+}
+
+// Create insere uma nova recorrência
+func (r *RecurrenceRepository) Create(rec *models.Recurrence) error {
+	query := `INSERT INTO recurrences
+		(description, amount, type, category, payer_user_id, account_id, frequency, interval_days, day_of_month, start_date, end_date, next_date, paused)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, rec.Description, rec.Amount, rec.Type, rec.Category, recurrencePayerID(rec), rec.AccountID,
+		rec.Frequency, rec.Interval, rec.DayOfMonth, dateOrNull(rec.StartDate), dateOrNull(rec.EndDate),
+		dateOrNull(rec.NextDate), rec.Paused)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rec.ID = int(id)
+	return nil
+}
+
+// Update grava o modelo e o agendamento da recorrência (lançamentos já gerados não mudam)
+func (r *RecurrenceRepository) Update(rec *models.Recurrence) error {
+	query := `UPDATE recurrences SET description = ?, amount = ?, type = ?, category = ?, payer_user_id = ?, account_id = ?,
+		frequency = ?, interval_days = ?, day_of_month = ?, start_date = ?, end_date = ?, next_date = ?, paused = ?,
+		updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`
+	_, err := r.db.Exec(query, rec.Description, rec.Amount, rec.Type, rec.Category, recurrencePayerID(rec), rec.AccountID,
+		rec.Frequency, rec.Interval, rec.DayOfMonth, dateOrNull(rec.StartDate), dateOrNull(rec.EndDate),
+		dateOrNull(rec.NextDate), rec.Paused, rec.ID)
+	return err
+}
+
+// FindAll retorna todas as recorrências, ordenadas pela próxima ocorrência
+func (r *RecurrenceRepository) FindAll() ([]models.Recurrence, error) {
+	query := `SELECT ` + recurrenceColumns + `
+		FROM recurrences r
+		LEFT JOIN users u ON u.id = r.payer_user_id
+		LEFT JOIN accounts a ON a.id = r.account_id
+		ORDER BY r.next_date, r.description`
+	return r.findMany(query)
+}
+
+// FindDue retorna as recorrências ativas com ocorrência vencida até a data informada
+func (r *RecurrenceRepository) FindDue(today time.Time) ([]models.Recurrence, error) {
+	query := `SELECT ` + recurrenceColumns + `
+		FROM recurrences r
+		LEFT JOIN users u ON u.id = r.payer_user_id
+		LEFT JOIN accounts a ON a.id = r.account_id
+		WHERE r.paused = 0 AND r.next_date <= ? AND (r.end_date IS NULL OR r.next_date <= r.end_date)
+		ORDER BY r.id`
+	return r.findMany(query, dateOrNull(today))
+}
+
+// findMany executa uma consulta de recorrências
+func (r *RecurrenceRepository) findMany(query string, args ...any) ([]models.Recurrence, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recurrences []models.Recurrence
+	for rows.Next() {
+		rec, err := scanRecurrence(rows)
+		if err != nil {
+			return nil, err
+		}
+		recurrences = append(recurrences, rec)
+	}
+	return recurrences, nil
+}
+
+// FindByID busca uma recorrência pelo ID
+func (r *RecurrenceRepository) FindByID(id int) (*models.Recurrence, error) {
+	query := `SELECT ` + recurrenceColumns + `
+		FROM recurrences r
+		LEFT JOIN users u ON u.id = r.payer_user_id
+		LEFT JOIN accounts a ON a.id = r.account_id
+		WHERE r.id = ?`
+	rec, err := scanRecurrence(r.db.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Generate grava os lançamentos das ocorrências vencidas (no cartão, já nas faturas) e avança a próxima data,
+// tudo em uma única transação. Uma ocorrência já gerada (mesma recorrência e data) é ignorada
+func (r *RecurrenceRepository) Generate(rec *models.Recurrence, dates []time.Time, nextDate time.Time) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT OR IGNORE INTO expenses (description, amount, type, category, payer, payer_user_id, account_id, date, recurrence_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	created := 0
+	for _, date := range dates {
+		result, err := tx.Exec(query, rec.Description, rec.Amount, rec.Type, rec.Category, rec.Payer,
+			recurrencePayerID(rec), rec.AccountID, date, rec.ID)
+		if err != nil {
+			return 0, err
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			created += int(n)
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE recurrences SET next_date = ? WHERE id = ?`, dateOrNull(nextDate), rec.ID); err != nil {
+		return 0, err
+	}
+	if err := assignInvoices(tx, "e.recurrence_id = ?", rec.ID); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return created, nil
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"database/sql"
+	"financas/internal/models"
+)
+
+type SettlementRepository struct {
+	db *sql.DB
+}
+
+func NewSettlementRepository(db *sql.DB) *SettlementRepository {
+	return &SettlementRepository{db: db}
+}
+
+// Create registra um acerto pago
+func (r *SettlementRepository) Create(settlement *models.Settlement) error {
+	query := `INSERT INTO settlements (month, from_user_id, to_user_id, amount) VALUES (?, ?, ?, ?)`
+	result, err := r.db.Exec(query, settlement.Month, settlement.FromUserID, settlement.ToUserID, settlement.Amount)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	settlement.ID = int(id)
+	return nil
+}
+
+// FindByMonth retorna os acertos pagos de um mês
+func (r *SettlementRepository) FindByMonth(month string) ([]models.Settlement, error) {
+	query := `
+		SELECT s.id, s.month, s.from_user_id, COALESCE(f.name, 'Membro removido'),
+			s.to_user_id, COALESCE(t.name, 'Membro removido'), s.amount, s.paid_at
+		FROM settlements s
+		LEFT JOIN users f ON s.from_user_id = f.id
+		LEFT JOIN users t ON s.to_user_id = t.id
+		WHERE s.month = ?
+		ORDER BY s.paid_at DESC
+	`
+	rows, err := r.db.Query(query, month)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settlements []models.Settlement
+	for rows.Next() {
+		var s models.Settlement
+		var paidAtStr string
+		if err := rows.Scan(&s.ID, &s.Month, &s.FromUserID, &s.FromUserName, &s.ToUserID, &s.ToUserName, &s.Amount, &paidAtStr); err != nil {
+			return nil, err
+		}
+		s.PaidAt = parseSQLiteTime(paidAtStr)
+		settlements = append(settlements, s)
+	}
+	return settlements, nil
+}
+
+// Delete remove o registro de um acerto (desfaz o pagamento)
+func (r *SettlementRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM settlements WHERE id = ?`, id)
+	return err
+}
@@ -3,7 +3,6 @@ package repositories
 import (
 	"database/sql"
 	"financas/internal/models"
-	"time"
 )
 
 type UserRepository struct {
@@ -14,6 +13,35 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// userColumns é a lista de colunas lida por todas as consultas de usuários
+// Os pontos são sempre a soma do extrato em point_transactions
+const userColumns = `
+	id, name,
+	COALESCE((SELECT SUM(pt.delta) FROM point_transactions pt WHERE pt.user_id = users.id), 0) AS points,
+	joined_at, left_at, archived_at, created_at, updated_at
+`
+
+// rowScanner é satisfeito por *sql.Row e *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser lê um usuário a partir das colunas de userColumns
+func scanUser(row rowScanner) (models.User, error) {
+	var user models.User
+	var joinedAt, leftAt, archivedAt sql.NullString
+	var createdAt, updatedAt string
+	if err := row.Scan(&user.ID, &user.Name, &user.Points, &joinedAt, &leftAt, &archivedAt, &createdAt, &updatedAt); err != nil {
+		return user, err
+	}
+	user.JoinedAt = parseSQLiteTime(joinedAt.String)
+	user.LeftAt = parseSQLiteTime(leftAt.String)
+	user.ArchivedAt = parseSQLiteTime(archivedAt.String)
+	user.CreatedAt = parseSQLiteTime(createdAt)
+	user.UpdatedAt = parseSQLiteTime(updatedAt)
+	return user, nil
+}
+
 // Create insere um novo usuário/membro da equipe
 func (r *UserRepository) Create(user *models.User) error {
 	query := `INSERT INTO users (name, points) VALUES (?, ?)`
@@ -29,9 +57,24 @@ func (r *UserRepository) Create(user *models.User) error {
 	return nil
 }
 
-// FindAll retorna todos os usuários
+// FindAll retorna os membros ativos (não arquivados)
 func (r *UserRepository) FindAll() ([]models.User, error) {
-	query := `SELECT id, name, points, created_at, updated_at FROM users ORDER BY name`
+	return r.findWhere(`archived_at IS NULL`)
+}
+
+// FindAllWithArchived retorna todos os membros, inclusive arquivados (para cálculos históricos)
+func (r *UserRepository) FindAllWithArchived() ([]models.User, error) {
+	return r.findWhere(`1 = 1`)
+}
+
+// FindArchived retorna apenas os membros arquivados
+func (r *UserRepository) FindArchived() ([]models.User, error) {
+	return r.findWhere(`archived_at IS NOT NULL`)
+}
+
+// findWhere lista os usuários que satisfazem a condição, ordenados por nome
+func (r *UserRepository) findWhere(condition string) ([]models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + condition + ` ORDER BY name`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -40,13 +83,10 @@ func (r *UserRepository) FindAll() ([]models.User, error) {
 
 	var users []models.User
 	for rows.Next() {
-		var user models.User
-		var createdAt, updatedAt string
-		if err := rows.Scan(&user.ID, &user.Name, &user.Points, &createdAt, &updatedAt); err != nil {
+		user, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
-		user.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
-		user.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
 		users = append(users, user)
 	}
 	return users, nil
@@ -54,29 +94,25 @@ func (r *UserRepository) FindAll() ([]models.User, error) {
 
 // FindByID busca um usuário pelo ID
 func (r *UserRepository) FindByID(id int) (*models.User, error) {
-	query := `SELECT id, name, points, created_at, updated_at FROM users WHERE id = ?`
-	row := r.db.QueryRow(query, id)
-
-	var user models.User
-	var createdAt, updatedAt string
-	if err := row.Scan(&user.ID, &user.Name, &user.Points, &createdAt, &updatedAt); err != nil {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+	user, err := scanUser(r.db.QueryRow(query, id))
+	if err != nil {
 		return nil, err
 	}
-	user.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
-	user.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
 	return &user, nil
 }
 
-// UpdatePoints atualiza os pontos de um usuário
-func (r *UserRepository) UpdatePoints(userID int, points int) error {
-	query := `UPDATE users SET points = points + ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	_, err := r.db.Exec(query, points, userID)
+// Update altera nome e período de participação de um membro
+func (r *UserRepository) Update(user *models.User) error {
+	query := `UPDATE users SET name = ?, joined_at = ?, left_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := r.db.Exec(query, user.Name, dateOrNull(user.JoinedAt), dateOrNull(user.LeftAt), user.ID)
 	return err
 }
 
 // GetRanking retorna os usuários ordenados por pontos (ranking)
+// Os pontos são sempre a soma do extrato em point_transactions
 func (r *UserRepository) GetRanking() ([]models.User, error) {
-	query := `SELECT id, name, points, created_at, updated_at FROM users ORDER BY points DESC`
+	query := `SELECT ` + userColumns + ` FROM users WHERE archived_at IS NULL ORDER BY points DESC`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -85,28 +121,33 @@ func (r *UserRepository) GetRanking() ([]models.User, error) {
 
 	var users []models.User
 	for rows.Next() {
-		var user models.User
-		var createdAt, updatedAt string
-		if err := rows.Scan(&user.ID, &user.Name, &user.Points, &createdAt, &updatedAt); err != nil {
+		user, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
-		user.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
-		user.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
 		users = append(users, user)
 	}
 	return users, nil
 }
 
-// Delete remove um usuário (hard delete)
-func (r *UserRepository) Delete(id int) error {
-	query := `DELETE FROM users WHERE id = ?`
+// Archive arquiva um membro (soft delete): some das novas compras e dos rateios futuros,
+// mas compras, saldos e conquistas antigas continuam apontando para ele
+func (r *UserRepository) Archive(id int) error {
+	query := `UPDATE users SET archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND archived_at IS NULL`
+	_, err := r.db.Exec(query, id)
+	return err
+}
+
+// Restore traz de volta um membro arquivado
+func (r *UserRepository) Restore(id int) error {
+	query := `UPDATE users SET archived_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
 	_, err := r.db.Exec(query, id)
 	return err
 }
 
-// Count retorna o número total de usuários
+// Count retorna o número de membros ativos
 func (r *UserRepository) Count() (int, error) {
 	var count int
-	err := r.db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count)
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM users WHERE archived_at IS NULL`).Scan(&count)
 	return count, err
 }
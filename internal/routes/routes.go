@@ -32,6 +32,17 @@ type Controllers struct {
 	User         *controllers.UserController
 	Purchase     *controllers.PurchaseController
 	Gamification *controllers.GamificationController
+	Recurrence   *controllers.RecurrenceController
+	Budget       *controllers.BudgetController
+	Import       *controllers.ImportController
+	Export       *controllers.ExportController
+	Search       *controllers.SearchController
+	Trash        *controllers.TrashController
+	Category     *controllers.CategoryController
+	Account      *controllers.AccountController
+	Invoice      *controllers.InvoiceController
+	Installment  *controllers.InstallmentController
+	Goal         *controllers.GoalController
 }
 
 func RegisterRoutes(c *Controllers) {
@@ -44,25 +55,119 @@ func RegisterRoutes(c *Controllers) {
 	http.HandleFunc("/update", secureHandler(c.Expense.Update))
 	http.HandleFunc("/delete", secureHandler(c.Expense.Delete))
 	http.HandleFunc("/insights", secureHandler(c.Expense.Insights))
+	http.HandleFunc("/rateio", secureHandler(c.Expense.Rateio))
+	http.HandleFunc("/search", secureHandler(c.Search.Index))
+	http.HandleFunc("/trash", secureHandler(c.Trash.Index))
+	http.HandleFunc("/trash/restore", secureHandler(c.Trash.Restore))
+
+	// ============================================
+	// Rotas de Importação de Extratos (CSV)
+	// ============================================
+	http.HandleFunc("/import", secureHandler(c.Import.Index))
+	http.HandleFunc("/import/preview", secureHandler(c.Import.Preview))
+	http.HandleFunc("/import/confirm", secureHandler(c.Import.Confirm))
+	http.HandleFunc("/import/ofx", secureHandler(c.Import.OFX))
+	http.HandleFunc("/import/profiles/delete", secureHandler(c.Import.DeleteProfile))
+
+	// ============================================
+	// Rotas de Exportação (CSV e XLSX)
+	// ============================================
+	http.HandleFunc("/export/expenses", secureHandler(c.Export.Expenses))
+	http.HandleFunc("/export/purchases", secureHandler(c.Export.Purchases))
+	http.HandleFunc("/export/rateio", secureHandler(c.Export.Rateio))
+
+	// ============================================
+	// Rotas de Recorrências (lançamentos que se repetem)
+	// ============================================
+	http.HandleFunc("/recurrences", secureHandler(c.Recurrence.Index))
+	http.HandleFunc("/recurrences/create", secureHandler(c.Recurrence.Create))
+	http.HandleFunc("/recurrences/edit", secureHandler(c.Recurrence.Edit))
+	http.HandleFunc("/recurrences/update", secureHandler(c.Recurrence.Update))
+	http.HandleFunc("/recurrences/pause", secureHandler(c.Recurrence.Pause))
+	http.HandleFunc("/recurrences/resume", secureHandler(c.Recurrence.Resume))
+	http.HandleFunc("/recurrences/end", secureHandler(c.Recurrence.End))
+
+	// ============================================
+	// Rotas de Orçamentos (limite mensal por categoria)
+	// ============================================
+	http.HandleFunc("/budgets", secureHandler(c.Budget.Index))
+	http.HandleFunc("/budgets/save", secureHandler(c.Budget.Save))
+	http.HandleFunc("/budgets/delete", secureHandler(c.Budget.Delete))
+
+	// ============================================
+	// Rotas de Contas e Transferências (saldo por conta)
+	// ============================================
+	http.HandleFunc("/accounts", secureHandler(c.Account.Index))
+	http.HandleFunc("/accounts/create", secureHandler(c.Account.Create))
+	http.HandleFunc("/accounts/edit", secureHandler(c.Account.Edit))
+	http.HandleFunc("/accounts/update", secureHandler(c.Account.Update))
+	http.HandleFunc("/accounts/delete", secureHandler(c.Account.Delete))
+	http.HandleFunc("/transfers/create", secureHandler(c.Account.CreateTransfer))
+	http.HandleFunc("/transfers/delete", secureHandler(c.Account.DeleteTransfer))
+
+	// ============================================
+	// Rotas de Faturas do Cartão
+	// ============================================
+	http.HandleFunc("/invoices", secureHandler(c.Invoice.Index))
+	http.HandleFunc("/invoices/view", secureHandler(c.Invoice.View))
+	http.HandleFunc("/invoices/pay", secureHandler(c.Invoice.Pay))
+
+	// ============================================
+	// Rotas de Compras Parceladas (criadas pelo formulário de lançamento)
+	// ============================================
+	http.HandleFunc("/installments", secureHandler(c.Installment.Index))
+	http.HandleFunc("/installments/view", secureHandler(c.Installment.View))
+	http.HandleFunc("/installments/update", secureHandler(c.Installment.Update))
+	http.HandleFunc("/installments/cancel", secureHandler(c.Installment.Cancel))
+
+	// ============================================
+	// Rotas de Metas de Economia
+	// ============================================
+	http.HandleFunc("/goals", secureHandler(c.Goal.Index))
+	http.HandleFunc("/goals/create", secureHandler(c.Goal.Create))
+	http.HandleFunc("/goals/edit", secureHandler(c.Goal.Edit))
+	http.HandleFunc("/goals/update", secureHandler(c.Goal.Update))
+	http.HandleFunc("/goals/delete", secureHandler(c.Goal.Delete))
+
+	// ============================================
+	// Rotas de Categorias (com subcategorias)
+	// ============================================
+	http.HandleFunc("/categories", secureHandler(c.Category.Index))
+	http.HandleFunc("/categories/create", secureHandler(c.Category.Create))
+	http.HandleFunc("/categories/edit", secureHandler(c.Category.Edit))
+	http.HandleFunc("/categories/update", secureHandler(c.Category.Update))
+	http.HandleFunc("/categories/delete", secureHandler(c.Category.Delete))
+	http.HandleFunc("/categories/merge", secureHandler(c.Category.Merge))
 
 	// ============================================
 	// Rotas de Membros/Usuários (Equipe do Rateio)
 	// ============================================
 	http.HandleFunc("/users", secureHandler(c.User.Index))
 	http.HandleFunc("/users/create", secureHandler(c.User.Create))
-	http.HandleFunc("/users/delete", secureHandler(c.User.Delete))
+	http.HandleFunc("/users/edit", secureHandler(c.User.Edit))
+	http.HandleFunc("/users/update", secureHandler(c.User.Update))
+	http.HandleFunc("/users/participation", secureHandler(c.User.SetParticipation))
+	http.HandleFunc("/users/participation/delete", secureHandler(c.User.DeleteParticipation))
+	http.HandleFunc("/users/archive", secureHandler(c.User.Archive))
+	http.HandleFunc("/users/restore", secureHandler(c.User.Restore))
 
 	// ============================================
 	// Rotas de Compras de Lanche (Rateio)
 	// ============================================
 	http.HandleFunc("/purchases", secureHandler(c.Purchase.Index))
 	http.HandleFunc("/purchases/create", secureHandler(c.Purchase.Create))
+	http.HandleFunc("/purchases/edit", secureHandler(c.Purchase.Edit))
+	http.HandleFunc("/purchases/update", secureHandler(c.Purchase.Update))
 	http.HandleFunc("/purchases/delete", secureHandler(c.Purchase.Delete))
 	http.HandleFunc("/purchases/process", secureHandler(c.Purchase.ProcessMonth))
+	http.HandleFunc("/purchases/reopen", secureHandler(c.Purchase.ReopenMonth))
+	http.HandleFunc("/purchases/settle", secureHandler(c.Purchase.Settle))
+	http.HandleFunc("/purchases/settle/delete", secureHandler(c.Purchase.DeleteSettlement))
 
 	// ============================================
 	// Rotas de Gamificação (Ranking e Conquistas)
 	// ============================================
 	http.HandleFunc("/ranking", secureHandler(c.Gamification.Ranking))
+	http.HandleFunc("/ranking/history", secureHandler(c.Gamification.PointsHistory))
 	http.HandleFunc("/achievements", secureHandler(c.Gamification.Achievements))
 }
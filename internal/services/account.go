@@ -0,0 +1,204 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"financas/internal/models"
+	"financas/internal/repositories"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrAccountNotFound indica uma conta inexistente
+var ErrAccountNotFound = errors.New("conta não encontrada")
+
+// RecentTransfersLimit é quantas transferências a tela de contas mostra
+const RecentTransfersLimit = 30
+
+type AccountService struct {
+	repository *repositories.AccountRepository
+	goalRepo   *repositories.GoalRepository
+}
+
+func NewAccountService(repository *repositories.AccountRepository, goalRepo *repositories.GoalRepository) *AccountService {
+	return &AccountService{repository: repository, goalRepo: goalRepo}
+}
+
+// AccountStatement é o extrato de uma conta, do movimento mais recente para o mais antigo
+type AccountStatement struct {
+	Account models.Account        `json:"account"`
+	Entries []models.AccountEntry `json:"entries"`
+}
+
+// FindAll retorna as contas com o saldo de hoje
+func (s *AccountService) FindAll(today time.Time) ([]models.Account, error) {
+	return s.repository.FindAll(today)
+}
+
+// FindByID retorna uma conta com o saldo de hoje (ErrAccountNotFound se não existir)
+func (s *AccountService) FindByID(id int, today time.Time) (*models.Account, error) {
+	account, err := s.repository.FindByID(id, today)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	return account, err
+}
+
+// TotalBalance soma o saldo de todas as contas (o cartão de crédito entra negativo)
+func TotalBalance(accounts []models.Account) models.Money {
+	var total models.Money
+	for _, a := range accounts {
+		total += a.Balance
+	}
+	return total
+}
+
+func (s *AccountService) validate(account *models.Account) error {
+	account.Name = strings.TrimSpace(account.Name)
+	if account.Name == "" {
+		return errors.New("o nome não pode ser vazio")
+	}
+	if utf8.RuneCountInString(account.Name) > 50 {
+		return errors.New("o nome deve ter no máximo 50 caracteres")
+	}
+	switch account.Type {
+	case models.AccountChecking, models.AccountSavings, models.AccountCash, models.AccountCreditCard:
+	default:
+		return errors.New("tipo de conta inválido")
+	}
+	if account.IsCreditCard() {
+		if account.ClosingDay < 1 || account.ClosingDay > 31 {
+			return errors.New("o dia de fechamento deve estar entre 1 e 31")
+		}
+		if account.DueDay < 1 || account.DueDay > 31 {
+			return errors.New("o dia de vencimento deve estar entre 1 e 31")
+		}
+		if account.DueDay == account.ClosingDay {
+			return errors.New("o vencimento deve ser em um dia diferente do fechamento")
+		}
+	} else {
+		account.ClosingDay, account.DueDay = 0, 0
+	}
+	exists, err := s.repository.NameExists(account.Name, account.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("já existe uma conta com esse nome")
+	}
+	return nil
+}
+
+func (s *AccountService) Create(account *models.Account) error {
+	if err := s.validate(account); err != nil {
+		return err
+	}
+	return s.repository.Create(account)
+}
+
+// Update altera uma conta; mudar o saldo inicial muda o saldo de todo o extrato
+// Novos dias de fechamento e vencimento valem para as compras que ainda não têm fatura
+func (s *AccountService) Update(account *models.Account) error {
+	if err := s.validate(account); err != nil {
+		return err
+	}
+	current, err := s.FindByID(account.ID, time.Now())
+	if err != nil {
+		return err
+	}
+	if current.IsCreditCard() && !account.IsCreditCard() {
+		invoices, err := s.repository.CountInvoices(account.ID)
+		if err != nil {
+			return err
+		}
+		if invoices > 0 {
+			return errors.New("o cartão tem faturas e não pode mudar de tipo")
+		}
+	}
+	err = s.repository.Update(account)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAccountNotFound
+	}
+	return err
+}
+
+// Delete remove uma conta sem movimentos
+func (s *AccountService) Delete(id int, today time.Time) error {
+	account, err := s.FindByID(id, today)
+	if err != nil {
+		return err
+	}
+	if account.Usage > 0 {
+		return errors.New("a conta tem lançamentos, recorrências ou transferências")
+	}
+	invoices, err := s.repository.CountInvoices(id)
+	if err != nil {
+		return err
+	}
+	if invoices > 0 {
+		return errors.New("o cartão tem faturas")
+	}
+	return s.repository.Delete(id)
+}
+
+// Statement monta o extrato da conta com o saldo corrente depois de cada movimento
+// Movimentos com data futura (ex: parcelas) entram no extrato, mas não no saldo de hoje
+func (s *AccountService) Statement(id int, today time.Time) (*AccountStatement, error) {
+	account, err := s.FindByID(id, today)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repository.FindEntries(id)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := account.OpeningBalance
+	for i := range entries {
+		balance += entries[i].Amount
+		entries[i].Balance = balance
+	}
+	// Mais recentes primeiro
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return &AccountStatement{Account: *account, Entries: entries}, nil
+}
+
+// CreateTransfer registra uma transferência entre duas contas diferentes
+func (s *AccountService) CreateTransfer(transfer *models.Transfer, today time.Time) error {
+	transfer.Description = strings.TrimSpace(transfer.Description)
+	if transfer.Amount <= 0 {
+		return errors.New("o valor deve ser maior que 0")
+	}
+	if transfer.Date.IsZero() {
+		return errors.New("a data não pode ser vazia")
+	}
+	if transfer.FromAccountID == transfer.ToAccountID {
+		return errors.New("escolha contas de origem e destino diferentes")
+	}
+	if _, err := s.FindByID(transfer.FromAccountID, today); err != nil {
+		return errors.New("conta de origem não encontrada")
+	}
+	if _, err := s.FindByID(transfer.ToAccountID, today); err != nil {
+		return errors.New("conta de destino não encontrada")
+	}
+	if err := resolveGoal(s.goalRepo, transfer.GoalID); err != nil {
+		return err
+	}
+	return s.repository.CreateTransfer(transfer)
+}
+
+// FindTransfers retorna as transferências mais recentes
+func (s *AccountService) FindTransfers() ([]models.Transfer, error) {
+	return s.repository.FindTransfers(RecentTransfersLimit)
+}
+
+func (s *AccountService) DeleteTransfer(id int) error {
+	err := s.repository.DeleteTransfer(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("transferência não encontrada")
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"financas/internal/models"
+	"time"
+)
+
+// Faixas de alerta do orçamento (percentual do disponível já gasto)
+const (
+	BudgetWarningPercent = 80
+	BudgetOverPercent    = 100
+)
+
+// BudgetStatus compara o orçamento de uma categoria com o gasto do mês
+type BudgetStatus struct {
+	Category  string       `json:"category"`
+	Limit     models.Money `json:"limit"`     // Limite mensal
+	Carried   models.Money `json:"carried"`   // Sobra acumulada dos meses anteriores (rollover)
+	Available models.Money `json:"available"` // Limite + sobra
+	Spent     models.Money `json:"spent"`
+	Remaining models.Money `json:"remaining"` // Negativo = estourou
+	Percent   float64      `json:"percent"`   // Gasto / disponível
+	Rollover  bool         `json:"rollover"`
+}
+
+// Level retorna a faixa do orçamento: "ok", "warning" (80%) ou "over" (100%)
+func (b BudgetStatus) Level() string {
+	switch {
+	case b.Percent >= BudgetOverPercent:
+		return "over"
+	case b.Percent >= BudgetWarningPercent:
+		return "warning"
+	default:
+		return "ok"
+	}
+}
+
+// BarPercent limita o percentual a 100 para a barra de progresso
+func (b BudgetStatus) BarPercent() float64 {
+	if b.Percent > 100 {
+		return 100
+	}
+	return b.Percent
+}
+
+// SaveBudget define o limite mensal de uma categoria
+// Um orçamento novo começa no mês informado (a sobra só acumula a partir dele)
+func (s *ExpenseService) SaveBudget(budget *models.Budget) error {
+	if budget.Category == "" {
+		return errors.New("a categoria não pode ser vazia")
+	}
+	if err := s.resolveCategory(&budget.Category, models.CategoryExpense); err != nil {
+		return err
+	}
+	if budget.Amount <= 0 {
+		return errors.New("o limite deve ser maior que 0")
+	}
+	if _, err := time.Parse("2006-01", budget.StartMonth); err != nil {
+		return errors.New("mês inválido")
+	}
+	return s.budgetRepo.Save(budget)
+}
+
+func (s *ExpenseService) DeleteBudget(category string) error {
+	return s.budgetRepo.Delete(category)
+}
+
+// GetBudgetStatus calcula o orçamento x realizado de cada categoria no mês
+// Só despesas contam para o orçamento, e o gasto das subcategorias entra no da categoria pai.
+// Com rollover, a sobra de cada mês desde o início do orçamento é somada ao mês seguinte
+// (estouros não são descontados dos meses seguintes)
+func (s *ExpenseService) GetBudgetStatus(month string) ([]BudgetStatus, error) {
+	budgets, err := s.budgetRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// O gasto das subcategorias também conta no orçamento da categoria pai
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[string]string)
+	for _, c := range categories {
+		if c.IsSubcategory() {
+			parents[c.Name] = c.ParentName
+		}
+	}
+
+	// Gasto por categoria de cada mês consultado (o mês atual e o histórico do rollover)
+	spentByMonth := make(map[string]map[string]models.Money)
+	spentIn := func(m string) (map[string]models.Money, error) {
+		if spent, ok := spentByMonth[m]; ok {
+			return spent, nil
+		}
+		metrics, err := s.repository.GetCategoryBreakdownForMonth(m)
+		if err != nil {
+			return nil, err
+		}
+		spent := make(map[string]models.Money)
+		for _, metric := range metrics {
+			if metric.Type == "despesa" {
+				spent[metric.Category] += metric.Total
+				if parent, ok := parents[metric.Category]; ok {
+					spent[parent] += metric.Total
+				}
+			}
+		}
+		spentByMonth[m] = spent
+		return spent, nil
+	}
+
+	current, err := spentIn(month)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []BudgetStatus{}
+	for _, budget := range budgets {
+		var carried models.Money
+		if budget.Rollover {
+			for m := budget.StartMonth; m < month; m = nextMonth(m) {
+				spent, err := spentIn(m)
+				if err != nil {
+					return nil, err
+				}
+				carried += budget.Amount - spent[budget.Category]
+				if carried < 0 {
+					carried = 0
+				}
+			}
+		}
+
+		status := BudgetStatus{
+			Category:  budget.Category,
+			Limit:     budget.Amount,
+			Carried:   carried,
+			Available: budget.Amount + carried,
+			Spent:     current[budget.Category],
+			Rollover:  budget.Rollover,
+		}
+		status.Remaining = status.Available - status.Spent
+		if status.Available > 0 {
+			status.Percent = float64(status.Spent) / float64(status.Available) * 100
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// nextMonth retorna o mês seguinte ("2026-12" -> "2027-01")
+func nextMonth(month string) string {
+	t, err := time.Parse("2006-01", month)
+	if err != nil {
+		return "9999-12"
+	}
+	return t.AddDate(0, 1, 0).Format("2006-01")
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"financas/internal/models"
+	"testing"
+)
+
+func TestBudgetRollover(t *testing.T) {
+	e := newTestEnv(t)
+	account := e.newAccount(t, "Conta", 0, 0)
+	e.exec(t, `INSERT INTO categories (name, parent_id, type) SELECT 'Restaurante', id, 'despesa' FROM categories WHERE name = 'Alimentação'`)
+
+	spend := func(category string, amount models.Money, date string) *models.Expense {
+		expense := &models.Expense{Description: category, Amount: amount, Type: "despesa", Category: category, AccountID: account, Date: day(date)}
+		if err := e.expenses.Create(expense); err != nil {
+			t.Fatal(err)
+		}
+		return expense
+	}
+	// Alimentação: limite de 500 com rollover desde janeiro
+	spend("Alimentação", 15000, "2026-01-05")
+	spend("Restaurante", 5000, "2026-01-20") // subcategoria conta na categoria pai
+	spend("Alimentação", 90000, "2026-02-10")
+	spend("Alimentação", 10000, "2026-03-10")
+	spend("Alimentação", 30000, "2026-04-10")
+	trashed := spend("Alimentação", 99900, "2026-04-11")
+	e.exec(t, `UPDATE expenses SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?`, trashed.ID)
+	// Transporte: limite de 100 sem rollover
+	spend("Transporte", 2000, "2026-03-10")
+	spend("Transporte", 9000, "2026-04-10")
+
+	for _, budget := range []models.Budget{
+		{Category: "alimentação", Amount: 50000, Rollover: true, StartMonth: "2026-01"},
+		{Category: "Transporte", Amount: 10000, StartMonth: "2026-01"},
+	} {
+		if err := e.expenses.SaveBudget(&budget); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		month    string
+		category string
+		carried  models.Money
+		spent    models.Money
+		level    string
+	}{
+		{"2025-12", "Alimentação", 0, 0, "ok"},     // antes do início não acumula
+		{"2026-01", "Alimentação", 0, 20000, "ok"}, // 150 + 50 da subcategoria
+		{"2026-02", "Alimentação", 30000, 90000, "over"},
+		{"2026-03", "Alimentação", 0, 10000, "ok"},     // estouro zera a sobra, sem ficar negativa
+		{"2026-04", "Alimentação", 40000, 30000, "ok"}, // lançamento na lixeira não conta
+		{"2026-04", "Transporte", 0, 9000, "warning"},  // sem rollover a sobra de março não passa
+	}
+	for _, tt := range tests {
+		statuses, err := e.expenses.GetBudgetStatus(tt.month)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found bool
+		for _, status := range statuses {
+			if status.Category != tt.category {
+				continue
+			}
+			found = true
+			if status.Carried != tt.carried || status.Spent != tt.spent || status.Level() != tt.level {
+				t.Errorf("%s %s: sobra %d, gasto %d, faixa %s; quer %d, %d, %s", tt.month, tt.category,
+					status.Carried, status.Spent, status.Level(), tt.carried, tt.spent, tt.level)
+			}
+			if status.Remaining != status.Limit+tt.carried-tt.spent {
+				t.Errorf("%s %s: restante %d", tt.month, tt.category, status.Remaining)
+			}
+		}
+		if !found {
+			t.Errorf("%s: sem orçamento de %s", tt.month, tt.category)
+		}
+	}
+
+	invalid := []models.Budget{
+		{Category: "", Amount: 1000, StartMonth: "2026-01"},
+		{Category: "Alimentação", Amount: 0, StartMonth: "2026-01"},
+		{Category: "Alimentação", Amount: 1000, StartMonth: "janeiro"},
+		{Category: "Não existe", Amount: 1000, StartMonth: "2026-01"},
+	}
+	for _, budget := range invalid {
+		if err := e.expenses.SaveBudget(&budget); err == nil {
+			t.Errorf("orçamento inválido aceito: %+v", budget)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"financas/internal/models"
+	"financas/internal/repositories"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrCategoryNotFound indica uma categoria inexistente
+var ErrCategoryNotFound = errors.New("categoria não encontrada")
+
+var categoryColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type CategoryService struct {
+	repository *repositories.CategoryRepository
+}
+
+func NewCategoryService(repository *repositories.CategoryRepository) *CategoryService {
+	return &CategoryService{repository: repository}
+}
+
+// FindAll retorna as categorias em ordem de exibição: despesas e depois receitas,
+// cada categoria principal seguida das suas subcategorias
+func (s *CategoryService) FindAll() ([]models.Category, error) {
+	categories, err := s.repository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return sortCategories(categories), nil
+}
+
+// FindByID retorna uma categoria (ErrCategoryNotFound se não existir)
+func (s *CategoryService) FindByID(id int) (*models.Category, error) {
+	category, err := s.repository.FindByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	return category, err
+}
+
+// validate confere os campos e a categoria pai
+// Só há um nível de subcategorias, e a subcategoria tem o mesmo tipo da categoria pai
+func (s *CategoryService) validate(category *models.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	category.Icon = strings.TrimSpace(category.Icon)
+	if category.Name == "" {
+		return errors.New("o nome não pode ser vazio")
+	}
+	if utf8.RuneCountInString(category.Name) > 50 {
+		return errors.New("o nome deve ter no máximo 50 caracteres")
+	}
+	if category.Color == "" {
+		category.Color = models.DefaultCategoryColor
+	}
+	if !categoryColorPattern.MatchString(category.Color) {
+		return errors.New("cor inválida (use o formato #rrggbb)")
+	}
+	if utf8.RuneCountInString(category.Icon) > 8 {
+		return errors.New("o ícone deve ter no máximo 8 caracteres")
+	}
+	if category.Type != models.CategoryIncome && category.Type != models.CategoryExpense {
+		return errors.New("tipo inválido")
+	}
+
+	if existing, err := s.repository.FindByName(category.Name); err == nil && existing.ID != category.ID {
+		return fmt.Errorf("já existe a categoria %q", existing.Name)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if category.ParentID <= 0 {
+		category.ParentID = 0
+		return nil
+	}
+	if category.ParentID == category.ID {
+		return errors.New("a categoria não pode ser pai dela mesma")
+	}
+	parent, err := s.FindByID(category.ParentID)
+	if err != nil {
+		return errors.New("categoria pai não encontrada")
+	}
+	if parent.IsSubcategory() {
+		return errors.New("a categoria pai não pode ser uma subcategoria")
+	}
+	if parent.Type != category.Type {
+		return errors.New("a subcategoria deve ter o mesmo tipo da categoria pai")
+	}
+	if category.ID > 0 {
+		children, err := s.repository.CountChildren(category.ID)
+		if err != nil {
+			return err
+		}
+		if children > 0 {
+			return errors.New("uma categoria com subcategorias não pode virar subcategoria")
+		}
+	}
+	return nil
+}
+
+func (s *CategoryService) Create(category *models.Category) error {
+	if err := s.validate(category); err != nil {
+		return err
+	}
+	return s.repository.Create(category)
+}
+
+// Update altera uma categoria; renomear atualiza os lançamentos, recorrências e orçamentos
+func (s *CategoryService) Update(category *models.Category) error {
+	current, err := s.FindByID(category.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(category); err != nil {
+		return err
+	}
+	if current.Type != category.Type {
+		if current.Usage > 0 {
+			return errors.New("não é possível mudar o tipo de uma categoria com lançamentos")
+		}
+		children, err := s.repository.CountChildren(category.ID)
+		if err != nil {
+			return err
+		}
+		if children > 0 {
+			return errors.New("não é possível mudar o tipo de uma categoria com subcategorias")
+		}
+	}
+	return s.repository.Update(category)
+}
+
+// Delete remove uma categoria sem uso; categorias usadas devem ser juntadas a outra
+func (s *CategoryService) Delete(id int) error {
+	category, err := s.FindByID(id)
+	if err != nil {
+		return err
+	}
+	children, err := s.repository.CountChildren(id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return errors.New("remova ou mova as subcategorias antes")
+	}
+	used, err := s.repository.IsInUse(category.Name)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.New("a categoria está em uso; junte-a a outra categoria")
+	}
+	return s.repository.Delete(id)
+}
+
+// Merge junta a categoria de origem à de destino: os lançamentos passam para o destino
+// e a origem é removida
+func (s *CategoryService) Merge(sourceID, targetID int) error {
+	if sourceID == targetID {
+		return errors.New("escolha uma categoria de destino diferente")
+	}
+	source, err := s.FindByID(sourceID)
+	if err != nil {
+		return err
+	}
+	target, err := s.FindByID(targetID)
+	if err != nil {
+		return err
+	}
+	if source.Type != target.Type {
+		return errors.New("as categorias devem ter o mesmo tipo")
+	}
+	children, err := s.repository.CountChildren(sourceID)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return errors.New("remova ou mova as subcategorias antes")
+	}
+	return s.repository.Merge(sourceID, targetID)
+}
+
+// sortCategories ordena despesas antes de receitas, com cada subcategoria logo depois da sua categoria pai
+// Recebe as categorias já em ordem alfabética dentro de cada tipo
+func sortCategories(categories []models.Category) []models.Category {
+	children := make(map[int][]models.Category)
+	for _, c := range categories {
+		if c.IsSubcategory() {
+			children[c.ParentID] = append(children[c.ParentID], c)
+		}
+	}
+
+	sorted := make([]models.Category, 0, len(categories))
+	for _, kind := range []string{models.CategoryExpense, models.CategoryIncome} {
+		for _, c := range categories {
+			if c.Type == kind && !c.IsSubcategory() {
+				sorted = append(sorted, c)
+				sorted = append(sorted, children[c.ID]...)
+			}
+		}
+	}
+	return sorted
+}
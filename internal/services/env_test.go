@@ -0,0 +1,153 @@
+package services
+
+import (
+	"database/sql"
+	"financas/database"
+	"financas/internal/models"
+	"financas/internal/repositories"
+	"testing"
+	"time"
+)
+
+// testEnv é o banco em memória com os serviços montados como em cmd/server
+type testEnv struct {
+	db           *sql.DB
+	expenses     *ExpenseService
+	users        *UserService
+	purchases    *PurchaseService
+	gamification *GamificationService
+	accounts     *AccountService
+	invoices     *InvoiceService
+	forecast     *ForecastService
+	goals        *GoalService
+	imports      *ImportService
+	exports      *ExportService
+}
+
+// newTestEnv cria um banco SQLite em memória com o schema completo
+// Uma única conexão: cada conexão ":memory:" seria um banco diferente
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("migração: %v", err)
+	}
+
+	expenseRepo := repositories.NewExpenseRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+	purchaseRepo := repositories.NewPurchaseRepository(db)
+	achievementRepo := repositories.NewAchievementRepository(db)
+	balanceRepo := repositories.NewMonthlyBalanceRepository(db)
+	runRepo := repositories.NewGamificationRunRepository(db)
+	pointsRepo := repositories.NewPointTransactionRepository(db)
+	settlementRepo := repositories.NewSettlementRepository(db)
+	participationRepo := repositories.NewParticipationRepository(db)
+	recurrenceRepo := repositories.NewRecurrenceRepository(db)
+	budgetRepo := repositories.NewBudgetRepository(db)
+	importProfileRepo := repositories.NewImportProfileRepository(db)
+	categoryRepo := repositories.NewCategoryRepository(db)
+	accountRepo := repositories.NewAccountRepository(db)
+	invoiceRepo := repositories.NewInvoiceRepository(db)
+	installmentRepo := repositories.NewInstallmentRepository(db)
+	goalRepo := repositories.NewGoalRepository(db)
+
+	env := &testEnv{db: db}
+	env.expenses = NewExpenseService(expenseRepo, userRepo, participationRepo, recurrenceRepo, budgetRepo, categoryRepo, accountRepo, installmentRepo, goalRepo)
+	env.users = NewUserService(userRepo, participationRepo)
+	env.purchases = NewPurchaseService(purchaseRepo, userRepo, balanceRepo, settlementRepo, participationRepo)
+	env.gamification = NewGamificationService(userRepo, purchaseRepo, achievementRepo, runRepo, pointsRepo, participationRepo)
+	env.accounts = NewAccountService(accountRepo, goalRepo)
+	env.invoices = NewInvoiceService(invoiceRepo, accountRepo)
+	env.forecast = NewForecastService(expenseRepo, recurrenceRepo, accountRepo, invoiceRepo, installmentRepo)
+	env.goals = NewGoalService(goalRepo, userRepo)
+	env.imports = NewImportService(env.expenses, importProfileRepo)
+	env.exports = NewExportService(env.expenses, env.purchases)
+	return env
+}
+
+// exec roda SQL de preparação do cenário
+func (e *testEnv) exec(t *testing.T, query string, args ...any) {
+	t.Helper()
+	if _, err := e.db.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+// count conta linhas com uma consulta SELECT count(*)
+func (e *testEnv) count(t *testing.T, query string, args ...any) int {
+	t.Helper()
+	var n int
+	if err := e.db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return n
+}
+
+// newUser cadastra um membro ativo desde sempre
+func (e *testEnv) newUser(t *testing.T, name string) int {
+	t.Helper()
+	user := &models.User{Name: name}
+	if err := e.users.Create(user); err != nil {
+		t.Fatalf("criar membro %s: %v", name, err)
+	}
+	return user.ID
+}
+
+// newPurchase registra uma compra dividida entre todos
+func (e *testEnv) newPurchase(t *testing.T, userID int, amount models.Money, date string) *models.Purchase {
+	t.Helper()
+	purchase := &models.Purchase{UserID: userID, Amount: amount, Date: day(date)}
+	if err := e.purchases.Create(purchase); err != nil {
+		t.Fatalf("criar compra: %v", err)
+	}
+	return purchase
+}
+
+// day converte "2006-01-02" em time.Time (UTC)
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// newAccount cadastra uma conta (cartão quando closingDay > 0)
+func (e *testEnv) newAccount(t *testing.T, name string, closingDay, dueDay int) int {
+	t.Helper()
+	account := &models.Account{Name: name, Type: models.AccountChecking}
+	if closingDay > 0 {
+		account.Type = models.AccountCreditCard
+		account.ClosingDay, account.DueDay = closingDay, dueDay
+	}
+	if err := e.accounts.Create(account); err != nil {
+		t.Fatalf("criar conta %s: %v", name, err)
+	}
+	return account.ID
+}
+
+// newExpense lança uma despesa de Alimentação na conta
+func (e *testEnv) newExpense(t *testing.T, accountID int, amount models.Money, date string) *models.Expense {
+	t.Helper()
+	expense := &models.Expense{Description: "Compra " + date, Amount: amount, Type: "despesa", Category: "Alimentação", AccountID: accountID, Date: day(date)}
+	if err := e.expenses.Create(expense); err != nil {
+		t.Fatalf("criar lançamento: %v", err)
+	}
+	return expense
+}
+
+// invoiceOf retorna o vencimento ("2006-01-02") da fatura do lançamento ("" = sem fatura)
+func (e *testEnv) invoiceOf(t *testing.T, expenseID int) string {
+	t.Helper()
+	var due sql.NullString
+	err := e.db.QueryRow(`SELECT substr(i.due_date, 1, 10) FROM expenses e LEFT JOIN invoices i ON i.id = e.invoice_id WHERE e.id = ?`, expenseID).Scan(&due)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return due.String
+}
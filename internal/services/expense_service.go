@@ -1,20 +1,138 @@
 package services
 
 import (
+	"database/sql"
 	"errors"
 	"financas/internal/models"
 	"financas/internal/repositories"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 )
 
 type ExpenseService struct {
-	repository *repositories.ExpenseRepository
+	repository        *repositories.ExpenseRepository
+	userRepo          *repositories.UserRepository
+	participationRepo *repositories.ParticipationRepository
+	recurrenceRepo    *repositories.RecurrenceRepository
+	budgetRepo        *repositories.BudgetRepository
+	categoryRepo      *repositories.CategoryRepository
+	accountRepo       *repositories.AccountRepository
+	installmentRepo   *repositories.InstallmentRepository
+	goalRepo          *repositories.GoalRepository
+
+	// recurrenceMu evita que o agendador e uma requisição gerem as mesmas ocorrências ao mesmo tempo
+	recurrenceMu sync.Mutex
 }
 
-func NewExpenseService(repository *repositories.ExpenseRepository) *ExpenseService {
-	return &ExpenseService{repository: repository}
+func NewExpenseService(
+	repository *repositories.ExpenseRepository,
+	userRepo *repositories.UserRepository,
+	participationRepo *repositories.ParticipationRepository,
+	recurrenceRepo *repositories.RecurrenceRepository,
+	budgetRepo *repositories.BudgetRepository,
+	categoryRepo *repositories.CategoryRepository,
+	accountRepo *repositories.AccountRepository,
+	installmentRepo *repositories.InstallmentRepository,
+	goalRepo *repositories.GoalRepository,
+) *ExpenseService {
+	return &ExpenseService{
+		repository:        repository,
+		userRepo:          userRepo,
+		participationRepo: participationRepo,
+		recurrenceRepo:    recurrenceRepo,
+		budgetRepo:        budgetRepo,
+		categoryRepo:      categoryRepo,
+		accountRepo:       accountRepo,
+		installmentRepo:   installmentRepo,
+		goalRepo:          goalRepo,
+	}
 }
 
-func (s *ExpenseService) Create(expense *models.Expense) error {
+// FindAccounts retorna as contas para os selects, com o saldo de hoje
+func (s *ExpenseService) FindAccounts() ([]models.Account, error) {
+	return s.accountRepo.FindAll(time.Now())
+}
+
+// resolveAccount confere se a conta do lançamento existe
+func (s *ExpenseService) resolveAccount(accountID int) error {
+	if accountID <= 0 {
+		return errors.New("selecione a conta")
+	}
+	if _, err := s.accountRepo.FindByID(accountID, time.Now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAccountNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// FindCategories retorna as categorias para os selects (subcategorias logo depois da categoria pai)
+func (s *ExpenseService) FindCategories() ([]models.Category, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return sortCategories(categories), nil
+}
+
+// resolveCategory confere se a categoria existe e é do tipo do lançamento,
+// e troca o nome informado pela grafia cadastrada
+func (s *ExpenseService) resolveCategory(name *string, kind string) error {
+	category, err := s.categoryRepo.FindByName(strings.TrimSpace(*name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("categoria %q não cadastrada", *name)
+	}
+	if err != nil {
+		return err
+	}
+	if category.Type != kind {
+		return fmt.Errorf("a categoria %q não é de %s", category.Name, kind)
+	}
+	*name = category.Name
+	return nil
+}
+
+// resolvePayer valida o membro pagador e preenche o nome gravado junto da despesa
+// Sem pagador a despesa fica fora do rateio
+func (s *ExpenseService) resolvePayer(expense *models.Expense, current *models.Expense) error {
+	currentPayer := 0
+	if current != nil {
+		currentPayer = current.PayerUserID
+	}
+	name, err := s.payerName(expense.PayerUserID, currentPayer)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		expense.PayerUserID = 0
+	}
+	expense.Payer = name
+	return nil
+}
+
+// payerName valida o membro pagador e retorna seu nome ("" = fora do rateio)
+// Um membro arquivado só é aceito se já era o pagador do registro (currentPayer)
+func (s *ExpenseService) payerName(payerUserID, currentPayer int) (string, error) {
+	if payerUserID <= 0 {
+		return "", nil
+	}
+
+	user, err := s.userRepo.FindByID(payerUserID)
+	if err != nil {
+		return "", errors.New("pagador não encontrado")
+	}
+	// Despesas antigas de membros arquivados continuam editáveis
+	if user.IsArchived() && currentPayer != user.ID {
+		return "", errors.New("membro arquivado não pode ser pagador")
+	}
+	return user.Name, nil
+}
+
+// validateExpense valida os campos obrigatórios de um lançamento
+func validateExpense(expense *models.Expense) error {
 	if expense.Amount <= 0 {
 		return errors.New("o valor deve ser maior que 0")
 	}
@@ -30,53 +148,182 @@ func (s *ExpenseService) Create(expense *models.Expense) error {
 	if expense.Date.IsZero() {
 		return errors.New("a data não pode ser vazia")
 	}
+	return nil
+}
+
+func (s *ExpenseService) Create(expense *models.Expense) error {
+	if err := validateExpense(expense); err != nil {
+		return err
+	}
+	if err := s.resolveCategory(&expense.Category, expense.Type); err != nil {
+		return err
+	}
+	if err := s.resolveAccount(expense.AccountID); err != nil {
+		return err
+	}
+	if err := s.resolvePayer(expense, nil); err != nil {
+		return err
+	}
+	if err := resolveGoal(s.goalRepo, expense.GoalID); err != nil {
+		return err
+	}
 	return s.repository.Create(expense)
 }
 
-func (s *ExpenseService) FindAll() ([]models.Expense, error) {
-	return s.repository.FindAll()
+// ImportExpenses cria vários lançamentos de uma vez (importação de extrato)
+// Todos são validados antes; a gravação é uma única transação, então nada é criado se algum falhar
+func (s *ExpenseService) ImportExpenses(expenses []models.Expense) error {
+	if len(expenses) == 0 {
+		return errors.New("nenhum lançamento selecionado")
+	}
+	for i := range expenses {
+		if err := validateExpense(&expenses[i]); err != nil {
+			return fmt.Errorf("%s: %w", expenses[i].Description, err)
+		}
+		if err := s.resolveCategory(&expenses[i].Category, expenses[i].Type); err != nil {
+			return fmt.Errorf("%s: %w", expenses[i].Description, err)
+		}
+		if err := s.resolveAccount(expenses[i].AccountID); err != nil {
+			return err
+		}
+		if err := s.resolvePayer(&expenses[i], nil); err != nil {
+			return err
+		}
+	}
+	return s.repository.CreateBatch(expenses)
+}
+
+// FindByFITID retorna os lançamentos já importados de uma conta OFX, pelo FITID
+func (s *ExpenseService) FindByFITID(account string) (map[string]models.Expense, error) {
+	return s.repository.FindByFITID(account)
+}
+
+// FindByDateRange retorna os lançamentos entre duas datas (detecção de duplicatas na importação)
+func (s *ExpenseService) FindByDateRange(from, to time.Time) ([]models.Expense, error) {
+	return s.repository.FindByDateRange(from, to)
 }
 
+// ErrInvalidFilter indica um filtro da lista de lançamentos que não pode ser aplicado
+var ErrInvalidFilter = errors.New("filtro inválido")
+
+// ExpensePageSize é o número de lançamentos por página na tela inicial
+const ExpensePageSize = 50
+
+// validateFilter confere o filtro da lista de lançamentos
+func validateFilter(filter *models.ExpenseFilter) error {
+	if filter.Type != "" && filter.Type != "receita" && filter.Type != "despesa" {
+		return fmt.Errorf("%w: tipo %q", ErrInvalidFilter, filter.Type)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return fmt.Errorf("%w: a data final é anterior à inicial", ErrInvalidFilter)
+	}
+	if filter.MinAmount < 0 || filter.MaxAmount < 0 {
+		return fmt.Errorf("%w: valores negativos", ErrInvalidFilter)
+	}
+	if filter.MaxAmount > 0 && filter.MaxAmount < filter.MinAmount {
+		return fmt.Errorf("%w: o valor máximo é menor que o mínimo", ErrInvalidFilter)
+	}
+	switch filter.Sort {
+	case "", models.SortDateDesc, models.SortDateAsc, models.SortAmountDesc, models.SortAmountAsc, models.SortDescription:
+	default:
+		return fmt.Errorf("%w: ordenação %q", ErrInvalidFilter, filter.Sort)
+	}
+	filter.Search = strings.TrimSpace(filter.Search)
+	return nil
+}
+
+// FindAll retorna todos os lançamentos que atendem ao filtro (filtro vazio = todos), sem paginação
+func (s *ExpenseService) FindAll(filter models.ExpenseFilter) ([]models.Expense, error) {
+	if err := validateFilter(&filter); err != nil {
+		return nil, err
+	}
+	return s.repository.FindAll(filter)
+}
+
+// FindPage retorna uma página da lista de lançamentos, a partir do cursor da página anterior
+func (s *ExpenseService) FindPage(filter models.ExpenseFilter, cursor string) (*models.ExpensePage, error) {
+	if err := validateFilter(&filter); err != nil {
+		return nil, err
+	}
+	page, err := s.repository.FindPage(filter, cursor, ExpensePageSize)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	return page, err
+}
+
+// FindByID retorna um lançamento ativo (ErrExpenseNotFound se não existir ou estiver na lixeira)
 func (s *ExpenseService) FindByID(id int) (*models.Expense, error) {
-	return s.repository.FindByID(id)
+	expense, err := s.repository.FindByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrExpenseNotFound
+	}
+	return expense, err
 }
 
 func (s *ExpenseService) Update(expense *models.Expense) error {
-	if expense.Amount <= 0 {
-		return errors.New("o valor deve ser maior que 0")
+	if err := validateExpense(expense); err != nil {
+		return err
 	}
-	if expense.Description == "" {
-		return errors.New("a descrição não pode ser vazia")
+	if err := s.resolveCategory(&expense.Category, expense.Type); err != nil {
+		return err
 	}
-	if expense.Type == "" {
-		return errors.New("o tipo não pode ser vazio")
+	if err := s.resolveAccount(expense.AccountID); err != nil {
+		return err
 	}
-	if expense.Category == "" {
-		return errors.New("a categoria não pode ser vazia")
+	current, err := s.FindByID(expense.ID)
+	if err != nil {
+		return err
 	}
-	if expense.Date.IsZero() {
-		return errors.New("a data não pode ser vazia")
+	if err := s.resolvePayer(expense, current); err != nil {
+		return err
+	}
+	if err := resolveGoal(s.goalRepo, expense.GoalID); err != nil {
+		return err
+	}
+	err = s.repository.Update(expense)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrExpenseNotFound
 	}
-	return s.repository.Update(expense)
+	return err
 }
 
 func (s *ExpenseService) Delete(id int) error {
 	return s.repository.Delete(id)
 }
 
+// Regimes dos relatórios: competência (data da compra) ou caixa (data em que o dinheiro sai,
+// o vencimento da fatura para compras no cartão)
+const (
+	BasisCompetence = "competencia"
+	BasisCash       = "caixa"
+)
+
 // InsightsData agrega todos os dados de relatório
 type InsightsData struct {
-	TotalIncome       float64                       `json:"total_income"`
-	TotalExpense      float64                       `json:"total_expense"`
-	Balance           float64                       `json:"balance"`
+	TotalIncome       models.Money                  `json:"total_income"`
+	TotalExpense      models.Money                  `json:"total_expense"`
+	Balance           models.Money                  `json:"balance"`
 	CategoryStats     []repositories.CategoryMetric `json:"category_stats"`
 	MonthlyStats      []repositories.MonthlyMetric  `json:"monthly_stats"`
+	Basis             string                        `json:"basis"` // Regime da tendência mensal (BasisCompetence ou BasisCash)
 	TypeStats         []repositories.TypeMetric     `json:"type_stats"`
 	TopExpenses       []models.Expense              `json:"top_expenses"`
 	TotalTransactions int                           `json:"total_transactions"`
+	BudgetMonth       string                        `json:"budget_month"`
+	Budgets           []BudgetStatus                `json:"budgets"`        // Orçamento x realizado no mês
+	Accounts          []models.Account              `json:"accounts"`       // Saldo de hoje por conta
+	AccountsTotal     models.Money                  `json:"accounts_total"` // Soma dos saldos das contas
 }
 
-func (s *ExpenseService) GetInsights() (*InsightsData, error) {
+// GetInsights agrega os relatórios gerais e o orçamento x realizado do mês informado,
+// com a tendência mensal no regime informado
+// Transferências entre contas (inclusive o pagamento da fatura) não são receita nem despesa e ficam fora dos totais
+func (s *ExpenseService) GetInsights(budgetMonth, basis string) (*InsightsData, error) {
+	if basis != BasisCompetence && basis != BasisCash {
+		return nil, fmt.Errorf("%w: regime %q", ErrInvalidFilter, basis)
+	}
+
 	income, expense, balance, err := s.repository.GetSummary()
 	if err != nil {
 		return nil, err
@@ -87,7 +334,7 @@ func (s *ExpenseService) GetInsights() (*InsightsData, error) {
 		return nil, err
 	}
 
-	monthly, err := s.repository.GetMonthlyBreakdown()
+	monthly, err := s.repository.GetMonthlyBreakdown(basis == BasisCash)
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +349,16 @@ func (s *ExpenseService) GetInsights() (*InsightsData, error) {
 		return nil, err
 	}
 
+	budgets, err := s.GetBudgetStatus(budgetMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.FindAccounts()
+	if err != nil {
+		return nil, err
+	}
+
 	totalTransactions := 0
 	for _, ts := range typeStats {
 		totalTransactions += ts.Count
@@ -113,65 +370,111 @@ func (s *ExpenseService) GetInsights() (*InsightsData, error) {
 		Balance:           balance,
 		CategoryStats:     cats,
 		MonthlyStats:      monthly,
+		Basis:             basis,
 		TypeStats:         typeStats,
 		TopExpenses:       topExpenses,
 		TotalTransactions: totalTransactions,
+		BudgetMonth:       budgetMonth,
+		Budgets:           budgets,
+		Accounts:          accounts,
+		AccountsTotal:     TotalBalance(accounts),
 	}, nil
 }
 
 // RateioStats armazena dados para a cota de lanche da equipe
 type RateioStats struct {
-	TotalSpent     float64      `json:"total_spent"`
-	SharePerPerson float64      `json:"share_per_person"`
-	MemberStats    []MemberStat `json:"member_stats"`
+	Month           string       `json:"month"`
+	TotalSpent      models.Money `json:"total_spent"`
+	SharePerPerson  models.Money `json:"share_per_person"` // Cota cheia (por unidade de participação)
+	MemberCount     int          `json:"member_count"`     // Membros ativos no mês
+	UnassignedTotal models.Money `json:"unassigned_total"` // Despesas sem pagador (fora do rateio)
+	MemberStats     []MemberStat `json:"member_stats"`
 }
 
 type MemberStat struct {
-	Name    string  `json:"name"`
-	Paid    float64 `json:"paid"`
-	Balance float64 `json:"balance"` // Positivo = Crédito, Negativo = Débito
+	UserID  int          `json:"user_id"`
+	Name    string       `json:"name"`
+	Paid    models.Money `json:"paid"`
+	Weight  float64      `json:"weight"` // Fator de participação no mês (1 = cota cheia)
+	Share   models.Money `json:"share"`
+	Balance models.Money `json:"balance"` // Positivo = Crédito, Negativo = Débito
 }
 
-func (s *ExpenseService) GetRateioStats() (*RateioStats, error) {
-	expenses, err := s.repository.FindAll()
+// GetRateioStats divide as despesas do mês pagas por membros entre os membros ativos no mês
+// O tamanho do grupo vem da tabela de membros (período na equipe, arquivamento e peso do mês),
+// da mesma forma que no rateio das compras de lanche
+func (s *ExpenseService) GetRateioStats(month string) (*RateioStats, error) {
+	users, err := s.userRepo.FindAllWithArchived()
 	if err != nil {
 		return nil, err
 	}
 
-	total := 0.0
-	payerTotals := make(map[string]float64)
+	paid, unassigned, err := s.repository.GetPaidByPayer(month)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, e := range expenses {
-		// Considerar apenas Despesas (Type = despesa) para o rateio?
-		// O usuário disse "cota para o lanche".
-		// Vamos filtrar por Type="despesa" por segurança.
-		if e.Type == "despesa" {
-			total += e.Amount
+	weights, err := s.participationRepo.GetWeightsByMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	factors := participationFactors(users, weights, month)
 
-			name := e.Payer
-			if name == "" {
-				name = "Outros"
-			}
-			payerTotals[name] += e.Amount
+	var total models.Money
+	for _, value := range paid {
+		total += value
+	}
+
+	totalWeight := 0.0
+	memberCount := 0
+	for _, factor := range factors {
+		if factor > 0 {
+			totalWeight += factor
+			memberCount++
 		}
 	}
 
-	// 6 pessoas
-	share := total / 6.0
+	var share models.Money
+	if totalWeight > 0 {
+		share = models.MoneyFromFloat(total.Float() / totalWeight)
+	}
 
-	var stats []MemberStat
-	for name, paid := range payerTotals {
-		balance := paid - share
+	// Divisão em centavos exatos: as cotas somam o total e os saldos somam zero
+	shares := make(map[int]models.Money)
+	userIDs, factorWeights := sortedWeights(factors)
+	for i, part := range total.Allocate(factorWeights) {
+		shares[userIDs[i]] = part
+	}
+
+	stats := []MemberStat{}
+	for _, user := range users {
+		// Quem não participou nem pagou nada no mês fica fora da lista
+		if factors[user.ID] == 0 && paid[user.ID] == 0 {
+			continue
+		}
+
+		memberShare := shares[user.ID]
 		stats = append(stats, MemberStat{
-			Name:    name,
-			Paid:    paid,
-			Balance: balance,
+			UserID:  user.ID,
+			Name:    user.Name,
+			Paid:    paid[user.ID],
+			Weight:  factors[user.ID],
+			Share:   memberShare,
+			Balance: paid[user.ID] - memberShare,
 		})
 	}
 
 	return &RateioStats{
-		TotalSpent:     total,
-		SharePerPerson: share,
-		MemberStats:    stats,
+		Month:           month,
+		TotalSpent:      total,
+		SharePerPerson:  share,
+		MemberCount:     memberCount,
+		UnassignedTotal: unassigned,
+		MemberStats:     stats,
 	}, nil
 }
+
+// GetRateioMonths retorna os meses com despesas para o seletor do rateio
+func (s *ExpenseService) GetRateioMonths() ([]string, error) {
+	return s.repository.GetDespesaMonths()
+}
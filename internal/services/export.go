@@ -0,0 +1,255 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"financas/internal/models"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formatos de exportação
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// ExportTable é uma tabela exportada (uma aba no XLSX, uma seção no CSV)
+// As células podem ser string, int, float64, models.Money ou time.Time; cada formato escreve do seu jeito
+type ExportTable struct {
+	Name    string
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// ExportOptions define o formato do arquivo exportado
+type ExportOptions struct {
+	Format string
+	BR     bool // Formato brasileiro: vírgula decimal, datas dd/mm/aaaa e, no CSV, separador ";"
+}
+
+// Validate confere o formato pedido
+func (o ExportOptions) Validate() error {
+	if o.Format != ExportCSV && o.Format != ExportXLSX {
+		return errors.New("formato de exportação inválido")
+	}
+	return nil
+}
+
+// ContentType retorna o tipo MIME do arquivo
+func (o ExportOptions) ContentType() string {
+	if o.Format == ExportXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Filename monta o nome do arquivo com a extensão do formato
+func (o ExportOptions) Filename(base string) string {
+	return base + "." + o.Format
+}
+
+// ExportService monta as tabelas de lançamentos, compras e rateio para exportação
+type ExportService struct {
+	expenseService  *ExpenseService
+	purchaseService *PurchaseService
+}
+
+func NewExportService(expenseService *ExpenseService, purchaseService *PurchaseService) *ExportService {
+	return &ExportService{
+		expenseService:  expenseService,
+		purchaseService: purchaseService,
+	}
+}
+
+// ExpensesTables exporta os lançamentos que atendem ao filtro da tela inicial
+// O valor sai com sinal (saída negativa) para que a soma da coluna seja o saldo
+func (s *ExportService) ExpensesTables(filter models.ExpenseFilter) ([]ExportTable, error) {
+	expenses, err := s.expenseService.FindAll(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	table := ExportTable{
+		Name:    "Lançamentos",
+		Columns: []string{"ID", "Data", "Descrição", "Tipo", "Categoria", "Valor", "Pagador", "Conta"},
+		Rows:    [][]interface{}{},
+	}
+	for _, e := range expenses {
+		amount := e.Amount
+		if e.Type == "despesa" {
+			amount = -amount
+		}
+		table.Rows = append(table.Rows, []interface{}{e.ID, e.Date, e.Description, e.Type, e.Category, amount, e.Payer, e.AccountName})
+	}
+	return []ExportTable{table}, nil
+}
+
+// PurchasesTables exporta as compras de lanche do mês
+func (s *ExportService) PurchasesTables(month string) ([]ExportTable, error) {
+	purchases, err := s.purchaseService.FindByMonth(month)
+	if err != nil {
+		return nil, err
+	}
+
+	table := ExportTable{
+		Name:    "Compras " + month,
+		Columns: []string{"ID", "Data", "Membro", "Valor", "Participantes"},
+		Rows:    [][]interface{}{},
+	}
+	for _, p := range purchases {
+		table.Rows = append(table.Rows, []interface{}{p.ID, p.Date, p.UserName, p.Amount, participantsLabel(p)})
+	}
+	return []ExportTable{table}, nil
+}
+
+// participantsLabel descreve quem consumiu a compra: "Todos" ou "Ana, Bruno (R$ 5,00)"
+func participantsLabel(p models.Purchase) string {
+	if p.SplitAmongAll() {
+		return "Todos"
+	}
+	names := make([]string, 0, len(p.Participants))
+	for _, pp := range p.Participants {
+		name := pp.UserName
+		if pp.Amount > 0 {
+			name += " (R$ " + pp.Amount.String() + ")"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// RateioTables exporta o rateio do mês: saldos dos membros, acertos pagos e plano de acerto
+func (s *ExportService) RateioTables(month string) ([]ExportTable, error) {
+	data, err := s.purchaseService.CalculateRateio(month)
+	if err != nil {
+		return nil, err
+	}
+
+	members := ExportTable{
+		Name:    "Rateio " + month,
+		Columns: []string{"Membro", "Participação (%)", "Pago", "Cota", "Saldo do mês", "Saldo herdado", "Saldo em aberto"},
+		Rows:    [][]interface{}{},
+	}
+	for _, m := range data.MemberStats {
+		members.Rows = append(members.Rows, []interface{}{m.UserName, m.WeightPercent(), m.Paid, m.Share, m.Balance, m.Opening, m.Outstanding})
+	}
+	members.Rows = append(members.Rows, []interface{}{"Total", "", data.TotalSpent, data.TotalSpent, "", "", ""})
+
+	settlements := ExportTable{
+		Name:    "Acertos pagos",
+		Columns: []string{"Data", "De", "Para", "Valor"},
+		Rows:    [][]interface{}{},
+	}
+	for _, st := range data.Settlements {
+		settlements.Rows = append(settlements.Rows, []interface{}{st.PaidAt, st.FromUserName, st.ToUserName, st.Amount})
+	}
+
+	transfers := ExportTable{
+		Name:    "Acertos pendentes",
+		Columns: []string{"De", "Para", "Valor"},
+		Rows:    [][]interface{}{},
+	}
+	for _, t := range data.Transfers {
+		transfers.Rows = append(transfers.Rows, []interface{}{t.FromUserName, t.ToUserName, t.Amount})
+	}
+
+	return []ExportTable{members, settlements, transfers}, nil
+}
+
+// WriteExport escreve as tabelas no formato pedido
+func WriteExport(w io.Writer, tables []ExportTable, opts ExportOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if opts.Format == ExportXLSX {
+		return writeXLSX(w, tables, opts.BR)
+	}
+	return writeCSV(w, tables, opts.BR)
+}
+
+// writeCSV escreve as tabelas em sequência; com mais de uma, cada seção começa pelo nome e
+// é separada da anterior por uma linha em branco
+// No formato brasileiro o arquivo leva BOM, para o Excel reconhecer o UTF-8 e o separador ";"
+func writeCSV(w io.Writer, tables []ExportTable, br bool) error {
+	if br {
+		if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+			return err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if br {
+		writer.Comma = ';'
+	}
+	for i, table := range tables {
+		if len(tables) > 1 {
+			if i > 0 {
+				if err := writer.Write([]string{}); err != nil {
+					return err
+				}
+			}
+			if err := writer.Write([]string{table.Name}); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(table.Columns); err != nil {
+			return err
+		}
+		for _, row := range table.Rows {
+			record := make([]string, len(row))
+			for j, cell := range row {
+				record[j] = csvCell(cell, br)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvCell formata uma célula como texto
+// Valores saem sem separador de milhar ("1234,56" / "1234.56") para continuarem numéricos na planilha
+func csvCell(cell interface{}, br bool) string {
+	switch v := cell.(type) {
+	case string:
+		return csvText(v)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		s := strconv.FormatFloat(v, 'f', 2, 64)
+		if br {
+			s = strings.Replace(s, ".", ",", 1)
+		}
+		return s
+	case models.Money:
+		if br {
+			return v.Input()
+		}
+		return strings.Replace(v.Input(), ",", ".", 1)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		if br {
+			return v.Format("02/01/2006")
+		}
+		return v.Format("2006-01-02")
+	default:
+		return csvText(fmt.Sprint(v))
+	}
+}
+
+// csvText protege textos vindos de fora (descrições importadas, nomes) que a planilha leria como
+// fórmula: começando com =, +, -, @, tab ou CR, ganham um apóstrofo na frente e ficam como texto
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
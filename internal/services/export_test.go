@@ -0,0 +1,197 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"financas/internal/models"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		name string
+		cell interface{}
+		br   bool
+		want string
+	}{
+		{"texto comum", "Padaria", false, "Padaria"},
+		{"fórmula", "=HYPERLINK(\"http://x\")", false, "'=HYPERLINK(\"http://x\")"},
+		{"soma", "+SUM(A1:A9)", true, "'+SUM(A1:A9)"},
+		{"subtração", "-2+3", false, "'-2+3"},
+		{"arroba", "@cmd", false, "'@cmd"},
+		{"tab", "\tcmd", false, "'\tcmd"},
+		{"retorno de carro", "\rcmd", false, "'\rcmd"},
+		{"sinal no meio não é fórmula", "Café = bom", false, "Café = bom"},
+		{"vazio", "", false, ""},
+		{"valor", models.Money(-123456), false, "-1234.56"},
+		{"valor BR", models.Money(-123456), true, "-1234,56"},
+		{"fator BR", 0.5, true, "0,50"},
+		{"data", day("2026-03-05"), false, "2026-03-05"},
+		{"data BR", day("2026-03-05"), true, "05/03/2026"},
+		{"sem data", time.Time{}, true, ""},
+		{"inteiro", 3, false, "3"},
+	}
+	for _, tt := range tests {
+		if got := csvCell(tt.cell, tt.br); got != tt.want {
+			t.Errorf("%s: csvCell(%v) = %q, quer %q", tt.name, tt.cell, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	tables := []ExportTable{
+		{Name: "Lançamentos", Columns: []string{"Descrição", "Valor"}, Rows: [][]interface{}{
+			{"Pão; leite", models.Money(1050)},
+			{"Loja \"Boa\"\nCentro", models.Money(-2000)},
+			{"=1+1", models.Money(1)},
+		}},
+		{Name: "Resumo", Columns: []string{"Total"}, Rows: [][]interface{}{{models.Money(-949)}}},
+	}
+
+	var br bytes.Buffer
+	if err := WriteExport(&br, tables, ExportOptions{Format: ExportCSV, BR: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\xef\xbb\xbf" +
+		"Lançamentos\n" +
+		"Descrição;Valor\n" +
+		"\"Pão; leite\";10,50\n" +
+		"\"Loja \"\"Boa\"\"\nCentro\";-20,00\n" +
+		"'=1+1;0,01\n" +
+		"\n" +
+		"Resumo\n" +
+		"Total\n" +
+		"-9,49\n"
+	if got := br.String(); got != want {
+		t.Errorf("CSV brasileiro:\n%q\nquer:\n%q", got, want)
+	}
+
+	var plain bytes.Buffer
+	if err := WriteExport(&plain, tables[:1], ExportOptions{Format: ExportCSV}); err != nil {
+		t.Fatal(err)
+	}
+	want = "Descrição,Valor\n" +
+		"Pão; leite,10.50\n" +
+		"\"Loja \"\"Boa\"\"\nCentro\",-20.00\n" +
+		"'=1+1,0.01\n"
+	if got := plain.String(); got != want {
+		t.Errorf("CSV:\n%q\nquer:\n%q", got, want)
+	}
+
+	if err := WriteExport(io.Discard, tables, ExportOptions{Format: "pdf"}); err == nil {
+		t.Error("formato pdf aceito")
+	}
+}
+
+// xlsxCellXML é uma célula lida de volta da aba exportada
+type xlsxCellXML struct {
+	Ref     string `xml:"r,attr"`
+	Type    string `xml:"t,attr"`
+	Style   string `xml:"s,attr"`
+	Value   string `xml:"v"`
+	Text    string `xml:"is>t"`
+	Formula string `xml:"f"`
+}
+
+// readXLSX abre o pacote exportado e retorna os nomes das abas e as células de cada uma
+func readXLSX(t *testing.T, data []byte) ([]string, [][]xlsxCellXML) {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = content
+	}
+	if archive.File[0].Name != "[Content_Types].xml" {
+		t.Errorf("primeiro arquivo do pacote é %s", archive.File[0].Name)
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(files["xl/workbook.xml"], &workbook); err != nil {
+		t.Fatalf("workbook.xml: %v", err)
+	}
+	names := []string{}
+	sheets := [][]xlsxCellXML{}
+	for i, sheet := range workbook.Sheets {
+		names = append(names, sheet.Name)
+		var data struct {
+			Cells []xlsxCellXML `xml:"sheetData>row>c"`
+		}
+		name := "xl/worksheets/sheet" + strconv.Itoa(i+1) + ".xml"
+		if err := xml.Unmarshal(files[name], &data); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		sheets = append(sheets, data.Cells)
+	}
+	return names, sheets
+}
+
+func TestWriteXLSX(t *testing.T) {
+	tables := []ExportTable{
+		{Name: "Rateio: 2026/03", Columns: []string{"Descrição", "Valor", "Data", "Fator"}, Rows: [][]interface{}{
+			{"=HYPERLINK(\"http://x\")", models.Money(-123456), day("2026-03-05"), 0.5},
+			{"<Tom & Jerry>", models.Money(1), time.Time{}, 1},
+		}},
+		{Name: strings.Repeat("Compras ", 5), Columns: []string{"Total"}},
+		{Name: " ", Columns: []string{"Total"}},
+	}
+	var buf bytes.Buffer
+	if err := WriteExport(&buf, tables, ExportOptions{Format: ExportXLSX, BR: true}); err != nil {
+		t.Fatal(err)
+	}
+	names, sheets := readXLSX(t, buf.Bytes())
+
+	wantNames := []string{"Rateio- 2026-03", "Compras Compras Compras Compras", "Planilha 3"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("abas = %q, quer %q", names, wantNames)
+	}
+
+	style := strconv.Itoa
+	cells := make(map[string]xlsxCellXML)
+	for _, cell := range sheets[0] {
+		cells[cell.Ref] = cell
+	}
+	tests := []struct {
+		ref  string
+		want xlsxCellXML
+	}{
+		{"A1", xlsxCellXML{Ref: "A1", Type: "inlineStr", Style: style(xlsxStyleHeader), Text: "Descrição"}},
+		// Texto com "=" fica como texto inline, sem fórmula e sem apóstrofo
+		{"A2", xlsxCellXML{Ref: "A2", Type: "inlineStr", Style: style(xlsxStyleDefault), Text: "=HYPERLINK(\"http://x\")"}},
+		{"B2", xlsxCellXML{Ref: "B2", Style: style(xlsxStyleMoney), Value: "-1234.56"}},
+		{"C2", xlsxCellXML{Ref: "C2", Style: style(xlsxStyleDate), Value: "46086"}},
+		{"D2", xlsxCellXML{Ref: "D2", Style: style(xlsxStyleDecimal), Value: "0.5"}},
+		{"A3", xlsxCellXML{Ref: "A3", Type: "inlineStr", Style: style(xlsxStyleDefault), Text: "<Tom & Jerry>"}},
+		{"B3", xlsxCellXML{Ref: "B3", Style: style(xlsxStyleMoney), Value: "0.01"}},
+		{"D3", xlsxCellXML{Ref: "D3", Value: "1"}},
+	}
+	for _, tt := range tests {
+		if got := cells[tt.ref]; got != tt.want {
+			t.Errorf("%s = %+v, quer %+v", tt.ref, got, tt.want)
+		}
+	}
+	if _, ok := cells["C3"]; ok {
+		t.Error("data vazia gerou célula")
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"financas/internal/models"
+	"financas/internal/repositories"
+	"fmt"
+	"time"
+)
+
+// Horizonte da previsão de caixa, em meses (contando o mês atual)
+const (
+	ForecastMinMonths     = 3
+	ForecastMaxMonths     = 12
+	ForecastDefaultMonths = 6
+)
+
+// forecastAverageMonths é quantos meses fechados entram na média histórica por categoria
+const forecastAverageMonths = 3
+
+type ForecastService struct {
+	expenseRepo     *repositories.ExpenseRepository
+	recurrenceRepo  *repositories.RecurrenceRepository
+	accountRepo     *repositories.AccountRepository
+	invoiceRepo     *repositories.InvoiceRepository
+	installmentRepo *repositories.InstallmentRepository
+}
+
+func NewForecastService(
+	expenseRepo *repositories.ExpenseRepository,
+	recurrenceRepo *repositories.RecurrenceRepository,
+	accountRepo *repositories.AccountRepository,
+	invoiceRepo *repositories.InvoiceRepository,
+	installmentRepo *repositories.InstallmentRepository,
+) *ForecastService {
+	return &ForecastService{
+		expenseRepo:     expenseRepo,
+		recurrenceRepo:  recurrenceRepo,
+		accountRepo:     accountRepo,
+		invoiceRepo:     invoiceRepo,
+		installmentRepo: installmentRepo,
+	}
+}
+
+// signedAmount converte o valor de um lançamento para o efeito no caixa (receita entra, despesa sai)
+func signedAmount(kind string, amount models.Money) models.Money {
+	if kind == "receita" {
+		return amount
+	}
+	return -amount
+}
+
+// Project projeta o saldo em caixa do mês atual e dos seguintes (months no total), somando:
+//   - as próximas ocorrências das recorrências ativas (no cartão, no vencimento da fatura);
+//   - as parcelas a vencer fora do cartão (as do cartão já estão nas faturas);
+//   - as faturas de cartão não pagas, no vencimento (vencidas entram no mês atual);
+//   - a média mensal por categoria dos últimos meses fechados, sem recorrências e parcelas.
+//     No mês atual entra só o que falta para chegar à média
+func (s *ForecastService) Project(today time.Time, months int) (*models.Forecast, error) {
+	if months < ForecastMinMonths || months > ForecastMaxMonths {
+		return nil, fmt.Errorf("%w: previsão de %d meses (use de %d a %d)", ErrInvalidFilter, months, ForecastMinMonths, ForecastMaxMonths)
+	}
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, months, -1)
+
+	forecast := &models.Forecast{Today: today, Months: make([]models.ForecastMonth, months)}
+	index := make(map[string]int, months)
+	for i := range forecast.Months {
+		month := start.AddDate(0, i, 0).Format("2006-01")
+		forecast.Months[i].Month = month
+		index[month] = i
+	}
+	// monthOf retorna o mês da projeção em que a data mexe no caixa
+	// (datas passadas caem no mês atual; depois do horizonte, nil)
+	monthOf := func(date time.Time) *models.ForecastMonth {
+		if date.After(end) {
+			return nil
+		}
+		if date.Before(start) {
+			return &forecast.Months[0]
+		}
+		return &forecast.Months[index[date.Format("2006-01")]]
+	}
+
+	accounts, err := s.accountRepo.FindAll(today)
+	if err != nil {
+		return nil, err
+	}
+	cards := make(map[int]models.Account)
+	for _, a := range accounts {
+		if a.IsCreditCard() {
+			cards[a.ID] = a
+			continue
+		}
+		forecast.Opening += a.Balance
+	}
+
+	recurrences, err := s.recurrenceRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range recurrences {
+		if rec.Paused || rec.IsEnded() || rec.NextDate.IsZero() {
+			continue
+		}
+		for occurrence := rec.NextDate; !occurrence.After(end); occurrence = rec.After(occurrence) {
+			if !rec.EndDate.IsZero() && occurrence.After(rec.EndDate) {
+				break
+			}
+			cash := occurrence
+			if card, ok := cards[rec.AccountID]; ok {
+				_, cash = card.InvoiceDates(occurrence)
+			}
+			if m := monthOf(cash); m != nil {
+				m.Recurring += signedAmount(rec.Type, rec.Amount)
+			}
+		}
+	}
+
+	installments, err := s.installmentRepo.FindUpcoming(today, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range installments {
+		if _, ok := cards[e.AccountID]; ok {
+			continue
+		}
+		monthOf(e.Date).Installments += signedAmount(e.Type, e.Amount)
+	}
+
+	invoices, err := s.invoiceRepo.FindAll(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, inv := range invoices {
+		if inv.Pending() <= 0 {
+			continue
+		}
+		if m := monthOf(inv.DueDate); m != nil {
+			m.Invoices -= inv.Pending()
+		}
+	}
+
+	averages, err := s.categoryAverages(start)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.expenseRepo.GetUnscheduledCategoryTotals(start, today)
+	if err != nil {
+		return nil, err
+	}
+	spent := make(map[string]models.Money)
+	for _, c := range current {
+		spent[c.Type+"|"+c.Category] = c.Total
+	}
+	for i := range forecast.Months {
+		for _, avg := range averages {
+			amount := avg.Total
+			if i == 0 {
+				amount -= spent[avg.Type+"|"+avg.Category]
+				if amount < 0 {
+					amount = 0
+				}
+			}
+			forecast.Months[i].Average += signedAmount(avg.Type, amount)
+		}
+	}
+
+	balance := forecast.Opening
+	for i := range forecast.Months {
+		m := &forecast.Months[i]
+		m.Net = m.Recurring + m.Installments + m.Invoices + m.Average
+		balance += m.Net
+		m.Balance = balance
+	}
+	return forecast, nil
+}
+
+// categoryAverages calcula a média mensal por categoria dos meses fechados antes de start,
+// dividindo pelos meses que tiveram lançamentos (para não subestimar um histórico curto)
+func (s *ForecastService) categoryAverages(start time.Time) ([]repositories.CategoryMetric, error) {
+	from := start.AddDate(0, -forecastAverageMonths, 0)
+	to := start.AddDate(0, 0, -1)
+	months, err := s.expenseRepo.CountMonthsWithEntries(from, to)
+	if err != nil || months == 0 {
+		return nil, err
+	}
+	totals, err := s.expenseRepo.GetUnscheduledCategoryTotals(from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range totals {
+		totals[i].Total /= models.Money(months)
+	}
+	return totals, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"financas/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestForecastProject(t *testing.T) {
+	e := newTestEnv(t)
+	today := day("2026-04-15")
+	checking := &models.Account{Name: "Conta", Type: models.AccountChecking, OpeningBalance: 100000}
+	if err := e.accounts.Create(checking); err != nil {
+		t.Fatal(err)
+	}
+	card := e.newAccount(t, "Cartão", 10, 20)
+
+	spend := func(category string, amount models.Money, accountID int, date string) {
+		expense := &models.Expense{Description: category, Amount: amount, Type: "despesa", Category: category, AccountID: accountID, Date: day(date)}
+		if err := e.expenses.Create(expense); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recurring := func(rec *models.Recurrence) {
+		if err := e.expenses.CreateRecurrence(rec, today); err != nil {
+			t.Fatal(err)
+		}
+	}
+	installments := func(plan *models.InstallmentPlan) {
+		if err := e.expenses.CreateInstallmentPlan(plan); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Histórico de fevereiro e março: média de 300 em Alimentação e 100 em Lazer
+	spend("Alimentação", 30000, checking.ID, "2026-02-10")
+	spend("Alimentação", 30000, checking.ID, "2026-03-10")
+	spend("Lazer", 20000, card, "2026-03-05") // fatura de 20/03, paga em abril
+	// Abril até hoje: 100 dos 300 de Alimentação
+	spend("Alimentação", 10000, checking.ID, "2026-04-10")
+
+	recurring(&models.Recurrence{Description: "Salário", Amount: 500000, Type: "receita", Category: "Salário",
+		AccountID: checking.ID, Frequency: models.FrequencyMonthly, DayOfMonth: 5, StartDate: day("2026-02-05")})
+	// No cartão, cada ocorrência entra no vencimento da fatura: a de 12/05 vence em 20/06
+	recurring(&models.Recurrence{Description: "Streaming", Amount: 5000, Type: "despesa", Category: "Lazer",
+		AccountID: card, Frequency: models.FrequencyMonthly, DayOfMonth: 12, StartDate: day("2026-04-12")})
+	installments(&models.InstallmentPlan{Description: "Curso", Total: 30000, Installments: 3, Category: "Educação",
+		AccountID: checking.ID, FirstDate: day("2026-04-01")})
+	// Parcelas no cartão só entram pelas faturas
+	installments(&models.InstallmentPlan{Description: "Fone", Total: 60000, Installments: 3, Category: "Lazer",
+		AccountID: card, FirstDate: day("2026-04-20")})
+
+	var march int
+	if err := e.db.QueryRow(`SELECT id FROM invoices WHERE substr(due_date, 1, 10) = '2026-03-20'`).Scan(&march); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.invoices.Pay(march, checking.ID, day("2026-04-14"), today); err != nil {
+		t.Fatal(err)
+	}
+
+	forecast, err := e.forecast.Project(today, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1.000 + 3 salários - 700 de Alimentação - 1ª parcela do curso - 200 da fatura paga
+	if forecast.Opening != 1500000 {
+		t.Errorf("saldo de hoje = %d, quer 1500000", forecast.Opening)
+	}
+	want := []models.ForecastMonth{
+		// Abril: só o que falta da média (200 de Alimentação e 100 de Lazer); a fatura vencida já foi paga
+		{Month: "2026-04", Average: -30000, Net: -30000, Balance: 1470000},
+		// Maio: salário, parcela do curso e fatura de 20/05 (streaming de abril + 1ª parcela do fone)
+		{Month: "2026-05", Recurring: 500000, Installments: -10000, Invoices: -25000, Average: -40000, Net: 425000, Balance: 1895000},
+		// Junho: salário menos o streaming de maio e a fatura de 20/06 (2ª parcela do fone)
+		{Month: "2026-06", Recurring: 495000, Installments: -10000, Invoices: -20000, Average: -40000, Net: 425000, Balance: 2320000},
+	}
+	if !reflect.DeepEqual(forecast.Months, want) {
+		t.Errorf("meses:\n%+v\nquer:\n%+v", forecast.Months, want)
+	}
+	if m := forecast.NegativeMonth(); m != nil {
+		t.Errorf("mês negativo %s", m.Month)
+	}
+
+	for _, months := range []int{ForecastMinMonths - 1, ForecastMaxMonths + 1} {
+		if _, err := e.forecast.Project(today, months); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("previsão de %d meses: %v, quer ErrInvalidFilter", months, err)
+		}
+	}
+}
+
+func TestForecastNegativeMonth(t *testing.T) {
+	e := newTestEnv(t)
+	checking := e.newAccount(t, "Conta", 0, 0)
+	rec := &models.Recurrence{Description: "Aluguel", Amount: 150000, Type: "despesa", Category: "Moradia",
+		AccountID: checking, Frequency: models.FrequencyMonthly, DayOfMonth: 5, StartDate: day("2026-05-05")}
+	if err := e.expenses.CreateRecurrence(rec, day("2026-04-15")); err != nil {
+		t.Fatal(err)
+	}
+	// Pausada, a recorrência não entra na previsão
+	if err := e.expenses.PauseRecurrence(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	forecast, err := e.forecast.Project(day("2026-04-15"), ForecastMinMonths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := forecast.NegativeMonth(); m != nil {
+		t.Fatalf("recorrência pausada deixou %s negativo", m.Month)
+	}
+
+	if err := e.expenses.ResumeRecurrence(rec.ID, day("2026-04-15")); err != nil {
+		t.Fatal(err)
+	}
+	forecast, err = e.forecast.Project(day("2026-04-15"), ForecastMinMonths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := forecast.NegativeMonth()
+	if m == nil || m.Month != "2026-05" || m.Balance != -150000 {
+		t.Errorf("primeiro mês negativo = %+v, quer 2026-05 com -150000", m)
+	}
+	if last := forecast.Months[len(forecast.Months)-1]; last.Balance != -300000 {
+		t.Errorf("saldo em junho = %d, quer -300000", last.Balance)
+	}
+}
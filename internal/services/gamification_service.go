@@ -1,31 +1,48 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
 	"financas/internal/models"
 	"financas/internal/repositories"
-	"math"
 	"sort"
 )
 
 // GamificationService gerencia o sistema de pontos e conquistas
 type GamificationService struct {
-	userRepo        *repositories.UserRepository
-	purchaseRepo    *repositories.PurchaseRepository
-	achievementRepo *repositories.AchievementRepository
+	userRepo          *repositories.UserRepository
+	purchaseRepo      *repositories.PurchaseRepository
+	achievementRepo   *repositories.AchievementRepository
+	runRepo           *repositories.GamificationRunRepository
+	pointsRepo        *repositories.PointTransactionRepository
+	participationRepo *repositories.ParticipationRepository
 }
 
 func NewGamificationService(
 	userRepo *repositories.UserRepository,
 	purchaseRepo *repositories.PurchaseRepository,
 	achievementRepo *repositories.AchievementRepository,
+	runRepo *repositories.GamificationRunRepository,
+	pointsRepo *repositories.PointTransactionRepository,
+	participationRepo *repositories.ParticipationRepository,
 ) *GamificationService {
 	return &GamificationService{
-		userRepo:        userRepo,
-		purchaseRepo:    purchaseRepo,
-		achievementRepo: achievementRepo,
+		userRepo:          userRepo,
+		purchaseRepo:      purchaseRepo,
+		achievementRepo:   achievementRepo,
+		runRepo:           runRepo,
+		pointsRepo:        pointsRepo,
+		participationRepo: participationRepo,
 	}
 }
 
+var (
+	// ErrMonthAlreadyProcessed indica que a gamificação do mês já foi processada
+	ErrMonthAlreadyProcessed = errors.New("a gamificação deste mês já foi processada")
+	// ErrMonthNotProcessed indica que não há execução registrada para o mês
+	ErrMonthNotProcessed = errors.New("a gamificação deste mês ainda não foi processada")
+)
+
 // Constantes de pontos
 const (
 	PointsPaidSnack       = 10  // Pagou o lanche do dia
@@ -35,15 +52,41 @@ const (
 	PointsNoParticipation = -15 // Não participou de nenhuma compra no mês
 )
 
-// AwardPointsForPurchase atribui pontos quando alguém paga um lanche
-func (s *GamificationService) AwardPointsForPurchase(userID int) error {
-	return s.userRepo.UpdatePoints(userID, PointsPaidSnack)
+// PointsHistoryData contém o extrato de pontos de um membro
+type PointsHistoryData struct {
+	User         *models.User
+	Transactions []models.PointTransaction
+}
+
+// GetPointsHistory retorna o membro e todos os lançamentos do seu extrato de pontos
+func (s *GamificationService) GetPointsHistory(userID int) (*PointsHistoryData, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.pointsRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PointsHistoryData{
+		User:         user,
+		Transactions: transactions,
+	}, nil
 }
 
 // ProcessMonthlyGamification processa pontos e conquistas do mês
-// Deve ser chamado no fechamento do mês
+// Deve ser chamado no fechamento do mês. Cada mês só pode ser processado uma vez;
+// para reprocessar é preciso reabrir o mês com ReopenMonth
 func (s *GamificationService) ProcessMonthlyGamification(month string) error {
-	users, err := s.userRepo.FindAll()
+	if _, err := s.runRepo.FindByMonth(month); err == nil {
+		return ErrMonthAlreadyProcessed
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	users, err := s.userRepo.FindAllWithArchived()
 	if err != nil {
 		return err
 	}
@@ -64,28 +107,50 @@ func (s *GamificationService) ProcessMonthlyGamification(month string) error {
 		return err
 	}
 
-	// Calcular total e média
-	totalSpent, _ := s.purchaseRepo.GetMonthlyTotal(month)
-	share := totalSpent / float64(len(users))
+	// Participação de cada membro no mês (período na equipe e peso do mês)
+	weights, err := s.participationRepo.GetWeightsByMonth(month)
+	if err != nil {
+		return err
+	}
+	factors := participationFactors(users, weights, month)
+
+	// Cota de cada membro, compra a compra (mesma regra do rateio)
+	purchases, err := s.purchaseRepo.FindByMonth(month)
+	if err != nil {
+		return err
+	}
+	shares, _, err := memberShares(purchases, factors)
+	if err != nil {
+		return err
+	}
 
 	// Calcular balanços
 	type userBalance struct {
 		UserID  int
-		Paid    float64
+		Paid    models.Money
 		Count   int
-		Balance float64
+		Share   models.Money
+		Balance models.Money
 	}
 
 	var balances []userBalance
 	for _, user := range users {
 		paid := totals[user.ID]
 		count := counts[user.ID]
-		balance := paid - share
+
+		memberShare := shares[user.ID]
+
+		// Membros ausentes no mês (que não pagaram nem consumiram nada) ficam fora da gamificação
+		if factors[user.ID] == 0 && paid == 0 && memberShare == 0 {
+			continue
+		}
+
 		balances = append(balances, userBalance{
 			UserID:  user.ID,
 			Paid:    paid,
 			Count:   count,
-			Balance: balance,
+			Share:   memberShare,
+			Balance: paid - memberShare,
 		})
 	}
 
@@ -94,33 +159,41 @@ func (s *GamificationService) ProcessMonthlyGamification(month string) error {
 		return balances[i].Balance > balances[j].Balance
 	})
 
-	// Processar pontos e conquistas para cada usuário
+	// Calcular os pontos do mês (aplicados de uma vez ao salvar a execução)
+	run := &models.GamificationRun{Month: month}
+	addDelta := func(userID, points int, reason string) {
+		run.Deltas = append(run.Deltas, models.PointDelta{UserID: userID, Points: points, Reason: reason})
+	}
+	award := func(userID int, name string) {
+		run.Achievements = append(run.Achievements, models.RunAchievement{UserID: userID, Name: name})
+	}
+
 	for i, b := range balances {
-		// Quem pagou acima da média ganha pontos extras
-		if b.Paid > share && b.Paid > 0 {
-			s.userRepo.UpdatePoints(b.UserID, PointsAboveAverage)
+		// Quem pagou acima da própria cota ganha pontos extras
+		if b.Paid > b.Share && b.Paid > 0 {
+			addDelta(b.UserID, PointsAboveAverage, models.ReasonAboveAverage)
 		}
 
 		// Quem não participou perde pontos
 		if b.Count == 0 {
-			s.userRepo.UpdatePoints(b.UserID, PointsNoParticipation)
+			addDelta(b.UserID, PointsNoParticipation, models.ReasonNoParticipation)
 		}
 
 		// Maior crédito do mês (primeiro da lista ordenada)
 		if i == 0 && b.Balance > 0 {
-			s.userRepo.UpdatePoints(b.UserID, PointsTopCreditor)
-			s.awardAchievement(b.UserID, "Mecenas", month)
+			addDelta(b.UserID, PointsTopCreditor, models.ReasonTopCreditor)
+			award(b.UserID, "Mecenas")
 		}
 
 		// Maior débito do mês (último da lista com balanço negativo)
 		if i == len(balances)-1 && b.Balance < 0 {
-			s.userRepo.UpdatePoints(b.UserID, PointsTopDebtor)
-			s.awardAchievement(b.UserID, "Caloteiro Simpático", month)
+			addDelta(b.UserID, PointsTopDebtor, models.ReasonTopDebtor)
+			award(b.UserID, "Caloteiro Simpático")
 		}
 
-		// Saldo equilibrado (próximo de zero, margem de 5%)
-		if share > 0 && math.Abs(b.Balance) <= share*0.05 {
-			s.awardAchievement(b.UserID, "Equilibrado", month)
+		// Saldo equilibrado (próximo de zero, margem de 5% da cota)
+		if b.Share > 0 && b.Balance.Abs()*20 <= b.Share {
+			award(b.UserID, "Equilibrado")
 		}
 	}
 
@@ -129,7 +202,7 @@ func (s *GamificationService) ProcessMonthlyGamification(month string) error {
 		return balances[i].Count > balances[j].Count
 	})
 	if len(balances) > 0 && balances[0].Count > 0 {
-		s.awardAchievement(balances[0].UserID, "Contador", month)
+		award(balances[0].UserID, "Contador")
 	}
 
 	// Maior gasto individual (ordenar por valor pago)
@@ -137,19 +210,32 @@ func (s *GamificationService) ProcessMonthlyGamification(month string) error {
 		return balances[i].Paid > balances[j].Paid
 	})
 	if len(balances) > 0 && balances[0].Paid > 0 {
-		s.awardAchievement(balances[0].UserID, "Mão Aberta", month)
+		award(balances[0].UserID, "Mão Aberta")
 	}
 
-	return nil
+	// Registrar a execução, aplicar os pontos e atribuir as conquistas (transação única)
+	return s.runRepo.Save(run)
 }
 
-// awardAchievement atribui uma conquista a um usuário
-func (s *GamificationService) awardAchievement(userID int, achievementName, month string) error {
-	achievement, err := s.achievementRepo.GetByName(achievementName)
-	if err != nil {
+// ReopenMonth reverte exatamente os pontos aplicados no processamento do mês
+// e remove as conquistas daquele mês, permitindo processá-lo novamente
+func (s *GamificationService) ReopenMonth(month string) error {
+	if _, err := s.runRepo.FindByMonth(month); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMonthNotProcessed
+		}
 		return err
 	}
-	return s.achievementRepo.AwardToUser(userID, achievement.ID, month)
+	return s.runRepo.Revert(month)
+}
+
+// GetMonthRun retorna a execução registrada de um mês (nil se ainda não processado)
+func (s *GamificationService) GetMonthRun(month string) (*models.GamificationRun, error) {
+	run, err := s.runRepo.FindByMonth(month)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return run, err
 }
 
 // GetRanking retorna o ranking geral de pontos
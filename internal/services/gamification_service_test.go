@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"financas/internal/models"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// gamificationMonth monta fevereiro com três membros:
+// Ana paga 60 e 30, Caio paga 30, Bia não paga nada (cota de 40 para cada um)
+func gamificationMonth(t *testing.T, e *testEnv) (ana, bia, caio int) {
+	t.Helper()
+	ana = e.newUser(t, "Ana")
+	bia = e.newUser(t, "Bia")
+	caio = e.newUser(t, "Caio")
+	e.newPurchase(t, ana, 6000, "2026-02-03")
+	e.newPurchase(t, ana, 3000, "2026-02-10")
+	e.newPurchase(t, caio, 3000, "2026-02-17")
+	return ana, bia, caio
+}
+
+// closeAndProcess faz o que o fechamento do mês faz: congela o rateio e processa a gamificação
+func closeAndProcess(t *testing.T, e *testEnv, month string) {
+	t.Helper()
+	if err := e.purchases.CloseMonth(month, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.gamification.ProcessMonthlyGamification(month); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (e *testEnv) points(t *testing.T, userID int) int {
+	t.Helper()
+	return e.count(t, `SELECT COALESCE(SUM(delta), 0) FROM point_transactions WHERE user_id = ?`, userID)
+}
+
+// achievementsOf lista as conquistas do membro no mês, em ordem alfabética
+func (e *testEnv) achievementsOf(t *testing.T, userID int, month string) []string {
+	t.Helper()
+	rows, err := e.db.Query(`SELECT a.name FROM user_achievements ua JOIN achievements a ON a.id = ua.achievement_id
+		WHERE ua.user_id = ? AND ua.month = ?`, userID, month)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestProcessMonthlyGamification(t *testing.T) {
+	e := newTestEnv(t)
+	ana, bia, caio := gamificationMonth(t, e)
+	closeAndProcess(t, e, "2026-02")
+
+	tests := []struct {
+		name         string
+		userID       int
+		points       int
+		achievements []string
+	}{
+		// 2 compras (+20), acima da cota (+5), maior crédito (+20)
+		{"Ana", ana, 45, []string{"Contador", "Mecenas", "Mão Aberta"}},
+		// não participou (-15), maior débito (-10)
+		{"Bia", bia, -25, []string{"Caloteiro Simpático"}},
+		// 1 compra (+10), abaixo da cota
+		{"Caio", caio, 10, []string{}},
+	}
+	for _, tt := range tests {
+		if got := e.points(t, tt.userID); got != tt.points {
+			t.Errorf("%s: %d pontos, quer %d", tt.name, got, tt.points)
+		}
+		if got := e.achievementsOf(t, tt.userID, "2026-02"); !reflect.DeepEqual(got, tt.achievements) {
+			t.Errorf("%s: conquistas %v, quer %v", tt.name, got, tt.achievements)
+		}
+	}
+
+	// Mês fechado: compras não mudam e o processamento não se repete
+	purchase := &models.Purchase{UserID: bia, Amount: 1000, Date: day("2026-02-20")}
+	if err := e.purchases.Create(purchase); !errors.Is(err, ErrMonthClosed) {
+		t.Errorf("compra em mês fechado: %v, quer ErrMonthClosed", err)
+	}
+	if err := e.gamification.ProcessMonthlyGamification("2026-02"); !errors.Is(err, ErrMonthAlreadyProcessed) {
+		t.Errorf("segundo processamento: %v, quer ErrMonthAlreadyProcessed", err)
+	}
+	if got := e.points(t, ana); got != 45 {
+		t.Errorf("Ana: %d pontos depois do segundo processamento, quer 45", got)
+	}
+}
+
+func TestReopenMonthRevertsGamification(t *testing.T) {
+	e := newTestEnv(t)
+	ana, bia, caio := gamificationMonth(t, e)
+	closeAndProcess(t, e, "2026-02")
+
+	// Conquista de meta no mesmo mês: não vem do processamento e fica
+	e.exec(t, `INSERT INTO user_achievements (user_id, achievement_id, month)
+		SELECT ?, id, '2026-02' FROM achievements WHERE name = ?`, bia, models.GoalAchievement)
+
+	// Reabrir como o controller: primeiro os pontos, depois o fechamento
+	if err := e.gamification.ReopenMonth("2026-02"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.purchases.ReopenMonth("2026-02"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ficam só os pontos das compras, lançados quando elas foram registradas
+	for userID, want := range map[int]int{ana: 20, bia: 0, caio: 10} {
+		if got := e.points(t, userID); got != want {
+			t.Errorf("membro %d: %d pontos depois de reabrir, quer %d", userID, got, want)
+		}
+	}
+	if got := e.achievementsOf(t, ana, "2026-02"); len(got) != 0 {
+		t.Errorf("Ana ainda tem %v", got)
+	}
+	if got := e.achievementsOf(t, bia, "2026-02"); !reflect.DeepEqual(got, []string{models.GoalAchievement}) {
+		t.Errorf("Bia: conquistas %v, quer só a da meta", got)
+	}
+	if err := e.gamification.ReopenMonth("2026-02"); !errors.Is(err, ErrMonthNotProcessed) {
+		t.Errorf("reabrir de novo: %v, quer ErrMonthNotProcessed", err)
+	}
+
+	// Mês aberto aceita compras; o novo processamento considera o mês como ficou
+	e.newPurchase(t, bia, 12000, "2026-02-20")
+	closeAndProcess(t, e, "2026-02")
+	if got := e.achievementsOf(t, bia, "2026-02"); !reflect.DeepEqual(got, []string{"Mecenas", models.GoalAchievement, "Mão Aberta"}) {
+		t.Errorf("Bia depois de reprocessar: %v", got)
+	}
+}
+
+func TestProcessFailureWritesNothing(t *testing.T) {
+	e := newTestEnv(t)
+	ana, _, _ := gamificationMonth(t, e)
+	if err := e.purchases.CloseMonth("2026-02", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Conquista que não existe mais: a execução inteira é desfeita
+	e.exec(t, `UPDATE achievements SET name = 'Mecenas (antiga)' WHERE name = 'Mecenas'`)
+	if err := e.gamification.ProcessMonthlyGamification("2026-02"); err == nil {
+		t.Fatal("processamento sem a conquista Mecenas não falhou")
+	}
+	if n := e.count(t, `SELECT count(*) FROM gamification_runs`); n != 0 {
+		t.Errorf("%d execuções gravadas", n)
+	}
+	if n := e.count(t, `SELECT count(*) FROM user_achievements`); n != 0 {
+		t.Errorf("%d conquistas gravadas", n)
+	}
+	if got := e.points(t, ana); got != 20 {
+		t.Errorf("Ana: %d pontos, quer só os 20 das compras", got)
+	}
+
+	// O controller reabre o mês; depois de corrigir, o mês é processado normalmente
+	if err := e.purchases.ReopenMonth("2026-02"); err != nil {
+		t.Fatal(err)
+	}
+	e.exec(t, `UPDATE achievements SET name = 'Mecenas' WHERE name = 'Mecenas (antiga)'`)
+	closeAndProcess(t, e, "2026-02")
+	if got := e.points(t, ana); got != 45 {
+		t.Errorf("Ana: %d pontos depois de processar, quer 45", got)
+	}
+}
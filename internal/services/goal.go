@@ -0,0 +1,150 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"financas/internal/models"
+	"financas/internal/repositories"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrGoalNotFound indica uma meta de economia inexistente
+var ErrGoalNotFound = errors.New("meta não encontrada")
+
+type GoalService struct {
+	repository *repositories.GoalRepository
+	userRepo   *repositories.UserRepository
+}
+
+func NewGoalService(repository *repositories.GoalRepository, userRepo *repositories.UserRepository) *GoalService {
+	return &GoalService{repository: repository, userRepo: userRepo}
+}
+
+// GoalDetail é uma meta com os aportes dela
+type GoalDetail struct {
+	Goal          models.Goal               `json:"goal"`
+	Contributions []models.GoalContribution `json:"contributions"`
+}
+
+// resolveGoal confere se a meta de um aporte existe (0 = lançamento sem meta)
+func resolveGoal(goalRepo *repositories.GoalRepository, goalID int) error {
+	if goalID <= 0 {
+		return nil
+	}
+	if _, err := goalRepo.FindByID(goalID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGoalNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *GoalService) validate(goal *models.Goal, currentOwner int) error {
+	goal.Name = strings.TrimSpace(goal.Name)
+	if goal.Name == "" {
+		return errors.New("o nome não pode ser vazio")
+	}
+	if utf8.RuneCountInString(goal.Name) > 50 {
+		return errors.New("o nome deve ter no máximo 50 caracteres")
+	}
+	if goal.Target <= 0 {
+		return errors.New("o valor da meta deve ser maior que 0")
+	}
+	if goal.Deadline.IsZero() {
+		return errors.New("o prazo não pode ser vazio")
+	}
+	if goal.OwnerUserID > 0 {
+		owner, err := s.userRepo.FindByID(goal.OwnerUserID)
+		if err != nil {
+			return errors.New("responsável não encontrado")
+		}
+		if owner.IsArchived() && owner.ID != currentOwner {
+			return errors.New("membro arquivado não pode ser responsável")
+		}
+	}
+	exists, err := s.repository.NameExists(goal.Name, goal.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("já existe uma meta com esse nome")
+	}
+	return nil
+}
+
+// Create cria uma meta com prazo a partir de hoje
+func (s *GoalService) Create(goal *models.Goal, today time.Time) error {
+	if err := s.validate(goal, 0); err != nil {
+		return err
+	}
+	if goal.Deadline.Before(time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)) {
+		return errors.New("o prazo não pode estar no passado")
+	}
+	return s.repository.Create(goal)
+}
+
+// Update altera uma meta; uma meta concluída cujo valor passa do que foi juntado volta a ficar em andamento
+// e uma em andamento cujo valor baixa até o que foi juntado é concluída
+func (s *GoalService) Update(goal *models.Goal) error {
+	current, err := s.repository.FindByID(goal.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrGoalNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.validate(goal, current.OwnerUserID); err != nil {
+		return err
+	}
+	reopen := current.IsCompleted() && goal.Target > current.Saved
+	if err := s.repository.Update(goal, reopen); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGoalNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete remove uma meta; os lançamentos e transferências de aporte continuam, sem o vínculo
+func (s *GoalService) Delete(id int) error {
+	if err := s.repository.Delete(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrGoalNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// FindAll retorna as metas com o progresso e o aporte mensal necessário calculados para hoje
+func (s *GoalService) FindAll(today time.Time) ([]models.Goal, error) {
+	goals, err := s.repository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range goals {
+		goals[i].Plan(today)
+	}
+	return goals, nil
+}
+
+// Detail retorna a meta com os aportes, do mais recente para o mais antigo
+func (s *GoalService) Detail(id int, today time.Time) (*GoalDetail, error) {
+	goal, err := s.repository.FindByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGoalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	goal.Plan(today)
+	contributions, err := s.repository.FindContributions(id)
+	if err != nil {
+		return nil, err
+	}
+	return &GoalDetail{Goal: *goal, Contributions: contributions}, nil
+}
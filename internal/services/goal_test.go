@@ -0,0 +1,136 @@
+package services
+
+import (
+	"financas/internal/models"
+	"testing"
+	"time"
+)
+
+// newGoal cria uma meta com prazo daqui a um ano
+func (e *testEnv) newGoal(t *testing.T, name string, target models.Money, ownerID int) *models.Goal {
+	t.Helper()
+	goal := &models.Goal{Name: name, Target: target, Deadline: time.Now().AddDate(1, 0, 0), OwnerUserID: ownerID}
+	if err := e.goals.Create(goal, time.Now()); err != nil {
+		t.Fatalf("criar meta %s: %v", name, err)
+	}
+	return goal
+}
+
+// goalState retorna se a meta está concluída e quantas conquistas de meta o membro tem
+func (e *testEnv) goalState(t *testing.T, goalID, ownerID int) (bool, int) {
+	t.Helper()
+	completed := e.count(t, `SELECT count(*) FROM goals WHERE id = ? AND completed_at IS NOT NULL`, goalID) == 1
+	awards := e.count(t, `SELECT count(*) FROM user_achievements ua JOIN achievements a ON a.id = ua.achievement_id
+		WHERE ua.user_id = ? AND a.name = ?`, ownerID, models.GoalAchievement)
+	return completed, awards
+}
+
+func TestGoalCompletesOnContribution(t *testing.T) {
+	tests := []struct {
+		name       string
+		contribute func(t *testing.T, e *testEnv, goalID, from, to int)
+	}{
+		{"lançamento", func(t *testing.T, e *testEnv, goalID, from, to int) {
+			expense := &models.Expense{Description: "Aporte", Amount: 10000, Type: "despesa", Category: "Alimentação",
+				AccountID: from, Date: day("2026-03-01"), GoalID: goalID}
+			if err := e.expenses.Create(expense); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"lançamento editado para a meta", func(t *testing.T, e *testEnv, goalID, from, to int) {
+			expense := e.newExpense(t, from, 10000, "2026-03-01")
+			expense.GoalID = goalID
+			if err := e.expenses.Update(expense); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"lançamento restaurado da lixeira", func(t *testing.T, e *testEnv, goalID, from, to int) {
+			expense := e.newExpense(t, from, 10000, "2026-03-01")
+			e.exec(t, `UPDATE expenses SET goal_id = ?, deleted_at = CURRENT_TIMESTAMP WHERE id = ?`, goalID, expense.ID)
+			if err := e.expenses.Restore(expense.ID); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"transferência", func(t *testing.T, e *testEnv, goalID, from, to int) {
+			transfer := &models.Transfer{FromAccountID: from, ToAccountID: to, Amount: 10000, Date: day("2026-03-01"), GoalID: goalID}
+			if err := e.accounts.CreateTransfer(transfer, day("2026-03-01")); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"valor da meta reduzido", func(t *testing.T, e *testEnv, goalID, from, to int) {
+			transfer := &models.Transfer{FromAccountID: from, ToAccountID: to, Amount: 8000, Date: day("2026-03-01"), GoalID: goalID}
+			if err := e.accounts.CreateTransfer(transfer, day("2026-03-01")); err != nil {
+				t.Fatal(err)
+			}
+			goal, err := e.goals.Detail(goalID, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			goal.Goal.Target = 8000
+			if err := e.goals.Update(&goal.Goal); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEnv(t)
+			owner := e.newUser(t, "Ana")
+			checking := e.newAccount(t, "Conta", 0, 0)
+			savings := e.newAccount(t, "Poupança", 0, 0)
+			goal := e.newGoal(t, "Viagem", 10000, owner)
+
+			tt.contribute(t, e, goal.ID, checking, savings)
+			completed, awards := e.goalState(t, goal.ID, owner)
+			if !completed || awards != 1 {
+				t.Errorf("concluída = %v, conquistas = %d; quer true e 1", completed, awards)
+			}
+		})
+	}
+}
+
+func TestGoalPartialContributionAndReads(t *testing.T) {
+	e := newTestEnv(t)
+	owner := e.newUser(t, "Ana")
+	checking := e.newAccount(t, "Conta", 0, 0)
+	savings := e.newAccount(t, "Poupança", 0, 0)
+	goal := e.newGoal(t, "Viagem", 10000, owner)
+
+	transfer := &models.Transfer{FromAccountID: checking, ToAccountID: savings, Amount: 4000, Date: day("2026-03-01"), GoalID: goal.ID}
+	if err := e.accounts.CreateTransfer(transfer, day("2026-03-01")); err != nil {
+		t.Fatal(err)
+	}
+	if completed, awards := e.goalState(t, goal.ID, owner); completed || awards != 0 {
+		t.Fatalf("aporte parcial: concluída = %v, conquistas = %d", completed, awards)
+	}
+
+	// Ler as metas não grava nada, mesmo com a meta cheia por fora dos caminhos de aporte
+	e.exec(t, `UPDATE transfers SET amount = 10000 WHERE id = ?`, transfer.ID)
+	if _, err := e.goals.FindAll(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.goals.Detail(goal.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if completed, awards := e.goalState(t, goal.ID, owner); completed || awards != 0 {
+		t.Errorf("leitura concluiu a meta: concluída = %v, conquistas = %d", completed, awards)
+	}
+}
+
+func TestGoalWithoutOwnerCompletes(t *testing.T) {
+	e := newTestEnv(t)
+	checking := e.newAccount(t, "Conta", 0, 0)
+	savings := e.newAccount(t, "Poupança", 0, 0)
+	goal := e.newGoal(t, "Reserva", 5000, 0)
+
+	transfer := &models.Transfer{FromAccountID: checking, ToAccountID: savings, Amount: 5000, Date: day("2026-03-01"), GoalID: goal.ID}
+	if err := e.accounts.CreateTransfer(transfer, day("2026-03-01")); err != nil {
+		t.Fatal(err)
+	}
+	if completed, _ := e.goalState(t, goal.ID, 0); !completed {
+		t.Error("meta sem responsável não foi concluída")
+	}
+	if n := e.count(t, `SELECT count(*) FROM user_achievements`); n != 0 {
+		t.Errorf("%d conquistas dadas sem responsável", n)
+	}
+}
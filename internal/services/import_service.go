@@ -0,0 +1,428 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"financas/internal/models"
+	"financas/internal/repositories"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportService lê extratos bancários e cria os lançamentos pelo ExpenseService
+type ImportService struct {
+	expenseService *ExpenseService
+	profileRepo    *repositories.ImportProfileRepository
+}
+
+func NewImportService(expenseService *ExpenseService, profileRepo *repositories.ImportProfileRepository) *ImportService {
+	return &ImportService{
+		expenseService: expenseService,
+		profileRepo:    profileRepo,
+	}
+}
+
+// ImportRow é uma linha do extrato já interpretada, mostrada na prévia
+type ImportRow struct {
+	Line        int          `json:"line"` // Linha no arquivo (1 = primeira)
+	Date        time.Time    `json:"date"`
+	Description string       `json:"description"`
+	Amount      models.Money `json:"amount"`
+	Type        string       `json:"type"`      // "receita" ou "despesa", pelo sinal/coluna do valor
+	Duplicate   bool         `json:"duplicate"` // Já existe lançamento igual
+	Error       string       `json:"error"`     // Linha que não pôde ser interpretada
+}
+
+// Valid indica se a linha pode ser importada
+func (r ImportRow) Valid() bool {
+	return r.Error == ""
+}
+
+// validateProfile confere se o mapeamento tem as colunas necessárias
+func validateProfile(p *models.ImportProfile) error {
+	if p.DateColumn == "" || p.DescriptionColumn == "" {
+		return errors.New("informe as colunas de data e descrição")
+	}
+	if p.DateFormat == "" {
+		return errors.New("informe o formato da data")
+	}
+	switch p.AmountMode {
+	case models.AmountSigned:
+		if p.AmountColumn == "" {
+			return errors.New("informe a coluna de valor")
+		}
+	case models.AmountSplit:
+		if p.DebitColumn == "" && p.CreditColumn == "" {
+			return errors.New("informe as colunas de débito e crédito")
+		}
+	default:
+		return errors.New("modo de valor inválido")
+	}
+	return nil
+}
+
+// ParseCSV interpreta o extrato com o mapeamento de colunas informado
+// Linhas com data ou valor inválidos voltam com Error preenchido em vez de interromper a leitura
+func (s *ImportService) ParseCSV(data []byte, profile models.ImportProfile) ([]ImportRow, error) {
+	if err := validateProfile(&profile); err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM do Excel
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = csvDelimiter(data, profile.Delimiter)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("arquivo CSV inválido: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("arquivo vazio")
+	}
+
+	var header []string
+	first := 0
+	if profile.HasHeader {
+		header = records[0]
+		first = 1
+	}
+
+	dateCol, err := resolveColumn(profile.DateColumn, header)
+	if err != nil {
+		return nil, err
+	}
+	descCol, err := resolveColumn(profile.DescriptionColumn, header)
+	if err != nil {
+		return nil, err
+	}
+	amountCol, debitCol, creditCol := -1, -1, -1
+	if profile.AmountMode == models.AmountSigned {
+		if amountCol, err = resolveColumn(profile.AmountColumn, header); err != nil {
+			return nil, err
+		}
+	} else {
+		if profile.DebitColumn != "" {
+			if debitCol, err = resolveColumn(profile.DebitColumn, header); err != nil {
+				return nil, err
+			}
+		}
+		if profile.CreditColumn != "" {
+			if creditCol, err = resolveColumn(profile.CreditColumn, header); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	rows := []ImportRow{}
+	for i := first; i < len(records); i++ {
+		record := records[i]
+		if isBlankRecord(record) {
+			continue
+		}
+		row := ImportRow{Line: i + 1, Description: strings.TrimSpace(field(record, descCol))}
+
+		date, err := parseStatementDate(field(record, dateCol), profile.DateFormat)
+		if err != nil {
+			row.Error = "data inválida: " + field(record, dateCol)
+			rows = append(rows, row)
+			continue
+		}
+		row.Date = date
+
+		var signed models.Money
+		if profile.AmountMode == models.AmountSigned {
+			signed, err = parseStatementAmount(field(record, amountCol), profile.DecimalComma)
+		} else {
+			signed, err = splitAmount(field(record, debitCol), field(record, creditCol), profile.DecimalComma)
+		}
+		if err != nil {
+			row.Error = err.Error()
+			rows = append(rows, row)
+			continue
+		}
+		if profile.Negate {
+			signed = -signed
+		}
+
+		switch {
+		case signed < 0:
+			row.Type = "despesa"
+		case signed > 0:
+			row.Type = "receita"
+		default:
+			row.Error = "valor zerado"
+		}
+		row.Amount = signed.Abs()
+		if row.Description == "" && row.Error == "" {
+			row.Error = "descrição vazia"
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Preview interpreta o extrato e marca as linhas que já existem como lançamento
+func (s *ImportService) Preview(data []byte, profile models.ImportProfile) ([]ImportRow, error) {
+	rows, err := s.ParseCSV(data, profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.markDuplicates(rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// markDuplicates marca as linhas com lançamento igual já cadastrado
+// Igual = mesma data, valor, tipo e descrição (sem diferenciar maiúsculas e espaços).
+// A contagem é respeitada: dois cafés iguais no extrato e um já lançado marcam só um como duplicado
+func (s *ImportService) markDuplicates(rows []ImportRow) error {
+	var from, to time.Time
+	for _, row := range rows {
+		if !row.Valid() {
+			continue
+		}
+		if from.IsZero() || row.Date.Before(from) {
+			from = row.Date
+		}
+		if to.IsZero() || row.Date.After(to) {
+			to = row.Date
+		}
+	}
+	if from.IsZero() {
+		return nil
+	}
+
+	existing, err := s.expenseService.FindByDateRange(from, to)
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]int)
+	for _, expense := range existing {
+		counts[duplicateKey(expense.Date, expense.Amount, expense.Type, expense.Description)]++
+	}
+
+	for i := range rows {
+		if !rows[i].Valid() {
+			continue
+		}
+		key := duplicateKey(rows[i].Date, rows[i].Amount, rows[i].Type, rows[i].Description)
+		if counts[key] > 0 {
+			rows[i].Duplicate = true
+			counts[key]--
+		}
+	}
+	return nil
+}
+
+// Import cria os lançamentos das linhas selecionadas, em uma única transação
+// categories associa o número da linha à categoria escolhida na prévia (linhas fora do mapa são ignoradas);
+// todos os lançamentos vão para a conta accountID
+func (s *ImportService) Import(data []byte, profile models.ImportProfile, categories map[int]string, accountID int) (int, error) {
+	rows, err := s.ParseCSV(data, profile)
+	if err != nil {
+		return 0, err
+	}
+
+	var expenses []models.Expense
+	for _, row := range rows {
+		category, selected := categories[row.Line]
+		if !selected || !row.Valid() {
+			continue
+		}
+		expenses = append(expenses, models.Expense{
+			Description: row.Description,
+			Amount:      row.Amount,
+			Type:        row.Type,
+			Category:    category,
+			AccountID:   accountID,
+			Date:        row.Date,
+		})
+	}
+
+	if err := s.expenseService.ImportExpenses(expenses); err != nil {
+		return 0, err
+	}
+	return len(expenses), nil
+}
+
+// FindCategories retorna as categorias para os selects da importação
+func (s *ImportService) FindCategories() ([]models.Category, error) {
+	return s.expenseService.FindCategories()
+}
+
+// FindAccounts retorna as contas de destino da importação
+func (s *ImportService) FindAccounts() ([]models.Account, error) {
+	return s.expenseService.FindAccounts()
+}
+
+// SaveProfile grava o mapeamento de colunas de um banco para as próximas importações
+func (s *ImportService) SaveProfile(profile *models.ImportProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return errors.New("o nome do perfil não pode ser vazio")
+	}
+	if err := validateProfile(profile); err != nil {
+		return err
+	}
+	return s.profileRepo.Save(profile)
+}
+
+func (s *ImportService) FindProfiles() ([]models.ImportProfile, error) {
+	return s.profileRepo.FindAll()
+}
+
+func (s *ImportService) FindProfile(id int) (*models.ImportProfile, error) {
+	return s.profileRepo.FindByID(id)
+}
+
+func (s *ImportService) DeleteProfile(id int) error {
+	return s.profileRepo.Delete(id)
+}
+
+// csvDelimiter usa o separador do perfil ou detecta pela primeira linha (";" é comum nos bancos brasileiros)
+func csvDelimiter(data []byte, configured string) rune {
+	switch configured {
+	case ";":
+		return ';'
+	case ",":
+		return ','
+	case "\\t", "\t", "tab":
+		return '\t'
+	}
+
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	best, bestCount := ',', 0
+	for _, candidate := range []rune{';', ',', '\t'} {
+		if count := strings.Count(string(firstLine), string(candidate)); count > bestCount {
+			best, bestCount = candidate, count
+		}
+	}
+	return best
+}
+
+// resolveColumn encontra a coluna pelo número (1 = primeira) ou pelo nome no cabeçalho
+func resolveColumn(spec string, header []string) (int, error) {
+	spec = strings.TrimSpace(spec)
+	if n, err := strconv.Atoi(spec); err == nil {
+		if n < 1 {
+			return 0, fmt.Errorf("coluna inválida: %s", spec)
+		}
+		return n - 1, nil
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), spec) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("coluna não encontrada no cabeçalho: %s", spec)
+}
+
+// field retorna o valor da coluna, ou "" se a linha for mais curta
+func field(record []string, col int) string {
+	if col < 0 || col >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[col])
+}
+
+// isBlankRecord indica uma linha sem nenhum valor (rodapés e linhas em branco)
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseStatementDate lê a data no formato do banco, ignorando um horário depois dela
+func parseStatementDate(raw, layout string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if date, err := time.Parse(layout, raw); err == nil {
+		return date, nil
+	}
+	if fields := strings.Fields(raw); len(fields) > 1 {
+		return time.Parse(layout, fields[0])
+	}
+	return time.Time{}, errors.New("data inválida")
+}
+
+// parseStatementAmount lê um valor com sinal, com vírgula ou ponto decimal
+// Aceita "R$", sinal antes ou depois do prefixo ("-R$ 45,90", "R$ -45,90", "+R$ 10,00")
+// e valores negativos entre parênteses, como "(12,50)"
+func parseStatementAmount(raw string, decimalComma bool) (models.Money, error) {
+	s := strings.TrimSpace(strings.ReplaceAll(raw, "\u00a0", " "))
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = strings.TrimSpace(strings.Trim(s, "()"))
+	}
+
+	s, sign := cutSign(s)
+	s = strings.TrimSpace(strings.TrimPrefix(s, "R$"))
+	if sign == "" {
+		s, sign = cutSign(s)
+	}
+	if sign != "" && negative {
+		return 0, fmt.Errorf("valor inválido: %s", raw)
+	}
+	negative = negative || sign == "-"
+
+	if !decimalComma {
+		// "1,234.56" -> "1234,56" (formato lido por ParseMoney)
+		s = strings.ReplaceAll(s, ",", "")
+		s = strings.Replace(s, ".", ",", 1)
+	}
+
+	// O sinal já foi tirado: um segundo sinal ("--5", "-R$ -5") é inválido
+	value, err := models.ParseMoney(s)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("valor inválido: %s", raw)
+	}
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+// cutSign separa um "+" ou "-" no início do valor
+func cutSign(s string) (string, string) {
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		return strings.TrimSpace(s[1:]), s[:1]
+	}
+	return s, ""
+}
+
+// splitAmount converte colunas de débito e crédito em um valor com sinal (débito = negativo)
+func splitAmount(debit, credit string, decimalComma bool) (models.Money, error) {
+	if debit != "" {
+		value, err := parseStatementAmount(debit, decimalComma)
+		if err != nil {
+			return 0, err
+		}
+		if value != 0 {
+			return -value.Abs(), nil
+		}
+	}
+	if credit != "" {
+		value, err := parseStatementAmount(credit, decimalComma)
+		if err != nil {
+			return 0, err
+		}
+		return value.Abs(), nil
+	}
+	return 0, nil
+}
+
+// duplicateKey identifica um lançamento para a detecção de duplicatas
+func duplicateKey(date time.Time, amount models.Money, kind, description string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(description), " "))
+	return fmt.Sprintf("%s|%d|%s|%s", date.Format("2006-01-02"), amount, kind, normalized)
+}
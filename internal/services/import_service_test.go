@@ -0,0 +1,158 @@
+package services
+
+import (
+	"financas/internal/models"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseStatementAmount(t *testing.T) {
+	tests := []struct {
+		raw          string
+		decimalComma bool
+		want         models.Money
+	}{
+		{"-45,90", true, -4590},
+		{"-R$ 45,90", true, -4590},
+		{"R$ -45,90", true, -4590},
+		{"- R$ 45,90", true, -4590},
+		{"+R$ 10,00", true, 1000},
+		{"R$ 1.234,56", true, 123456},
+		{"(12,50)", true, -1250},
+		{"(R$ 12,50)", true, -1250},
+		{"R$ 45,90", true, 4590},
+		{"-45.90", false, -4590},
+		{"1,234.56", false, 123456},
+		{"-R$ 1,234.56", false, -123456},
+		{"+8.5", false, 850},
+	}
+	for _, tt := range tests {
+		got, err := parseStatementAmount(tt.raw, tt.decimalComma)
+		if err != nil {
+			t.Errorf("parseStatementAmount(%q) retornou erro: %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseStatementAmount(%q) = %d, quer %d", tt.raw, got, tt.want)
+		}
+	}
+
+	for _, raw := range []string{"", "R$", "--5", "-R$ -5,00", "R$ --3,00", "(-12,50)", "+-1", "abc"} {
+		if got, err := parseStatementAmount(raw, true); err == nil {
+			t.Errorf("parseStatementAmount(%q) = %d, quer erro", raw, got)
+		}
+	}
+}
+
+// importSummary resume uma linha interpretada para comparar nos testes
+type importSummary struct {
+	Line        int
+	Date        string
+	Description string
+	Amount      models.Money
+	Type        string
+	Error       string
+}
+
+func summarize(rows []ImportRow) []importSummary {
+	result := []importSummary{}
+	for _, r := range rows {
+		date := ""
+		if !r.Date.IsZero() {
+			date = r.Date.Format("2006-01-02")
+		}
+		result = append(result, importSummary{r.Line, date, r.Description, r.Amount, r.Type, r.Error})
+	}
+	return result
+}
+
+func TestParseCSVFixtures(t *testing.T) {
+	tests := []struct {
+		file    string
+		profile models.ImportProfile
+		want    []importSummary
+	}{
+		{
+			// Exportação da conta do Nubank: vírgula, ponto decimal, sem "R$"
+			file: "nubank_conta.csv",
+			profile: models.ImportProfile{
+				HasHeader: true, DateColumn: "Data", DateFormat: "02/01/2006", DescriptionColumn: "Descrição",
+				AmountMode: models.AmountSigned, AmountColumn: "Valor",
+			},
+			want: []importSummary{
+				{2, "2026-03-02", "Compra no débito - PADARIA PAO QUENTE", 4590, "despesa", ""},
+				{3, "2026-03-03", "Transferência recebida pelo Pix - EMPRESA EXEMPLO LTDA", 250000, "receita", ""},
+				{4, "2026-03-03", "Pagamento de boleto efetuado - CONDOMINIO", 125000, "despesa", ""},
+				{5, "2026-03-05", "Compra no débito - CAFE, PAO E CIA", 850, "despesa", ""},
+				{6, "2026-03-05", "Estorno zerado", 0, "", "valor zerado"},
+			},
+		},
+		{
+			// Extrato de planilha: BOM, CRLF, ponto e vírgula detectado e valores com "R$"
+			file: "extrato_ponto_virgula.csv",
+			profile: models.ImportProfile{
+				HasHeader: true, DateColumn: "1", DateFormat: "02/01/2006", DescriptionColumn: "Histórico",
+				AmountMode: models.AmountSigned, AmountColumn: "Valor", DecimalComma: true,
+			},
+			want: []importSummary{
+				{2, "2026-03-02", "PAG PADARIA PAO QUENTE", 4590, "despesa", ""},
+				{3, "2026-03-03", "PIX RECEBIDO EMPRESA", 250000, "receita", ""},
+				{4, "2026-03-04", "TARIFA PACOTE", 1990, "despesa", ""},
+				{5, "2026-03-04", "ESTORNO TARIFA", 1990, "receita", ""},
+				{6, "2026-03-06", "DEB AUTOMATICO", 12000, "despesa", ""},
+				{7, "2026-03-07", "LINHA QUEBRADA", 0, "", "valor inválido: R$ --3,00"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile("testdata/" + tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows, err := (&ImportService{}).ParseCSV(data, tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := summarize(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("linhas:\n%+v\nquer:\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewMarksDuplicates(t *testing.T) {
+	e := newTestEnv(t)
+	account := e.newAccount(t, "Conta", 0, 0)
+	for _, expense := range []models.Expense{
+		{Description: "Café", Amount: 850, Type: "despesa", Category: "Alimentação", AccountID: account, Date: day("2026-03-05")},
+		{Description: "PIX recebido", Amount: 250000, Type: "receita", Category: "Salário", AccountID: account, Date: day("2026-03-03")},
+	} {
+		if err := e.expenses.Create(&expense); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	csv := "Data,Descrição,Valor\n" +
+		"03/03/2026,pix  RECEBIDO,2500.00\n" + // mesma descrição sem diferenciar maiúsculas e espaços
+		"05/03/2026,Café,-8.50\n" +
+		"05/03/2026,Café,-8.50\n" + // segundo café: só um já foi lançado
+		"06/03/2026,Café,-8.50\n" + // outro dia
+		"05/03/2026,Café,8.50\n" + // mesmo valor, mas receita
+		"05/03/2026,Café,abc\n" // linha inválida nunca é duplicada
+	profile := models.ImportProfile{HasHeader: true, DateColumn: "Data", DateFormat: "02/01/2006",
+		DescriptionColumn: "Descrição", AmountMode: models.AmountSigned, AmountColumn: "Valor"}
+	rows, err := e.imports.Preview([]byte(csv), profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []bool{}
+	for _, row := range rows {
+		got = append(got, row.Duplicate)
+	}
+	if want := []bool{true, true, false, false, false, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("duplicadas = %v, quer %v", got, want)
+	}
+}
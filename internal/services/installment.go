@@ -0,0 +1,164 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"financas/internal/models"
+	"strings"
+	"time"
+)
+
+// ErrInstallmentPlanNotFound indica uma compra parcelada inexistente
+var ErrInstallmentPlanNotFound = errors.New("compra parcelada não encontrada")
+
+// MaxInstallments é o maior número de parcelas aceito em uma compra
+const MaxInstallments = 120
+
+// InstallmentPlanDetail é uma compra parcelada com as parcelas ativas
+type InstallmentPlanDetail struct {
+	Plan         models.InstallmentPlan `json:"plan"`
+	Installments []models.Expense       `json:"installments"`
+}
+
+// validateInstallmentPlan valida os campos de uma compra parcelada nova
+func validateInstallmentPlan(plan *models.InstallmentPlan) error {
+	plan.Description = strings.TrimSpace(plan.Description)
+	if plan.Description == "" {
+		return errors.New("a descrição não pode ser vazia")
+	}
+	if plan.Installments < 2 || plan.Installments > MaxInstallments {
+		return errors.New("o número de parcelas deve estar entre 2 e 120")
+	}
+	if plan.Total < models.Money(plan.Installments) {
+		return errors.New("o valor total deve ser de pelo menos 1 centavo por parcela")
+	}
+	if plan.FirstDate.IsZero() {
+		return errors.New("a data não pode ser vazia")
+	}
+	if plan.Remainder == "" {
+		plan.Remainder = models.RemainderLast
+	}
+	if plan.Remainder != models.RemainderFirst && plan.Remainder != models.RemainderLast {
+		return errors.New("escolha a primeira ou a última parcela para a sobra dos centavos")
+	}
+	return nil
+}
+
+// CreateInstallmentPlan grava uma compra parcelada: uma despesa por parcela, todo mês a partir
+// da primeira data, com valores em centavos exatos que somam o total
+// Parcelas em cartão de crédito entram cada uma na fatura do seu mês
+func (s *ExpenseService) CreateInstallmentPlan(plan *models.InstallmentPlan) error {
+	if err := validateInstallmentPlan(plan); err != nil {
+		return err
+	}
+	if err := s.resolveCategory(&plan.Category, "despesa"); err != nil {
+		return err
+	}
+	if err := s.resolveAccount(plan.AccountID); err != nil {
+		return err
+	}
+	payer, err := s.payerName(plan.PayerUserID, 0)
+	if err != nil {
+		return err
+	}
+	if payer == "" {
+		plan.PayerUserID = 0
+	}
+	plan.Payer = payer
+
+	amounts := models.SplitInstallments(plan.Total, plan.Installments, plan.Remainder)
+	dates := models.InstallmentDates(plan.FirstDate, plan.Installments)
+	installments := make([]models.Expense, plan.Installments)
+	for i := range installments {
+		installments[i] = models.Expense{
+			Description:       plan.Description,
+			Amount:            amounts[i],
+			Type:              "despesa",
+			Category:          plan.Category,
+			Payer:             plan.Payer,
+			PayerUserID:       plan.PayerUserID,
+			AccountID:         plan.AccountID,
+			Date:              dates[i],
+			InstallmentNumber: i + 1,
+			InstallmentCount:  plan.Installments,
+		}
+	}
+
+	return s.installmentRepo.Create(plan, installments)
+}
+
+// FindInstallmentPlans retorna as compras parceladas com as parcelas pagas e restantes em relação a hoje
+func (s *ExpenseService) FindInstallmentPlans(today time.Time) ([]models.InstallmentPlan, error) {
+	return s.installmentRepo.FindAll(today)
+}
+
+// FindInstallmentPlan retorna a compra parcelada com as parcelas ativas
+func (s *ExpenseService) FindInstallmentPlan(id int, today time.Time) (*InstallmentPlanDetail, error) {
+	plan, err := s.installmentRepo.FindByID(id, today)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInstallmentPlanNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	installments, err := s.installmentRepo.FindInstallments(id)
+	if err != nil {
+		return nil, err
+	}
+	return &InstallmentPlanDetail{Plan: *plan, Installments: installments}, nil
+}
+
+// UpdateRemainingInstallments altera descrição, categoria, conta e pagador do plano e das parcelas
+// restantes (depois de hoje); amount > 0 também troca o valor de cada parcela restante
+// As parcelas que já venceram não mudam
+func (s *ExpenseService) UpdateRemainingInstallments(plan *models.InstallmentPlan, amount models.Money, today time.Time) error {
+	current, err := s.FindInstallmentPlan(plan.ID, today)
+	if err != nil {
+		return err
+	}
+	if current.Plan.Remaining == 0 {
+		return errors.New("a compra não tem parcelas restantes")
+	}
+	if amount < 0 {
+		return errors.New("o valor da parcela não pode ser negativo")
+	}
+	plan.Description = strings.TrimSpace(plan.Description)
+	if plan.Description == "" {
+		return errors.New("a descrição não pode ser vazia")
+	}
+	if err := s.resolveCategory(&plan.Category, "despesa"); err != nil {
+		return err
+	}
+	if err := s.resolveAccount(plan.AccountID); err != nil {
+		return err
+	}
+	payer, err := s.payerName(plan.PayerUserID, current.Plan.PayerUserID)
+	if err != nil {
+		return err
+	}
+	if payer == "" {
+		plan.PayerUserID = 0
+	}
+	plan.Payer = payer
+
+	if _, err := s.installmentRepo.UpdateRemaining(plan, amount, today); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInstallmentPlanNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// CancelRemainingInstallments move para a lixeira as parcelas restantes (depois de hoje)
+// e retorna quantas foram canceladas; as parcelas que já venceram continuam lançadas
+func (s *ExpenseService) CancelRemainingInstallments(id int, today time.Time) (int64, error) {
+	current, err := s.FindInstallmentPlan(id, today)
+	if err != nil {
+		return 0, err
+	}
+	if current.Plan.Remaining == 0 {
+		return 0, errors.New("a compra não tem parcelas restantes")
+	}
+	return s.installmentRepo.CancelRemaining(id, today)
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"financas/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// installmentState resume cada parcela ativa como "número valor fatura"
+func (e *testEnv) installmentState(t *testing.T, planID int) []string {
+	t.Helper()
+	detail, err := e.expenses.FindInstallmentPlan(planID, day("2026-01-01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := []string{}
+	for _, installment := range detail.Installments {
+		state = append(state, installment.InstallmentLabel()+" "+installment.Amount.Input()+" "+e.invoiceOf(t, installment.ID))
+	}
+	return state
+}
+
+func TestInstallmentPlanLifecycle(t *testing.T) {
+	e := newTestEnv(t)
+	card := e.newAccount(t, "Cartão", 10, 20)
+	other := e.newAccount(t, "Outro cartão", 25, 5)
+
+	plan := &models.InstallmentPlan{Description: " Geladeira ", Total: 100001, Installments: 4, Category: "Moradia",
+		AccountID: card, FirstDate: day("2026-01-31")}
+	if err := e.expenses.CreateInstallmentPlan(plan); err != nil {
+		t.Fatal(err)
+	}
+	// Cada parcela na fatura do seu mês; a sobra de centavos fica na última
+	want := []string{
+		"1/4 250,00 2026-02-20",
+		"2/4 250,00 2026-03-20",
+		"3/4 250,00 2026-04-20",
+		"4/4 250,01 2026-05-20",
+	}
+	if got := e.installmentState(t, plan.ID); !reflect.DeepEqual(got, want) {
+		t.Fatalf("parcelas:\n%q\nquer:\n%q", got, want)
+	}
+
+	detail, err := e.expenses.FindInstallmentPlan(plan.ID, day("2026-02-28"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := detail.Plan; p.Description != "Geladeira" || p.Paid != 2 || p.Remaining != 2 || p.Outstanding != 50001 {
+		t.Errorf("plano em 28/02: %q, %d pagas, %d restantes, %d em aberto; quer 2, 2 e 50001",
+			p.Description, p.Paid, p.Remaining, p.Outstanding)
+	}
+
+	// Novo valor e outro cartão só para as parcelas depois de hoje (31/03 e 30/04 passam do fechamento no dia 25)
+	update := detail.Plan
+	update.Description = "Geladeira nova"
+	update.AccountID = other
+	if err := e.expenses.UpdateRemainingInstallments(&update, 30000, day("2026-02-28")); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"1/4 250,00 2026-02-20",
+		"2/4 250,00 2026-03-20",
+		"3/4 300,00 2026-05-05",
+		"4/4 300,00 2026-06-05",
+	}
+	if got := e.installmentState(t, plan.ID); !reflect.DeepEqual(got, want) {
+		t.Errorf("parcelas depois da alteração:\n%q\nquer:\n%q", got, want)
+	}
+	if n := e.count(t, `SELECT count(*) FROM expenses WHERE installment_plan_id = ? AND description = 'Geladeira nova'`, plan.ID); n != 2 {
+		t.Errorf("%d parcelas com a nova descrição, quer 2", n)
+	}
+
+	// Cancelar em 15/04 leva só a última parcela para a lixeira
+	cancelled, err := e.expenses.CancelRemainingInstallments(plan.ID, day("2026-04-15"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled != 1 {
+		t.Errorf("%d parcelas canceladas, quer 1", cancelled)
+	}
+	detail, err = e.expenses.FindInstallmentPlan(plan.ID, day("2026-04-15"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !detail.Plan.Cancelled || detail.Plan.Remaining != 0 || len(detail.Installments) != 3 {
+		t.Errorf("depois de cancelar: cancelado %v, %d restantes, %d parcelas ativas",
+			detail.Plan.Cancelled, detail.Plan.Remaining, len(detail.Installments))
+	}
+	if _, err := e.expenses.CancelRemainingInstallments(plan.ID, day("2026-04-15")); err == nil {
+		t.Error("segundo cancelamento aceito sem parcelas restantes")
+	}
+	if err := e.expenses.UpdateRemainingInstallments(&update, 0, day("2026-04-15")); err == nil {
+		t.Error("alteração aceita sem parcelas restantes")
+	}
+
+	if _, err := e.expenses.FindInstallmentPlan(999, day("2026-04-15")); !errors.Is(err, ErrInstallmentPlanNotFound) {
+		t.Errorf("plano inexistente: %v, quer ErrInstallmentPlanNotFound", err)
+	}
+}
+
+func TestInstallmentPlanValidation(t *testing.T) {
+	e := newTestEnv(t)
+	account := e.newAccount(t, "Conta", 0, 0)
+	valid := models.InstallmentPlan{Description: "TV", Total: 300000, Installments: 3, Category: "Lazer",
+		AccountID: account, FirstDate: day("2026-03-10")}
+
+	tests := []struct {
+		name   string
+		change func(p *models.InstallmentPlan)
+	}{
+		{"descrição em branco", func(p *models.InstallmentPlan) { p.Description = "  " }},
+		{"uma parcela", func(p *models.InstallmentPlan) { p.Installments = 1 }},
+		{"parcelas demais", func(p *models.InstallmentPlan) { p.Installments = MaxInstallments + 1 }},
+		{"menos de 1 centavo por parcela", func(p *models.InstallmentPlan) { p.Total = 2 }},
+		{"sem data", func(p *models.InstallmentPlan) { p.FirstDate = time.Time{} }},
+		{"sobra inválida", func(p *models.InstallmentPlan) { p.Remainder = "middle" }},
+		{"categoria de receita", func(p *models.InstallmentPlan) { p.Category = "Salário" }},
+		{"conta inexistente", func(p *models.InstallmentPlan) { p.AccountID = 999 }},
+	}
+	for _, tt := range tests {
+		plan := valid
+		tt.change(&plan)
+		if err := e.expenses.CreateInstallmentPlan(&plan); err == nil {
+			t.Errorf("%s: plano aceito", tt.name)
+		}
+	}
+	if n := e.count(t, `SELECT count(*) FROM expenses`); n != 0 {
+		t.Errorf("%d parcelas gravadas por planos inválidos", n)
+	}
+
+	plan := valid
+	plan.Remainder = models.RemainderFirst
+	plan.Total = 100000
+	if err := e.expenses.CreateInstallmentPlan(&plan); err != nil {
+		t.Fatal(err)
+	}
+	if n := e.count(t, `SELECT amount FROM expenses WHERE installment_plan_id = ? AND installment_number = 1`, plan.ID); n != 33334 {
+		t.Errorf("primeira parcela de %d, quer 33334", n)
+	}
+}
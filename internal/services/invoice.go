@@ -0,0 +1,121 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"financas/internal/models"
+	"financas/internal/repositories"
+	"fmt"
+	"time"
+)
+
+// ErrInvoiceNotFound indica uma fatura inexistente
+var ErrInvoiceNotFound = errors.New("fatura não encontrada")
+
+type InvoiceService struct {
+	repository  *repositories.InvoiceRepository
+	accountRepo *repositories.AccountRepository
+}
+
+func NewInvoiceService(repository *repositories.InvoiceRepository, accountRepo *repositories.AccountRepository) *InvoiceService {
+	return &InvoiceService{repository: repository, accountRepo: accountRepo}
+}
+
+// InvoiceDetail é uma fatura com os lançamentos dela
+type InvoiceDetail struct {
+	Invoice  models.Invoice   `json:"invoice"`
+	Expenses []models.Expense `json:"expenses"`
+}
+
+// AssignPending coloca nas faturas as compras no cartão gravadas antes das faturas existirem
+// Chamado ao iniciar o servidor; os lançamentos novos já entram na fatura ao serem gravados
+func (s *InvoiceService) AssignPending() error {
+	return s.repository.AssignPending()
+}
+
+// FindAll retorna as faturas do cartão (0 = todos) com a situação de hoje
+func (s *InvoiceService) FindAll(accountID int, today time.Time) ([]models.Invoice, error) {
+	invoices, err := s.repository.FindAll(accountID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range invoices {
+		invoices[i].Status = invoices[i].StatusOn(today)
+	}
+	return invoices, nil
+}
+
+// FindAccounts retorna as contas: os cartões para o filtro e as contas correntes para o pagamento
+func (s *InvoiceService) FindAccounts(today time.Time) ([]models.Account, error) {
+	return s.accountRepo.FindAll(today)
+}
+
+// Detail retorna a fatura com os lançamentos
+func (s *InvoiceService) Detail(id int, today time.Time) (*InvoiceDetail, error) {
+	invoice, err := s.findByID(id, today)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.repository.FindExpenses(id)
+	if err != nil {
+		return nil, err
+	}
+	return &InvoiceDetail{Invoice: *invoice, Expenses: expenses}, nil
+}
+
+func (s *InvoiceService) findByID(id int, today time.Time) (*models.Invoice, error) {
+	invoice, err := s.repository.FindByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	invoice.Status = invoice.StatusOn(today)
+	return invoice, nil
+}
+
+// Pay paga o total de uma fatura fechada com uma transferência da conta corrente para o cartão
+// A transferência não é despesa: as compras já contaram nas despesas, cada uma na sua data
+func (s *InvoiceService) Pay(id, fromAccountID int, date, today time.Time) error {
+	invoice, err := s.findByID(id, today)
+	if err != nil {
+		return err
+	}
+	switch invoice.Status {
+	case models.InvoicePaid:
+		return errors.New("a fatura já foi paga")
+	case models.InvoiceOpen:
+		return fmt.Errorf("a fatura ainda está aberta (fecha em %s)", invoice.ClosingDate.Format("02/01/2006"))
+	}
+	if invoice.Total <= 0 {
+		return errors.New("a fatura não tem valor a pagar")
+	}
+	if date.IsZero() {
+		return errors.New("a data não pode ser vazia")
+	}
+
+	from, err := s.accountRepo.FindByID(fromAccountID, today)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAccountNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if from.Type != models.AccountChecking {
+		return errors.New("o pagamento deve sair de uma conta corrente")
+	}
+
+	transfer := &models.Transfer{
+		FromAccountID: from.ID,
+		ToAccountID:   invoice.AccountID,
+		Amount:        invoice.Total,
+		Date:          date,
+		Description:   fmt.Sprintf("Pagamento da fatura %s de %s", invoice.DueDate.Format("01/2006"), invoice.AccountName),
+	}
+	err = s.repository.Pay(id, transfer)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("a fatura já foi paga")
+	}
+	return err
+}
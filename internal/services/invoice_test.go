@@ -0,0 +1,138 @@
+package services
+
+import (
+	"financas/internal/models"
+	"testing"
+)
+
+func TestInvoiceDates(t *testing.T) {
+	tests := []struct {
+		name         string
+		closing, due int
+		purchase     string
+		wantClosing  string
+		wantDue      string
+	}{
+		{"antes do fechamento", 10, 20, "2026-03-09", "2026-03-10", "2026-03-20"},
+		{"no dia do fechamento vai para a seguinte", 10, 20, "2026-03-10", "2026-04-10", "2026-04-20"},
+		{"vencimento no mês seguinte ao fechamento", 25, 5, "2026-03-01", "2026-03-25", "2026-04-05"},
+		{"virada do ano", 25, 5, "2026-12-26", "2027-01-25", "2027-02-05"},
+		{"fechamento no dia 31 em fevereiro", 31, 10, "2026-02-15", "2026-02-28", "2026-03-10"},
+		{"vencimento no dia 31 em abril", 20, 31, "2026-04-01", "2026-04-20", "2026-04-30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := models.Account{Type: models.AccountCreditCard, ClosingDay: tt.closing, DueDay: tt.due}
+			closing, due := card.InvoiceDates(day(tt.purchase))
+			if got := closing.Format("2006-01-02"); got != tt.wantClosing {
+				t.Errorf("fechamento = %s, quer %s", got, tt.wantClosing)
+			}
+			if got := due.Format("2006-01-02"); got != tt.wantDue {
+				t.Errorf("vencimento = %s, quer %s", got, tt.wantDue)
+			}
+		})
+	}
+}
+
+func TestExpenseWritesAssignInvoice(t *testing.T) {
+	e := newTestEnv(t)
+	card := e.newAccount(t, "Cartão", 10, 20)
+	other := e.newAccount(t, "Outro cartão", 25, 5)
+	checking := e.newAccount(t, "Conta", 0, 0)
+
+	before := e.newExpense(t, card, 1000, "2026-03-09")
+	after := e.newExpense(t, card, 2000, "2026-03-10")
+	cash := e.newExpense(t, checking, 500, "2026-03-09")
+	if got := e.invoiceOf(t, before.ID); got != "2026-03-20" {
+		t.Errorf("compra antes do fechamento na fatura de %q, quer 2026-03-20", got)
+	}
+	if got := e.invoiceOf(t, after.ID); got != "2026-04-20" {
+		t.Errorf("compra no fechamento na fatura de %q, quer 2026-04-20", got)
+	}
+	if got := e.invoiceOf(t, cash.ID); got != "" {
+		t.Errorf("compra na conta corrente na fatura de %q", got)
+	}
+
+	// Mudar a data ou o cartão troca a fatura
+	before.Date = day("2026-03-15")
+	if err := e.expenses.Update(before); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.invoiceOf(t, before.ID); got != "2026-04-20" {
+		t.Errorf("compra com nova data na fatura de %q, quer 2026-04-20", got)
+	}
+	before.AccountID = other
+	if err := e.expenses.Update(before); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.invoiceOf(t, before.ID); got != "2026-04-05" {
+		t.Errorf("compra no outro cartão na fatura de %q, quer 2026-04-05", got)
+	}
+
+	// Mudar só a descrição mantém a fatura
+	after.Description = "Mercado"
+	if err := e.expenses.Update(after); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.invoiceOf(t, after.ID); got != "2026-04-20" {
+		t.Errorf("compra editada na fatura de %q, quer 2026-04-20", got)
+	}
+
+	if n := e.count(t, `SELECT count(*) FROM invoices`); n != 3 {
+		t.Errorf("%d faturas criadas, quer 3", n)
+	}
+}
+
+func TestImportAssignsInvoices(t *testing.T) {
+	e := newTestEnv(t)
+	card := e.newAccount(t, "Cartão", 10, 20)
+
+	expenses := []models.Expense{
+		{Description: "Padaria", Amount: 1000, Type: "despesa", Category: "Alimentação", AccountID: card, Date: day("2026-03-01")},
+		{Description: "Mercado", Amount: 2000, Type: "despesa", Category: "Alimentação", AccountID: card, Date: day("2026-03-11")},
+	}
+	if err := e.expenses.ImportExpenses(expenses); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.invoiceOf(t, expenses[0].ID); got != "2026-03-20" {
+		t.Errorf("Padaria na fatura de %q, quer 2026-03-20", got)
+	}
+	if got := e.invoiceOf(t, expenses[1].ID); got != "2026-04-20" {
+		t.Errorf("Mercado na fatura de %q, quer 2026-04-20", got)
+	}
+}
+
+func TestAccountUpdateAssignsPendingInvoices(t *testing.T) {
+	e := newTestEnv(t)
+	card := e.newAccount(t, "Cartão", 10, 20)
+	expense := e.newExpense(t, card, 1000, "2026-03-09")
+
+	// Lançamento gravado antes das faturas: sem fatura até o cartão ou a inicialização atualizarem
+	e.exec(t, `UPDATE expenses SET invoice_id = NULL`)
+	e.exec(t, `DELETE FROM invoices`)
+	if err := e.invoices.AssignPending(); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.invoiceOf(t, expense.ID); got != "2026-03-20" {
+		t.Errorf("lançamento antigo na fatura de %q, quer 2026-03-20", got)
+	}
+
+	// Cartão cadastrado sem dia de fechamento e configurado depois
+	plain := e.newAccount(t, "Cartão sem fatura", 0, 0)
+	e.exec(t, `UPDATE accounts SET type = 'cartao' WHERE id = ?`, plain)
+	pending := e.newExpense(t, plain, 1000, "2026-03-09")
+	if got := e.invoiceOf(t, pending.ID); got != "" {
+		t.Fatalf("cartão sem fechamento criou fatura de %q", got)
+	}
+	account, err := e.accounts.FindByID(plain, day("2026-03-09"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	account.ClosingDay, account.DueDay = 5, 15
+	if err := e.accounts.Update(account); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.invoiceOf(t, pending.ID); got != "2026-04-15" {
+		t.Errorf("compra do cartão configurado na fatura de %q, quer 2026-04-15", got)
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"errors"
+	"financas/internal/models"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// OFXTransaction é uma transação (STMTTRN) do extrato OFX
+type OFXTransaction struct {
+	FITID       string       `json:"fitid"`
+	Type        string       `json:"type"` // TRNTYPE do banco (DEBIT, CREDIT, PAYMENT...)
+	Date        time.Time    `json:"date"`
+	Amount      models.Money `json:"amount"` // Com sinal: negativo = saída
+	Description string       `json:"description"`
+}
+
+// Kind classifica a transação pelo sinal de TRNAMT
+func (t OFXTransaction) Kind() string {
+	if t.Amount < 0 {
+		return "despesa"
+	}
+	return "receita"
+}
+
+// OFXStatement é o extrato OFX já interpretado
+type OFXStatement struct {
+	Account      string           `json:"account"` // BANKID/ACCTID (o FITID só é único dentro da conta)
+	Transactions []OFXTransaction `json:"transactions"`
+}
+
+// ofxTransactionBlock encontra cada transação; STMTTRN é fechado tanto no SGML quanto no XML
+var ofxTransactionBlock = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+
+// ofxValue lê o valor de um elemento
+// Serve para SGML (OFX 1.x, "<TRNAMT>-12.50" sem fechamento) e XML (OFX 2.x, "<TRNAMT>-12.50</TRNAMT>"):
+// o valor vai até a próxima tag ou o fim da linha
+func ofxValue(block, tag string) string {
+	re := regexp.MustCompile(`(?i)<` + tag + `>([^<\r\n]*)`)
+	match := re.FindStringSubmatch(block)
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(html.UnescapeString(match[1]))
+}
+
+// parseOFX interpreta um extrato OFX (SGML 1.x ou XML 2.x)
+func parseOFX(data []byte) (*OFXStatement, error) {
+	content := string(data)
+	// Extratos SGML de bancos brasileiros costumam vir em Windows-1252 (CHARSET:1252)
+	if !utf8.Valid(data) {
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		content = string(runes)
+	}
+
+	if !strings.Contains(strings.ToUpper(content), "<OFX>") {
+		return nil, errors.New("arquivo OFX inválido")
+	}
+
+	statement := &OFXStatement{Account: ofxValue(content, "ACCTID")}
+	if bank := ofxValue(content, "BANKID"); bank != "" {
+		statement.Account = bank + "/" + statement.Account
+	}
+
+	for _, match := range ofxTransactionBlock.FindAllStringSubmatch(content, -1) {
+		block := match[1]
+
+		t := OFXTransaction{
+			FITID: ofxValue(block, "FITID"),
+			Type:  ofxValue(block, "TRNTYPE"),
+		}
+		if t.FITID == "" {
+			return nil, errors.New("transação sem FITID no extrato")
+		}
+
+		date, err := parseOFXDate(ofxValue(block, "DTPOSTED"))
+		if err != nil {
+			return nil, err
+		}
+		t.Date = date
+
+		raw := ofxValue(block, "TRNAMT")
+		// Alguns bancos usam vírgula decimal no TRNAMT
+		decimalComma := strings.Contains(raw, ",") && !strings.Contains(raw, ".")
+		amount, err := parseStatementAmount(raw, decimalComma)
+		if err != nil {
+			return nil, err
+		}
+		t.Amount = amount
+
+		t.Description = ofxValue(block, "NAME")
+		if memo := ofxValue(block, "MEMO"); t.Description == "" {
+			t.Description = memo
+		}
+		if t.Description == "" {
+			t.Description = t.Type
+		}
+
+		statement.Transactions = append(statement.Transactions, t)
+	}
+	return statement, nil
+}
+
+// parseOFXDate lê datas OFX como "20260215", "20260215120000" ou "20260215120000[-3:BRT]"
+func parseOFXDate(raw string) (time.Time, error) {
+	if len(raw) < 8 {
+		return time.Time{}, errors.New("data inválida no extrato: " + raw)
+	}
+	date, err := time.Parse("20060102", raw[:8])
+	if err != nil {
+		return time.Time{}, errors.New("data inválida no extrato: " + raw)
+	}
+	return date, nil
+}
+
+// OFXConflict é uma transação com FITID já importado, mas com dados diferentes
+type OFXConflict struct {
+	Transaction OFXTransaction `json:"transaction"`
+	Existing    models.Expense `json:"existing"`
+}
+
+// OFXReport resume uma importação OFX
+type OFXReport struct {
+	Account     string           `json:"account"`
+	New         []OFXTransaction `json:"new"`         // Importadas agora
+	Skipped     int              `json:"skipped"`     // Já importadas antes (mesmo FITID e mesmos dados)
+	Conflicting []OFXConflict    `json:"conflicting"` // Mesmo FITID com valor, data ou tipo diferentes (não importadas)
+}
+
+// ImportOFX importa as transações novas do extrato, identificadas pelo FITID da conta
+// Transações já importadas (inclusive removidas depois) são ignoradas; se o banco mudou valor,
+// data ou sinal de um FITID já importado, a transação é reportada como conflito e não é importada.
+// Débitos recebem expenseCategory e créditos, incomeCategory; tudo vai para a conta accountID
+func (s *ImportService) ImportOFX(data []byte, expenseCategory, incomeCategory string, accountID int) (*OFXReport, error) {
+	if expenseCategory == "" || incomeCategory == "" {
+		return nil, errors.New("a categoria não pode ser vazia")
+	}
+
+	statement, err := parseOFX(data)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.expenseService.FindByFITID(statement.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OFXReport{Account: statement.Account}
+	var expenses []models.Expense
+	for _, t := range statement.Transactions {
+		if t.Amount == 0 {
+			report.Skipped++
+			continue
+		}
+
+		if previous, ok := existing[t.FITID]; ok {
+			if previous.Amount == t.Amount.Abs() && previous.Type == t.Kind() && sameDay(previous.Date, t.Date) {
+				report.Skipped++
+			} else {
+				report.Conflicting = append(report.Conflicting, OFXConflict{Transaction: t, Existing: previous})
+			}
+			continue
+		}
+
+		category := expenseCategory
+		if t.Kind() == models.CategoryIncome {
+			category = incomeCategory
+		}
+		expense := models.Expense{
+			Description: t.Description,
+			Amount:      t.Amount.Abs(),
+			Type:        t.Kind(),
+			Category:    category,
+			AccountID:   accountID,
+			Date:        t.Date,
+			FITID:       t.FITID,
+			OFXAccount:  statement.Account,
+		}
+		// FITID repetido no próprio arquivo (extratos concatenados)
+		existing[t.FITID] = expense
+		expenses = append(expenses, expense)
+		report.New = append(report.New, t)
+	}
+
+	if len(expenses) > 0 {
+		if err := s.expenseService.ImportExpenses(expenses); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// sameDay compara apenas a data do calendário
+func sameDay(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"bytes"
+	"financas/internal/models"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// ofxSummary resume uma transação interpretada para comparar nos testes
+type ofxSummary struct {
+	FITID       string
+	Date        string
+	Amount      models.Money
+	Description string
+}
+
+func TestParseOFXFixtures(t *testing.T) {
+	tests := []struct {
+		file    string
+		account string
+		want    []ofxSummary
+	}{
+		{
+			// SGML 1.x em Windows-1252, com datas com fuso, vírgula decimal e entidade no MEMO
+			file:    "extrato_itau.ofx",
+			account: "0341/56789-0",
+			want: []ofxSummary{
+				{"20260302001", "2026-03-02", -4590, "PAG PADARIA PÃO QUENTE"},
+				{"20260303001", "2026-03-03", 250000, "PIX RECEBIDO"},
+				{"20260304001", "2026-03-04", -1990, "TARIFA ADM & MANUTENÇÃO"},
+			},
+		},
+		{
+			// XML 2.x em UTF-8
+			file:    "extrato_xml.ofx",
+			account: "0260/1234567-8",
+			want: []ofxSummary{
+				{"6a1f0c2e-0001", "2026-03-05", -125000, "Pagamento de boleto - CONDOMÍNIO"},
+				{"6a1f0c2e-0002", "2026-03-06", 0, "Estorno zerado"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile("testdata/" + tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			statement, err := parseOFX(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statement.Account != tt.account {
+				t.Errorf("conta = %q, quer %q", statement.Account, tt.account)
+			}
+			got := []ofxSummary{}
+			for _, tr := range statement.Transactions {
+				got = append(got, ofxSummary{tr.FITID, tr.Date.Format("2006-01-02"), tr.Amount, tr.Description})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transações:\n%+v\nquer:\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	invalid := map[string]string{
+		"sem OFX":    "extrato qualquer",
+		"sem FITID":  "<OFX><STMTTRN><DTPOSTED>20260301<TRNAMT>-1.00</STMTTRN></OFX>",
+		"data ruim":  "<OFX><STMTTRN><FITID>1<DTPOSTED>2026<TRNAMT>-1.00</STMTTRN></OFX>",
+		"valor ruim": "<OFX><STMTTRN><FITID>1<DTPOSTED>20260301<TRNAMT>abc</STMTTRN></OFX>",
+	}
+	for name, content := range invalid {
+		if _, err := parseOFX([]byte(content)); err == nil {
+			t.Errorf("%s: extrato aceito", name)
+		}
+	}
+}
+
+func TestImportOFXDedupe(t *testing.T) {
+	e := newTestEnv(t)
+	account := e.newAccount(t, "Itaú", 0, 0)
+	data, err := os.ReadFile("testdata/extrato_itau.ofx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	importOFX := func(data []byte) *OFXReport {
+		t.Helper()
+		report, err := e.imports.ImportOFX(data, "Alimentação", "Salário", account)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	first := importOFX(data)
+	if len(first.New) != 3 || first.Skipped != 0 || len(first.Conflicting) != 0 {
+		t.Fatalf("primeira importação: %d novas, %d ignoradas, %d conflitos", len(first.New), first.Skipped, len(first.Conflicting))
+	}
+	if n := e.count(t, `SELECT count(*) FROM expenses WHERE type = 'receita' AND category = 'Salário' AND amount = 250000`); n != 1 {
+		t.Errorf("crédito importado %d vezes como receita", n)
+	}
+
+	// Reimportar o mesmo extrato, mesmo com um lançamento na lixeira, não duplica nada
+	e.exec(t, `UPDATE expenses SET deleted_at = CURRENT_TIMESTAMP WHERE fitid = '20260302001'`)
+	again := importOFX(data)
+	if len(again.New) != 0 || again.Skipped != 3 {
+		t.Errorf("reimportação: %d novas, %d ignoradas; quer 0 e 3", len(again.New), again.Skipped)
+	}
+
+	// O banco mudou o valor de um FITID já importado e mandou um novo repetido no arquivo
+	changed := bytes.Replace(data, []byte("<TRNAMT>-45.90"), []byte("<TRNAMT>-49.90"), 1)
+	changed = bytes.Replace(changed, []byte("</BANKTRANLIST>"), []byte(
+		"<STMTTRN>\r\n<TRNTYPE>DEBIT\r\n<DTPOSTED>20260309\r\n<TRNAMT>-8.50\r\n<FITID>20260309001\r\n<MEMO>CAFE\r\n</STMTTRN>\r\n"+
+			"<STMTTRN>\r\n<TRNTYPE>DEBIT\r\n<DTPOSTED>20260309\r\n<TRNAMT>-8.50\r\n<FITID>20260309001\r\n<MEMO>CAFE\r\n</STMTTRN>\r\n</BANKTRANLIST>"), 1)
+	report := importOFX(changed)
+	if len(report.New) != 1 || report.Skipped != 3 || len(report.Conflicting) != 1 {
+		t.Errorf("extrato alterado: %d novas, %d ignoradas, %d conflitos; quer 1, 3 e 1",
+			len(report.New), report.Skipped, len(report.Conflicting))
+	}
+	if len(report.Conflicting) == 1 && report.Conflicting[0].Existing.Amount != 4590 {
+		t.Errorf("conflito com o lançamento de %d, quer 4590", report.Conflicting[0].Existing.Amount)
+	}
+
+	// O FITID só é único dentro da conta: outro banco com os mesmos FITIDs importa tudo
+	other := importOFX(bytes.Replace(data, []byte("<BANKID>0341"), []byte("<BANKID>0001"), 1))
+	if len(other.New) != 3 {
+		t.Errorf("outra conta: %d novas, quer 3", len(other.New))
+	}
+	if n := e.count(t, `SELECT count(*) FROM expenses`); n != 7 {
+		t.Errorf("%d lançamentos no total, quer 7", n)
+	}
+}
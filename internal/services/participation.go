@@ -0,0 +1,59 @@
+package services
+
+import (
+	"financas/internal/models"
+	"math"
+	"time"
+)
+
+// participationFactors calcula quanto cada membro participa do rateio de um mês
+// Fator = peso configurado no mês (padrão 1) × fração dos dias do mês em que o membro estava na equipe.
+// Fator 0 significa que o membro não participa do mês (ainda não entrou, já saiu, foi arquivado ou fez opt-out)
+func participationFactors(users []models.User, weights map[int]float64, month string) map[int]float64 {
+	factors := make(map[int]float64)
+
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		// Mês inválido: sem pro-rata, apenas os pesos configurados
+		for _, user := range users {
+			factors[user.ID] = monthWeight(weights, user.ID)
+		}
+		return factors
+	}
+	end := start.AddDate(0, 1, -1)
+	daysInMonth := float64(end.Day())
+
+	for _, user := range users {
+		activeStart, activeEnd := start, end
+		if !user.JoinedAt.IsZero() && user.JoinedAt.After(activeStart) {
+			activeStart = user.JoinedAt
+		}
+		if !user.LeftAt.IsZero() && user.LeftAt.Before(activeEnd) {
+			activeEnd = user.LeftAt
+		}
+		// Membro arquivado deixa de participar a partir do dia do arquivamento
+		if user.IsArchived() {
+			archivedDay := time.Date(user.ArchivedAt.Year(), user.ArchivedAt.Month(), user.ArchivedAt.Day(), 0, 0, 0, 0, time.UTC)
+			if archivedDay.Before(activeEnd) {
+				activeEnd = archivedDay
+			}
+		}
+
+		if activeEnd.Before(activeStart) {
+			factors[user.ID] = 0
+			continue
+		}
+
+		activeDays := math.Round(activeEnd.Sub(activeStart).Hours()/24) + 1
+		factors[user.ID] = monthWeight(weights, user.ID) * activeDays / daysInMonth
+	}
+	return factors
+}
+
+// monthWeight retorna o peso configurado do membro no mês (1 = cota cheia quando não configurado)
+func monthWeight(weights map[int]float64, userID int) float64 {
+	if weight, ok := weights[userID]; ok {
+		return weight
+	}
+	return 1
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"financas/internal/models"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParticipationFactors(t *testing.T) {
+	at := func(s string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04:05", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name    string
+		user    models.User
+		weights map[int]float64
+		month   string
+		want    float64
+	}{
+		{"sempre na equipe", models.User{ID: 1}, nil, "2026-04", 1},
+		{"entrou no meio do mês", models.User{ID: 1, JoinedAt: day("2026-04-16")}, nil, "2026-04", 15.0 / 30},
+		{"entrou no último dia", models.User{ID: 1, JoinedAt: day("2026-04-30")}, nil, "2026-04", 1.0 / 30},
+		{"entrou antes do mês", models.User{ID: 1, JoinedAt: day("2026-01-10")}, nil, "2026-04", 1},
+		{"ainda não tinha entrado", models.User{ID: 1, JoinedAt: day("2026-05-01")}, nil, "2026-04", 0},
+		{"saiu no dia 10 de fevereiro", models.User{ID: 1, LeftAt: day("2026-02-10")}, nil, "2026-02", 10.0 / 28},
+		{"já tinha saído", models.User{ID: 1, LeftAt: day("2026-03-31")}, nil, "2026-04", 0},
+		{"entrou e saiu no mesmo mês", models.User{ID: 1, JoinedAt: day("2026-04-11"), LeftAt: day("2026-04-20")}, nil, "2026-04", 10.0 / 30},
+		{"arquivado no fim da tarde conta o dia", models.User{ID: 1, ArchivedAt: at("2026-04-06 18:30:00")}, nil, "2026-04", 6.0 / 30},
+		{"arquivado antes do mês", models.User{ID: 1, ArchivedAt: at("2026-03-15 09:00:00")}, nil, "2026-04", 0},
+		{"saída antes do arquivamento", models.User{ID: 1, LeftAt: day("2026-04-05"), ArchivedAt: at("2026-04-20 10:00:00")}, nil, "2026-04", 5.0 / 30},
+		{"peso configurado", models.User{ID: 1}, map[int]float64{1: 0.5}, "2026-04", 0.5},
+		{"peso de outro membro não vale", models.User{ID: 1}, map[int]float64{2: 0.5}, "2026-04", 1},
+		{"opt-out", models.User{ID: 1}, map[int]float64{1: 0}, "2026-04", 0},
+		{"peso e pro-rata juntos", models.User{ID: 1, JoinedAt: day("2026-04-16")}, map[int]float64{1: 2}, "2026-04", 1},
+		{"mês inválido usa só o peso", models.User{ID: 1, JoinedAt: day("2026-04-16")}, map[int]float64{1: 0.5}, "2026-13", 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factors := participationFactors([]models.User{tt.user}, tt.weights, tt.month)
+			if got := factors[tt.user.ID]; math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("fator = %v, quer %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateioFollowsParticipation(t *testing.T) {
+	e := newTestEnv(t)
+	ana := e.newUser(t, "Ana")
+	bia := e.newUser(t, "Bia")
+	caio := e.newUser(t, "Caio")
+
+	// Abril: Bia entra no dia 16 (meio mês) e Caio faz opt-out
+	e.exec(t, `UPDATE users SET joined_at = '2026-04-16' WHERE id = ?`, bia)
+	if err := e.users.SetParticipation(&models.MonthParticipation{UserID: caio, Month: "2026-04", Weight: 0}); err != nil {
+		t.Fatal(err)
+	}
+	e.newPurchase(t, ana, 9000, "2026-04-20")
+
+	rateio, err := e.purchases.CalculateRateio("2026-04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]models.Money{ana: 6000, bia: 3000, caio: 0}
+	for _, stat := range rateio.MemberStats {
+		if stat.Share != want[stat.UserID] {
+			t.Errorf("%s: cota %d, quer %d", stat.UserName, stat.Share, want[stat.UserID])
+		}
+	}
+
+	// Voltar ao padrão devolve a cota cheia a Caio
+	if err := e.users.DeleteParticipation(caio, "2026-04"); err != nil {
+		t.Fatal(err)
+	}
+	rateio, err = e.purchases.CalculateRateio("2026-04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stat := range rateio.MemberStats {
+		if stat.UserID == caio && stat.Share != 3600 {
+			t.Errorf("Caio sem opt-out: cota %d, quer 3600", stat.Share)
+		}
+	}
+
+	for _, weight := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if err := e.users.SetParticipation(&models.MonthParticipation{UserID: bia, Month: "2026-04", Weight: weight}); err == nil {
+			t.Errorf("peso %v aceito", weight)
+		}
+	}
+	if err := e.users.SetParticipation(&models.MonthParticipation{UserID: bia, Month: "abril", Weight: 1}); err == nil {
+		t.Error("mês inválido aceito")
+	}
+}
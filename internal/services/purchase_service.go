@@ -1,25 +1,44 @@
 package services
 
 import (
+	"database/sql"
 	"errors"
 	"financas/internal/models"
 	"financas/internal/repositories"
+	"fmt"
+	"math"
+	"sort"
 	"time"
 )
 
 type PurchaseService struct {
-	purchaseRepo *repositories.PurchaseRepository
-	userRepo     *repositories.UserRepository
+	purchaseRepo      *repositories.PurchaseRepository
+	userRepo          *repositories.UserRepository
+	balanceRepo       *repositories.MonthlyBalanceRepository
+	settlementRepo    *repositories.SettlementRepository
+	participationRepo *repositories.ParticipationRepository
 }
 
-func NewPurchaseService(purchaseRepo *repositories.PurchaseRepository, userRepo *repositories.UserRepository) *PurchaseService {
+func NewPurchaseService(
+	purchaseRepo *repositories.PurchaseRepository,
+	userRepo *repositories.UserRepository,
+	balanceRepo *repositories.MonthlyBalanceRepository,
+	settlementRepo *repositories.SettlementRepository,
+	participationRepo *repositories.ParticipationRepository,
+) *PurchaseService {
 	return &PurchaseService{
-		purchaseRepo: purchaseRepo,
-		userRepo:     userRepo,
+		purchaseRepo:      purchaseRepo,
+		userRepo:          userRepo,
+		balanceRepo:       balanceRepo,
+		settlementRepo:    settlementRepo,
+		participationRepo: participationRepo,
 	}
 }
 
-// Create registra uma nova compra de lanche
+// ErrMonthClosed indica que o mês já foi fechado e não aceita alterações
+var ErrMonthClosed = errors.New("o mês já está fechado")
+
+// Create registra uma nova compra de lanche, com os pontos de quem pagou lançados junto
 func (s *PurchaseService) Create(purchase *models.Purchase) error {
 	if purchase.Amount <= 0 {
 		return errors.New("o valor deve ser maior que 0")
@@ -31,13 +50,76 @@ func (s *PurchaseService) Create(purchase *models.Purchase) error {
 		return errors.New("a data não pode ser vazia")
 	}
 
-	// Verificar se o usuário existe
-	_, err := s.userRepo.FindByID(purchase.UserID)
+	// Verificar se o usuário existe e está ativo
+	user, err := s.userRepo.FindByID(purchase.UserID)
 	if err != nil {
 		return errors.New("usuário não encontrado")
 	}
+	if user.IsArchived() {
+		return errors.New("membro arquivado não pode registrar compras")
+	}
+
+	if err := s.validateParticipants(purchase); err != nil {
+		return err
+	}
+
+	// Meses fechados não aceitam novas compras
+	if err := s.ensureMonthOpen(purchase.Date.Format("2006-01")); err != nil {
+		return err
+	}
 
-	return s.purchaseRepo.Create(purchase)
+	return s.purchaseRepo.Create(purchase, paidSnackAward(purchase))
+}
+
+// paidSnackAward é o lançamento de pontos de quem pagou a compra (a compra e o mês são
+// preenchidos pelo repositório na gravação)
+func paidSnackAward(purchase *models.Purchase) *models.PointTransaction {
+	return &models.PointTransaction{
+		UserID: purchase.UserID,
+		Delta:  PointsPaidSnack,
+		Reason: models.ReasonPaidSnack,
+	}
+}
+
+// validateParticipants valida quem consumiu a compra (sem participantes = dividida entre todos)
+// Os valores fixos não podem passar do total e, se sobrar valor, alguém precisa ter peso para cobri-lo
+func (s *PurchaseService) validateParticipants(purchase *models.Purchase) error {
+	seen := make(map[int]bool)
+	var fixedTotal models.Money
+	weighted := false
+	for _, p := range purchase.Participants {
+		if seen[p.UserID] {
+			return errors.New("participante repetido na compra")
+		}
+		seen[p.UserID] = true
+
+		if math.IsNaN(p.Weight) || math.IsInf(p.Weight, 0) {
+			return errors.New("peso do participante inválido")
+		}
+		if p.Weight < 0 || p.Amount < 0 {
+			return errors.New("peso e valor do participante não podem ser negativos")
+		}
+		if _, err := s.userRepo.FindByID(p.UserID); err != nil {
+			return errors.New("participante não encontrado")
+		}
+
+		if p.Amount > 0 {
+			fixedTotal += p.Amount
+		} else if p.Weight > 0 {
+			weighted = true
+		}
+	}
+
+	if len(purchase.Participants) == 0 {
+		return nil
+	}
+	if fixedTotal > purchase.Amount {
+		return errors.New("a soma dos valores fixos passa do valor da compra")
+	}
+	if fixedTotal < purchase.Amount && !weighted {
+		return errors.New("sobra valor sem participante para dividir; informe um peso ou ajuste os valores fixos")
+	}
+	return nil
 }
 
 // FindAll retorna todas as compras
@@ -55,11 +137,77 @@ func (s *PurchaseService) FindByID(id int) (*models.Purchase, error) {
 	return s.purchaseRepo.FindByID(id)
 }
 
-// Delete remove uma compra
+// Update altera uma compra existente (dono, valor ou data)
+// Trocar o dono move os pontos da compra para o novo dono, junto com a alteração
+func (s *PurchaseService) Update(purchase *models.Purchase) error {
+	if purchase.Amount <= 0 {
+		return errors.New("o valor deve ser maior que 0")
+	}
+	if purchase.UserID <= 0 {
+		return errors.New("usuário inválido")
+	}
+	if purchase.Date.IsZero() {
+		return errors.New("a data não pode ser vazia")
+	}
+
+	user, err := s.userRepo.FindByID(purchase.UserID)
+	if err != nil {
+		return errors.New("usuário não encontrado")
+	}
+
+	current, err := s.purchaseRepo.FindByID(purchase.ID)
+	if err != nil {
+		return errors.New("compra não encontrada")
+	}
+
+	// Compras antigas de membros arquivados podem ser corrigidas, mas não transferidas para eles
+	if user.IsArchived() && current.UserID != purchase.UserID {
+		return errors.New("membro arquivado não pode receber compras")
+	}
+
+	if err := s.validateParticipants(purchase); err != nil {
+		return err
+	}
+
+	// Nem o mês de origem nem o de destino podem estar fechados
+	if err := s.ensureMonthOpen(current.Month); err != nil {
+		return err
+	}
+	if err := s.ensureMonthOpen(purchase.Date.Format("2006-01")); err != nil {
+		return err
+	}
+
+	var award *models.PointTransaction
+	if current.UserID != purchase.UserID {
+		award = paidSnackAward(purchase)
+	}
+	return s.purchaseRepo.Update(purchase, award)
+}
+
+// Delete remove uma compra (soft delete) e estorna os pontos que ela gerou
 func (s *PurchaseService) Delete(id int) error {
+	purchase, err := s.purchaseRepo.FindByID(id)
+	if err != nil {
+		return errors.New("compra não encontrada")
+	}
+	if err := s.ensureMonthOpen(purchase.Month); err != nil {
+		return err
+	}
 	return s.purchaseRepo.Delete(id)
 }
 
+// ensureMonthOpen retorna ErrMonthClosed se o mês já foi fechado
+func (s *PurchaseService) ensureMonthOpen(month string) error {
+	closed, err := s.balanceRepo.IsClosed(month)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return ErrMonthClosed
+	}
+	return nil
+}
+
 // GetCurrentMonth retorna o mês atual no formato "2006-01"
 func (s *PurchaseService) GetCurrentMonth() string {
 	return time.Now().Format("2006-01")
@@ -73,29 +221,247 @@ func (s *PurchaseService) GetDistinctMonths() ([]string, error) {
 // RateioData contém os dados de rateio para um mês
 type RateioData struct {
 	Month          string
-	TotalSpent     float64
-	SharePerPerson float64
-	MemberCount    int
+	TotalSpent     models.Money
+	SharePerPerson models.Money // Cota cheia (membro com participação integral no mês)
+	MemberCount    int          // Membros que participam do mês
 	MemberStats    []MemberRateioStat
+	Closed         bool                 // true quando os dados vêm do snapshot de fechamento
+	ClosedAt       time.Time            // Data do fechamento (zero se o mês está aberto)
+	CarryOver      bool                 // Mês fechado levando o saldo em aberto para os meses seguintes
+	Settlements    []models.Settlement  // Acertos já pagos no mês
+	Transfers      []SettlementTransfer // Plano de acerto para o saldo que ainda está em aberto
 }
 
 type MemberRateioStat struct {
-	UserID   int
-	UserName string
-	Paid     float64
-	Share    float64
-	Balance  float64 // Positivo = crédito, Negativo = débito
+	UserID      int
+	UserName    string
+	Paid        models.Money
+	Weight      float64 // Fator de participação no mês (1 = cota cheia, 0 = não participa)
+	Share       models.Money
+	Balance     models.Money // Positivo = crédito, Negativo = débito
+	Opening     models.Money // Saldo herdado de meses anteriores fechados com carry-over
+	Outstanding models.Money // Saldo acumulado: herdado + saldo do mês + acertos pagos
+}
+
+// WeightPercent retorna o fator de participação em porcentagem (para exibição)
+func (m MemberRateioStat) WeightPercent() float64 {
+	return m.Weight * 100
+}
+
+// SettlementTransfer é uma transferência sugerida do devedor para o credor
+type SettlementTransfer struct {
+	FromUserID   int
+	FromUserName string
+	ToUserID     int
+	ToUserName   string
+	Amount       models.Money
 }
 
-// CalculateRateio calcula o rateio para um mês
+// CalculateRateio retorna o rateio de um mês
+// Meses fechados usam o snapshot congelado; meses abertos são calculados ao vivo
+// Em ambos os casos o saldo herdado e os acertos pagos são aplicados e o plano de acerto é montado
 func (s *PurchaseService) CalculateRateio(month string) (*RateioData, error) {
-	users, err := s.userRepo.FindAll()
+	var data *RateioData
+	closing, err := s.balanceRepo.FindClosing(month)
+	switch {
+	case err == nil:
+		data, err = s.rateioFromSnapshot(closing)
+	case errors.Is(err, sql.ErrNoRows):
+		data, err = s.calculateLiveRateio(month)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	memberCount := len(users)
-	if memberCount == 0 {
+	if err := s.applySettlements(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// applySettlements soma o saldo herdado, abate os acertos pagos e calcula as transferências restantes
+func (s *PurchaseService) applySettlements(data *RateioData) error {
+	settlements, err := s.settlementRepo.FindByMonth(data.Month)
+	if err != nil {
+		return err
+	}
+
+	carried, err := s.balanceRepo.GetCarriedBalances(data.Month)
+	if err != nil {
+		return err
+	}
+
+	paid := make(map[int]models.Money)
+	for _, st := range settlements {
+		paid[st.FromUserID] += st.Amount // Devedor pagou: saldo sobe
+		paid[st.ToUserID] -= st.Amount   // Credor recebeu: saldo desce
+	}
+
+	for i := range data.MemberStats {
+		stat := &data.MemberStats[i]
+		stat.Opening = carried[stat.UserID]
+		stat.Outstanding = stat.Opening + stat.Balance + paid[stat.UserID]
+	}
+
+	data.Settlements = settlements
+	data.Transfers = []SettlementTransfer{}
+	// Com carry-over, o que sobrou do mês é acertado nos meses seguintes
+	if !data.CarryOver {
+		data.Transfers = BuildSettlementPlan(data.MemberStats)
+	}
+	return nil
+}
+
+// BuildSettlementPlan transforma os saldos em aberto na lista de transferências que zera todos eles
+// Estratégia gulosa: o maior devedor paga o maior credor até um dos dois zerar,
+// o que gera no máximo N-1 transferências
+func BuildSettlementPlan(stats []MemberRateioStat) []SettlementTransfer {
+	type party struct {
+		userID int
+		name   string
+		cents  int64
+	}
+
+	var debtors, creditors []party
+	for _, stat := range stats {
+		cents := int64(stat.Outstanding)
+		if cents < 0 {
+			debtors = append(debtors, party{stat.UserID, stat.UserName, -cents})
+		} else if cents > 0 {
+			creditors = append(creditors, party{stat.UserID, stat.UserName, cents})
+		}
+	}
+
+	sort.Slice(debtors, func(i, j int) bool { return debtors[i].cents > debtors[j].cents })
+	sort.Slice(creditors, func(i, j int) bool { return creditors[i].cents > creditors[j].cents })
+
+	transfers := []SettlementTransfer{}
+	i, j := 0, 0
+	for i < len(debtors) && j < len(creditors) {
+		amount := debtors[i].cents
+		if creditors[j].cents < amount {
+			amount = creditors[j].cents
+		}
+
+		transfers = append(transfers, SettlementTransfer{
+			FromUserID:   debtors[i].userID,
+			FromUserName: debtors[i].name,
+			ToUserID:     creditors[j].userID,
+			ToUserName:   creditors[j].name,
+			Amount:       models.Money(amount),
+		})
+
+		debtors[i].cents -= amount
+		creditors[j].cents -= amount
+		if debtors[i].cents == 0 {
+			i++
+		}
+		if creditors[j].cents == 0 {
+			j++
+		}
+	}
+	return transfers
+}
+
+// RecordSettlement marca uma transferência do plano de acerto como paga
+// O valor não pode passar do que o devedor ainda deve nem do que o credor ainda tem a receber no mês
+func (s *PurchaseService) RecordSettlement(settlement *models.Settlement) error {
+	if settlement.Amount <= 0 {
+		return errors.New("o valor deve ser maior que 0")
+	}
+	if settlement.FromUserID == settlement.ToUserID {
+		return errors.New("quem paga e quem recebe devem ser diferentes")
+	}
+	if _, err := time.Parse("2006-01", settlement.Month); err != nil {
+		return errors.New("mês inválido")
+	}
+	if _, err := s.userRepo.FindByID(settlement.FromUserID); err != nil {
+		return errors.New("devedor não encontrado")
+	}
+	if _, err := s.userRepo.FindByID(settlement.ToUserID); err != nil {
+		return errors.New("credor não encontrado")
+	}
+
+	rateio, err := s.CalculateRateio(settlement.Month)
+	if err != nil {
+		return err
+	}
+	if rateio.CarryOver {
+		return errors.New("o mês foi fechado levando os saldos adiante; registre o acerto no mês seguinte")
+	}
+	var debt, credit models.Money
+	for _, stat := range rateio.MemberStats {
+		switch stat.UserID {
+		case settlement.FromUserID:
+			debt = -stat.Outstanding
+		case settlement.ToUserID:
+			credit = stat.Outstanding
+		}
+	}
+	limit := min(debt, credit)
+	if limit <= 0 {
+		return errors.New("não há saldo em aberto do devedor para o credor neste mês")
+	}
+	if settlement.Amount > limit {
+		return fmt.Errorf("o valor passa do saldo em aberto entre os dois (R$ %s)", limit)
+	}
+	return s.settlementRepo.Create(settlement)
+}
+
+// DeleteSettlement desfaz o registro de um acerto pago
+func (s *PurchaseService) DeleteSettlement(id int) error {
+	return s.settlementRepo.Delete(id)
+}
+
+// rateioFromSnapshot monta o rateio a partir dos balanços gravados no fechamento
+func (s *PurchaseService) rateioFromSnapshot(closing *models.MonthClosing) (*RateioData, error) {
+	balances, err := s.balanceRepo.FindByMonth(closing.Month)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := []MemberRateioStat{}
+	for _, b := range balances {
+		// Snapshots antigos não gravavam o fator; ele é derivado da cota
+		weight := b.Weight
+		if weight < 0 {
+			weight = 0
+			if closing.SharePerPerson > 0 {
+				weight = b.ShareValue.Float() / closing.SharePerPerson.Float()
+			}
+		}
+		stats = append(stats, MemberRateioStat{
+			UserID:   b.UserID,
+			UserName: b.UserName,
+			Paid:     b.TotalPaid,
+			Weight:   weight,
+			Share:    b.ShareValue,
+			Balance:  b.Balance,
+		})
+	}
+
+	return &RateioData{
+		Month:          closing.Month,
+		TotalSpent:     closing.TotalSpent,
+		SharePerPerson: closing.SharePerPerson,
+		MemberCount:    closing.MemberCount,
+		MemberStats:    stats,
+		Closed:         true,
+		ClosedAt:       closing.ClosedAt,
+		CarryOver:      closing.CarryOver,
+	}, nil
+}
+
+// calculateLiveRateio calcula o rateio a partir das compras e membros atuais
+// A cota de cada membro é calculada compra a compra: as divididas entre todos seguem
+// o fator de participação no mês, as demais só entre seus participantes
+func (s *PurchaseService) calculateLiveRateio(month string) (*RateioData, error) {
+	users, err := s.userRepo.FindAllWithArchived()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
 		return &RateioData{
 			Month:          month,
 			TotalSpent:     0,
@@ -117,20 +483,54 @@ func (s *PurchaseService) CalculateRateio(month string) (*RateioData, error) {
 		return nil, err
 	}
 
-	// Calcular cota por pessoa
-	share := total / float64(memberCount)
+	// Compras do mês com seus participantes
+	purchases, err := s.purchaseRepo.FindByMonth(month)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fator de participação de cada membro (período na equipe e peso do mês)
+	weights, err := s.participationRepo.GetWeightsByMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	factors := participationFactors(users, weights, month)
+
+	// Saldo herdado: membro arquivado que ainda deve ou tem a receber continua no rateio
+	carried, err := s.balanceRepo.GetCarriedBalances(month)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cota de cada membro e cota cheia (por unidade de participação)
+	shares, share, err := memberShares(purchases, factors)
+	if err != nil {
+		return nil, err
+	}
 
 	// Montar estatísticas de cada membro
 	var stats []MemberRateioStat
+	memberCount := 0
 	for _, user := range users {
 		paid := totals[user.ID] // 0 se não pagou nada
-		balance := paid - share
+		memberShare := shares[user.ID]
+
+		// Arquivados só aparecem nos meses em que ainda participavam, pagaram algo ou têm saldo herdado
+		if user.IsArchived() && factors[user.ID] == 0 && paid == 0 && memberShare == 0 && carried[user.ID] == 0 {
+			continue
+		}
+		if factors[user.ID] > 0 || memberShare > 0 {
+			memberCount++
+		}
+
+		balance := paid - memberShare
 
 		stats = append(stats, MemberRateioStat{
 			UserID:   user.ID,
 			UserName: user.Name,
 			Paid:     paid,
-			Share:    share,
+			Weight:   factors[user.ID],
+			Share:    memberShare,
 			Balance:  balance,
 		})
 	}
@@ -143,3 +543,66 @@ func (s *PurchaseService) CalculateRateio(month string) (*RateioData, error) {
 		MemberStats:    stats,
 	}, nil
 }
+
+// CloseMonth fecha o mês, gravando o snapshot do rateio em monthly_balances
+// A partir daí o mês não muda mais, mesmo que membros sejam adicionados ou removidos.
+// Com carryOver, o saldo que ficar em aberto entra como saldo inicial dos meses seguintes;
+// sem ele, o acerto é feito no próprio mês e os meses seguintes começam zerados
+func (s *PurchaseService) CloseMonth(month string, carryOver bool) error {
+	if err := s.ensureMonthOpen(month); err != nil {
+		return err
+	}
+
+	rateio, err := s.calculateLiveRateio(month)
+	if err != nil {
+		return err
+	}
+
+	var balances []models.MonthlyBalance
+	for _, stat := range rateio.MemberStats {
+		balances = append(balances, models.MonthlyBalance{
+			UserID:     stat.UserID,
+			Month:      month,
+			TotalPaid:  stat.Paid,
+			ShareValue: stat.Share,
+			Balance:    stat.Balance,
+			Weight:     stat.Weight,
+		})
+	}
+
+	closing := &models.MonthClosing{
+		Month:          month,
+		TotalSpent:     rateio.TotalSpent,
+		MemberCount:    rateio.MemberCount,
+		SharePerPerson: rateio.SharePerPerson,
+		CarryOver:      carryOver,
+	}
+	return s.balanceRepo.CloseMonth(closing, balances)
+}
+
+// ReopenMonth desfaz o fechamento do mês, liberando novas compras e o recálculo
+func (s *PurchaseService) ReopenMonth(month string) error {
+	closed, err := s.balanceRepo.IsClosed(month)
+	if err != nil {
+		return err
+	}
+	if !closed {
+		return errors.New("o mês não está fechado")
+	}
+	return s.balanceRepo.ReopenMonth(month)
+}
+
+// GetCumulativeBalances retorna o saldo acumulado de cada membro até o mês atual
+// (saldo herdado dos meses com carry-over + saldo em aberto do mês atual)
+func (s *PurchaseService) GetCumulativeBalances() (map[int]MemberRateioStat, error) {
+	rateio, err := s.CalculateRateio(s.GetCurrentMonth())
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[int]MemberRateioStat)
+	for _, stat := range rateio.MemberStats {
+		balances[stat.UserID] = stat
+	}
+	return balances, nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"financas/internal/models"
+	"reflect"
+	"testing"
+)
+
+// openings retorna o saldo herdado de cada membro no rateio do mês
+func openings(t *testing.T, e *testEnv, month string) map[int]models.Money {
+	t.Helper()
+	rateio, err := e.purchases.CalculateRateio(month)
+	if err != nil {
+		t.Fatalf("rateio de %s: %v", month, err)
+	}
+	result := make(map[int]models.Money)
+	for _, stat := range rateio.MemberStats {
+		result[stat.UserID] = stat.Opening
+	}
+	return result
+}
+
+func TestCarryOverOpening(t *testing.T) {
+	// Jan: Ana paga 100 entre os dois (Ana +50, Bia -50)
+	// Fev: Bia paga 40 (Ana -20, Bia +20) e quem deve acerta 10 antes do fechamento
+	tests := []struct {
+		name      string
+		janCarry  bool
+		febCarry  bool
+		wantFeb   models.Money // herdado por Ana em fevereiro
+		wantMarch models.Money // herdado por Ana em março
+	}{
+		{"carry-over nos dois meses", true, true, 5000, 2000},
+		{"janeiro com carry-over, fevereiro sem", true, false, 5000, 0},
+		{"janeiro sem carry-over, fevereiro com", false, true, 0, -1000},
+		{"nenhum com carry-over", false, false, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEnv(t)
+			ana := e.newUser(t, "Ana")
+			bia := e.newUser(t, "Bia")
+
+			e.newPurchase(t, ana, 10000, "2026-01-10")
+			if err := e.purchases.CloseMonth("2026-01", tt.janCarry); err != nil {
+				t.Fatal(err)
+			}
+
+			e.newPurchase(t, bia, 4000, "2026-02-10")
+			feb := openings(t, e, "2026-02")
+			if feb[ana] != tt.wantFeb || feb[bia] != -tt.wantFeb {
+				t.Errorf("herdado em fevereiro = %v, quer Ana %d / Bia %d", feb, tt.wantFeb, -tt.wantFeb)
+			}
+
+			// Bia só deve para Ana em fevereiro quando janeiro passou o saldo adiante
+			if tt.janCarry {
+				err := e.purchases.RecordSettlement(&models.Settlement{Month: "2026-02", FromUserID: bia, ToUserID: ana, Amount: 1000})
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				err := e.purchases.RecordSettlement(&models.Settlement{Month: "2026-02", FromUserID: ana, ToUserID: bia, Amount: 1000})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := e.purchases.CloseMonth("2026-02", tt.febCarry); err != nil {
+				t.Fatal(err)
+			}
+
+			march := openings(t, e, "2026-03")
+			if march[ana] != tt.wantMarch || march[bia] != -tt.wantMarch {
+				t.Errorf("herdado em março = %v, quer Ana %d / Bia %d", march, tt.wantMarch, -tt.wantMarch)
+			}
+		})
+	}
+}
+
+func TestCarryOverResetsAfterReopen(t *testing.T) {
+	e := newTestEnv(t)
+	ana := e.newUser(t, "Ana")
+	e.newUser(t, "Bia")
+
+	e.newPurchase(t, ana, 10000, "2026-01-10")
+	if err := e.purchases.CloseMonth("2026-01", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := openings(t, e, "2026-02")[ana]; got != 5000 {
+		t.Fatalf("herdado com janeiro fechado = %d, quer 5000", got)
+	}
+
+	if err := e.purchases.ReopenMonth("2026-01"); err != nil {
+		t.Fatal(err)
+	}
+	if got := openings(t, e, "2026-02")[ana]; got != 0 {
+		t.Errorf("herdado com janeiro reaberto = %d, quer 0", got)
+	}
+}
+
+func TestArchivedDebtorStaysInRateio(t *testing.T) {
+	e := newTestEnv(t)
+	ana := e.newUser(t, "Ana")
+	e.newUser(t, "Bia")
+	caio := e.newUser(t, "Caio")
+
+	// Jan: Ana paga 90 entre os três (Ana +60, Bia -30, Caio -30)
+	e.newPurchase(t, ana, 9000, "2026-01-10")
+	if err := e.purchases.CloseMonth("2026-01", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Caio sai da equipe devendo (arquivado com force=1)
+	e.exec(t, `UPDATE users SET archived_at = '2026-01-31 12:00:00' WHERE id = ?`, caio)
+
+	rateio, err := e.purchases.CalculateRateio("2026-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	var total models.Money
+	for _, stat := range rateio.MemberStats {
+		total += stat.Outstanding
+		if stat.UserID == caio {
+			found = true
+			if stat.Opening != -3000 || stat.Share != 0 {
+				t.Errorf("Caio: herdado %d, cota %d; quer -3000 e 0", stat.Opening, stat.Share)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("membro arquivado com dívida sumiu do rateio")
+	}
+	if total != 0 {
+		t.Errorf("saldos acumulados somam %d, quer 0", total)
+	}
+
+	var planned models.Money
+	for _, transfer := range rateio.Transfers {
+		if transfer.FromUserID == caio {
+			planned += transfer.Amount
+		}
+	}
+	if planned != 3000 {
+		t.Errorf("plano de acerto cobra %d de Caio, quer 3000 (transferências: %+v)", planned, rateio.Transfers)
+	}
+}
+
+func TestBuildSettlementPlan(t *testing.T) {
+	type balances map[int]models.Money
+	tests := []struct {
+		name        string
+		outstanding balances
+		want        []SettlementTransfer
+	}{
+		{"todos zerados", balances{1: 0, 2: 0}, []SettlementTransfer{}},
+		{"um devedor e um credor", balances{1: 5000, 2: -5000},
+			[]SettlementTransfer{{FromUserID: 2, ToUserID: 1, Amount: 5000}}},
+		{"maior devedor paga o maior credor primeiro", balances{1: 7000, 2: 2000, 3: -6000, 4: -3000},
+			[]SettlementTransfer{
+				{FromUserID: 3, ToUserID: 1, Amount: 6000},
+				{FromUserID: 4, ToUserID: 1, Amount: 1000},
+				{FromUserID: 4, ToUserID: 2, Amount: 2000},
+			}},
+		{"centavos sem sobra", balances{1: 1, 2: 1, 3: -2},
+			[]SettlementTransfer{
+				{FromUserID: 3, ToUserID: 1, Amount: 1},
+				{FromUserID: 3, ToUserID: 2, Amount: 1},
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := []MemberRateioStat{}
+			for userID := 1; userID <= len(tt.outstanding); userID++ {
+				stats = append(stats, MemberRateioStat{UserID: userID, Outstanding: tt.outstanding[userID]})
+			}
+			got := BuildSettlementPlan(stats)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("plano:\n%+v\nquer:\n%+v", got, tt.want)
+			}
+
+			// O plano zera todos os saldos com no máximo N-1 transferências
+			left := make(map[int]models.Money)
+			for userID, cents := range tt.outstanding {
+				left[userID] = cents
+			}
+			for _, transfer := range got {
+				left[transfer.FromUserID] += transfer.Amount
+				left[transfer.ToUserID] -= transfer.Amount
+			}
+			for userID, cents := range left {
+				if cents != 0 {
+					t.Errorf("membro %d fica com %d depois do plano", userID, cents)
+				}
+			}
+			if len(got) > len(tt.outstanding)-1 {
+				t.Errorf("%d transferências para %d membros", len(got), len(tt.outstanding))
+			}
+		})
+	}
+}
+
+func TestRecordSettlementValidation(t *testing.T) {
+	e := newTestEnv(t)
+	ana := e.newUser(t, "Ana")
+	bia := e.newUser(t, "Bia")
+	caio := e.newUser(t, "Caio")
+
+	// Fev: Ana paga 90 entre os três (Ana +60, Bia -30, Caio -30)
+	e.newPurchase(t, ana, 9000, "2026-02-10")
+	// Jan fechado levando os saldos adiante
+	e.newPurchase(t, ana, 3000, "2026-01-10")
+	if err := e.purchases.CloseMonth("2026-01", true); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		settlement models.Settlement
+		wantErr    bool
+	}{
+		{"valor zero", models.Settlement{Month: "2026-02", FromUserID: bia, ToUserID: ana, Amount: 0}, true},
+		{"para si mesmo", models.Settlement{Month: "2026-02", FromUserID: bia, ToUserID: bia, Amount: 1000}, true},
+		{"mês inválido", models.Settlement{Month: "2026-13", FromUserID: bia, ToUserID: ana, Amount: 1000}, true},
+		{"membro inexistente", models.Settlement{Month: "2026-02", FromUserID: 999, ToUserID: ana, Amount: 1000}, true},
+		{"sentido contrário", models.Settlement{Month: "2026-02", FromUserID: ana, ToUserID: bia, Amount: 1000}, true},
+		{"entre dois devedores", models.Settlement{Month: "2026-02", FromUserID: bia, ToUserID: caio, Amount: 1000}, true},
+		{"mês fechado com carry-over", models.Settlement{Month: "2026-01", FromUserID: bia, ToUserID: ana, Amount: 1000}, true},
+		// Bia deve 30 do mês e 10 herdados de janeiro
+		{"acima do saldo em aberto", models.Settlement{Month: "2026-02", FromUserID: bia, ToUserID: ana, Amount: 4001}, true},
+		{"parte da dívida", models.Settlement{Month: "2026-02", FromUserID: bia, ToUserID: ana, Amount: 1500}, false},
+		{"o que sobrou, contando o já pago", models.Settlement{Month: "2026-02", FromUserID: bia, ToUserID: ana, Amount: 2501}, true},
+		{"quitação", models.Settlement{Month: "2026-02", FromUserID: bia, ToUserID: ana, Amount: 2500}, false},
+	}
+	for _, tt := range tests {
+		settlement := tt.settlement
+		err := e.purchases.RecordSettlement(&settlement)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: erro = %v, quer erro %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// Com Bia quitada, o plano só cobra Caio
+	rateio, err := e.purchases.CalculateRateio("2026-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SettlementTransfer{{FromUserID: caio, FromUserName: "Caio", ToUserID: ana, ToUserName: "Ana", Amount: 4000}}
+	if !reflect.DeepEqual(rateio.Transfers, want) {
+		t.Errorf("plano depois dos acertos: %+v, quer %+v", rateio.Transfers, want)
+	}
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"errors"
+	"financas/internal/models"
+	"time"
+)
+
+// validateRecurrence valida o modelo do lançamento e o agendamento de uma recorrência
+func validateRecurrence(rec *models.Recurrence) error {
+	if rec.Amount <= 0 {
+		return errors.New("o valor deve ser maior que 0")
+	}
+	if rec.Description == "" {
+		return errors.New("a descrição não pode ser vazia")
+	}
+	if rec.Type == "" {
+		return errors.New("o tipo não pode ser vazio")
+	}
+	if rec.Category == "" {
+		return errors.New("a categoria não pode ser vazia")
+	}
+	if rec.StartDate.IsZero() {
+		return errors.New("a data de início não pode ser vazia")
+	}
+	if !rec.EndDate.IsZero() && rec.EndDate.Before(rec.StartDate) {
+		return errors.New("a data final não pode ser anterior ao início")
+	}
+
+	switch rec.Frequency {
+	case models.FrequencyMonthly:
+		if rec.DayOfMonth < 1 || rec.DayOfMonth > 31 {
+			return errors.New("o dia do mês deve estar entre 1 e 31")
+		}
+	case models.FrequencyDays:
+		if rec.Interval < 1 {
+			return errors.New("o intervalo deve ser de pelo menos 1 dia")
+		}
+	case models.FrequencyWeekly, models.FrequencyYearly:
+	default:
+		return errors.New("frequência inválida")
+	}
+
+	// Semanal, anual e a cada N dias seguem o dia da data de início
+	if rec.Frequency != models.FrequencyMonthly {
+		rec.DayOfMonth = rec.StartDate.Day()
+	}
+	if rec.Frequency != models.FrequencyDays {
+		rec.Interval = 1
+	}
+	return nil
+}
+
+// CreateRecurrence cadastra uma recorrência e já gera as ocorrências vencidas até hoje
+// (uma recorrência com início no passado é preenchida desde o início)
+func (s *ExpenseService) CreateRecurrence(rec *models.Recurrence, today time.Time) error {
+	if err := validateRecurrence(rec); err != nil {
+		return err
+	}
+	if err := s.resolveCategory(&rec.Category, rec.Type); err != nil {
+		return err
+	}
+	if err := s.resolveAccount(rec.AccountID); err != nil {
+		return err
+	}
+	payer, err := s.payerName(rec.PayerUserID, 0)
+	if err != nil {
+		return err
+	}
+	rec.Payer = payer
+	rec.NextDate = rec.First()
+
+	s.recurrenceMu.Lock()
+	defer s.recurrenceMu.Unlock()
+
+	if err := s.recurrenceRepo.Create(rec); err != nil {
+		return err
+	}
+	_, err = s.generateDue(today)
+	return err
+}
+
+func (s *ExpenseService) FindAllRecurrences() ([]models.Recurrence, error) {
+	return s.recurrenceRepo.FindAll()
+}
+
+func (s *ExpenseService) FindRecurrenceByID(id int) (*models.Recurrence, error) {
+	return s.recurrenceRepo.FindByID(id)
+}
+
+// UpdateRecurrence altera o modelo e o agendamento de uma recorrência
+// As mudanças valem só para as próximas ocorrências: os lançamentos já gerados não são alterados
+// e o novo agendamento continua a partir da próxima ocorrência pendente, sem gerar períodos passados
+func (s *ExpenseService) UpdateRecurrence(rec *models.Recurrence, today time.Time) error {
+	if err := validateRecurrence(rec); err != nil {
+		return err
+	}
+	if err := s.resolveCategory(&rec.Category, rec.Type); err != nil {
+		return err
+	}
+	if err := s.resolveAccount(rec.AccountID); err != nil {
+		return err
+	}
+
+	s.recurrenceMu.Lock()
+	defer s.recurrenceMu.Unlock()
+
+	current, err := s.recurrenceRepo.FindByID(rec.ID)
+	if err != nil {
+		return errors.New("recorrência não encontrada")
+	}
+	payer, err := s.payerName(rec.PayerUserID, current.PayerUserID)
+	if err != nil {
+		return err
+	}
+	rec.Payer = payer
+	rec.Paused = current.Paused
+	rec.NextDate = rec.FirstOnOrAfter(current.NextDate)
+
+	if err := s.recurrenceRepo.Update(rec); err != nil {
+		return err
+	}
+	_, err = s.generateDue(today)
+	return err
+}
+
+// PauseRecurrence suspende a geração de lançamentos de uma recorrência
+func (s *ExpenseService) PauseRecurrence(id int) error {
+	s.recurrenceMu.Lock()
+	defer s.recurrenceMu.Unlock()
+
+	rec, err := s.recurrenceRepo.FindByID(id)
+	if err != nil {
+		return errors.New("recorrência não encontrada")
+	}
+	rec.Paused = true
+	return s.recurrenceRepo.Update(rec)
+}
+
+// ResumeRecurrence retoma uma recorrência pausada
+// Os períodos em que ela ficou pausada não são gerados: a próxima ocorrência é a partir de hoje
+func (s *ExpenseService) ResumeRecurrence(id int, today time.Time) error {
+	s.recurrenceMu.Lock()
+	defer s.recurrenceMu.Unlock()
+
+	rec, err := s.recurrenceRepo.FindByID(id)
+	if err != nil {
+		return errors.New("recorrência não encontrada")
+	}
+	from := rec.NextDate
+	if from.Before(today) {
+		from = today
+	}
+	rec.Paused = false
+	rec.NextDate = rec.FirstOnOrAfter(from)
+
+	if err := s.recurrenceRepo.Update(rec); err != nil {
+		return err
+	}
+	_, err = s.generateDue(today)
+	return err
+}
+
+// EndRecurrence encerra a recorrência na data informada (nenhuma ocorrência depois dela é gerada)
+func (s *ExpenseService) EndRecurrence(id int, endDate time.Time) error {
+	s.recurrenceMu.Lock()
+	defer s.recurrenceMu.Unlock()
+
+	rec, err := s.recurrenceRepo.FindByID(id)
+	if err != nil {
+		return errors.New("recorrência não encontrada")
+	}
+	if endDate.IsZero() {
+		return errors.New("a data final não pode ser vazia")
+	}
+	if endDate.Before(rec.StartDate) {
+		return errors.New("a data final não pode ser anterior ao início")
+	}
+	rec.EndDate = endDate
+	return s.recurrenceRepo.Update(rec)
+}
+
+// GenerateDueRecurrences gera os lançamentos de todas as ocorrências vencidas até hoje
+// Chamado ao iniciar o servidor (recupera os períodos perdidos com ele desligado) e
+// periodicamente depois disso. Retorna quantos lançamentos foram criados
+func (s *ExpenseService) GenerateDueRecurrences(today time.Time) (int, error) {
+	s.recurrenceMu.Lock()
+	defer s.recurrenceMu.Unlock()
+	return s.generateDue(today)
+}
+
+// generateDue gera as ocorrências vencidas (o chamador segura recurrenceMu)
+func (s *ExpenseService) generateDue(today time.Time) (int, error) {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	due, err := s.recurrenceRepo.FindDue(today)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for i := range due {
+		rec := &due[i]
+
+		var dates []time.Time
+		next := rec.NextDate
+		for !next.After(today) && (rec.EndDate.IsZero() || !next.After(rec.EndDate)) {
+			dates = append(dates, next)
+			next = rec.After(next)
+		}
+		if len(dates) == 0 {
+			continue
+		}
+
+		n, err := s.recurrenceRepo.Generate(rec, dates, next)
+		if err != nil {
+			return created, err
+		}
+		created += n
+	}
+	return created, nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"financas/internal/models"
+	"reflect"
+	"testing"
+)
+
+// generatedDates lista as datas dos lançamentos gerados pela recorrência
+func (e *testEnv) generatedDates(t *testing.T, recurrenceID int) []string {
+	t.Helper()
+	rows, err := e.db.Query(`SELECT substr(date, 1, 10) FROM expenses WHERE recurrence_id = ? ORDER BY date`, recurrenceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	dates := []string{}
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			t.Fatal(err)
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+func newRecurrence(frequency string, dayOfMonth int, start string) *models.Recurrence {
+	return &models.Recurrence{Description: "Aluguel", Amount: 150000, Type: "despesa", Category: "Alimentação",
+		Frequency: frequency, DayOfMonth: dayOfMonth, StartDate: day(start)}
+}
+
+func TestRecurrenceGeneration(t *testing.T) {
+	tests := []struct {
+		name  string
+		rec   *models.Recurrence
+		today string
+		want  []string
+	}{
+		{"início no passado gera desde o início", newRecurrence(models.FrequencyMonthly, 31, "2026-01-01"), "2026-04-15",
+			[]string{"2026-01-31", "2026-02-28", "2026-03-31"}},
+		{"ocorrência de hoje é gerada", newRecurrence(models.FrequencyMonthly, 15, "2026-03-01"), "2026-04-15",
+			[]string{"2026-03-15", "2026-04-15"}},
+		{"início no futuro não gera nada", newRecurrence(models.FrequencyMonthly, 10, "2026-05-01"), "2026-04-15",
+			[]string{}},
+		{"para na data final", func() *models.Recurrence {
+			rec := newRecurrence(models.FrequencyWeekly, 0, "2026-03-02")
+			rec.EndDate = day("2026-03-16")
+			return rec
+		}(), "2026-04-15", []string{"2026-03-02", "2026-03-09", "2026-03-16"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEnv(t)
+			tt.rec.AccountID = e.newAccount(t, "Conta", 0, 0)
+			if err := e.expenses.CreateRecurrence(tt.rec, day(tt.today)); err != nil {
+				t.Fatal(err)
+			}
+			if got := e.generatedDates(t, tt.rec.ID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("gerados = %v, quer %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceCatchUpPauseAndUpdate(t *testing.T) {
+	e := newTestEnv(t)
+	rec := newRecurrence(models.FrequencyMonthly, 10, "2026-01-10")
+	rec.AccountID = e.newAccount(t, "Conta", 0, 0)
+	if err := e.expenses.CreateRecurrence(rec, day("2026-01-10")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Servidor desligado por dois meses: a geração recupera os períodos perdidos, sem repetir
+	n, err := e.expenses.GenerateDueRecurrences(day("2026-03-12"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("recuperou %d lançamentos, quer 2", n)
+	}
+	if n, _ := e.expenses.GenerateDueRecurrences(day("2026-03-12")); n != 0 {
+		t.Errorf("segunda execução criou %d lançamentos", n)
+	}
+
+	// Pausada em abril e maio: ao retomar, esses meses não são gerados
+	if err := e.expenses.PauseRecurrence(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := e.expenses.GenerateDueRecurrences(day("2026-05-20")); n != 0 {
+		t.Errorf("recorrência pausada criou %d lançamentos", n)
+	}
+	if err := e.expenses.ResumeRecurrence(rec.ID, day("2026-05-20")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.expenses.GenerateDueRecurrences(day("2026-06-10")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Mudar o dia vale a partir da próxima ocorrência, sem mexer nos lançamentos gerados
+	current, err := e.expenses.FindRecurrenceByID(rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current.DayOfMonth = 20
+	current.Amount = 160000
+	if err := e.expenses.UpdateRecurrence(current, day("2026-07-20")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"2026-01-10", "2026-02-10", "2026-03-10", "2026-06-10", "2026-07-20"}
+	if got := e.generatedDates(t, rec.ID); !reflect.DeepEqual(got, want) {
+		t.Errorf("gerados = %v, quer %v", got, want)
+	}
+	if n := e.count(t, `SELECT count(*) FROM expenses WHERE recurrence_id = ? AND amount = 160000`, rec.ID); n != 1 {
+		t.Errorf("%d lançamentos com o valor novo, quer só o de julho", n)
+	}
+
+	// Encerrada em agosto: nada depois da data final
+	if err := e.expenses.EndRecurrence(rec.ID, day("2026-08-31")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.expenses.GenerateDueRecurrences(day("2026-12-31")); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.generatedDates(t, rec.ID); len(got) != 6 || got[5] != "2026-08-20" {
+		t.Errorf("gerados depois de encerrar = %v, quer até 2026-08-20", got)
+	}
+	if err := e.expenses.EndRecurrence(rec.ID, day("2025-12-31")); err == nil {
+		t.Error("data final antes do início aceita")
+	}
+}
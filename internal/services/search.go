@@ -0,0 +1,120 @@
+package services
+
+import (
+	"financas/internal/models"
+	"financas/internal/repositories"
+	"strings"
+	"unicode"
+)
+
+// Limites de resultados da busca global
+const (
+	searchExpenseLimit  = 50
+	searchPurchaseLimit = 30
+)
+
+// SearchService é a busca global: lançamentos pela descrição e compras de lanche pelo nome do membro
+type SearchService struct {
+	expenseRepo  *repositories.ExpenseRepository
+	purchaseRepo *repositories.PurchaseRepository
+	userRepo     *repositories.UserRepository
+}
+
+func NewSearchService(
+	expenseRepo *repositories.ExpenseRepository,
+	purchaseRepo *repositories.PurchaseRepository,
+	userRepo *repositories.UserRepository,
+) *SearchService {
+	return &SearchService{
+		expenseRepo:  expenseRepo,
+		purchaseRepo: purchaseRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// SearchResults reúne os resultados da busca global
+type SearchResults struct {
+	Query     string
+	Expenses  []models.Expense  // Mais relevantes primeiro
+	Members   []models.User     // Membros cujo nome combina com a busca (inclusive arquivados)
+	Purchases []models.Purchase // Compras mais recentes desses membros
+}
+
+// IsEmpty indica se a busca não encontrou nada
+func (r *SearchResults) IsEmpty() bool {
+	return len(r.Expenses) == 0 && len(r.Purchases) == 0
+}
+
+// Search busca o texto nas descrições dos lançamentos (FTS5, por prefixo e sem acentos)
+// e nos nomes dos membros, trazendo as compras de lanche de quem combinar
+func (s *SearchService) Search(query string) (*SearchResults, error) {
+	results := &SearchResults{Query: strings.TrimSpace(query)}
+	if results.Query == "" {
+		return results, nil
+	}
+
+	expenses, err := s.expenseRepo.Search(results.Query, searchExpenseLimit)
+	if err != nil {
+		return nil, err
+	}
+	results.Expenses = expenses
+
+	users, err := s.userRepo.FindAllWithArchived()
+	if err != nil {
+		return nil, err
+	}
+	terms := strings.Fields(foldAccents(results.Query))
+	var ids []int
+	for _, user := range users {
+		if nameMatches(user.Name, terms) {
+			results.Members = append(results.Members, user)
+			ids = append(ids, user.ID)
+		}
+	}
+
+	purchases, err := s.purchaseRepo.FindByUsers(ids, searchPurchaseLimit)
+	if err != nil {
+		return nil, err
+	}
+	results.Purchases = purchases
+	return results, nil
+}
+
+// nameMatches indica se alguma palavra da busca (2+ letras) é início de uma palavra do nome
+// Qualquer palavra basta: "café ana" encontra o lançamento do café e as compras da Ana
+func nameMatches(name string, terms []string) bool {
+	words := strings.Fields(foldAccents(name))
+	for _, term := range terms {
+		if len([]rune(term)) < 2 {
+			continue
+		}
+		for _, word := range words {
+			if strings.HasPrefix(word, term) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// accentFolder troca as letras acentuadas do português pela letra sem acento
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// foldAccents normaliza o texto para comparação: minúsculas, sem acentos e sem pontuação
+// (mesma regra do tokenizer do FTS, para a busca por membro se comportar como a de lançamentos)
+func foldAccents(s string) string {
+	s = accentFolder.Replace(strings.ToLower(s))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+}
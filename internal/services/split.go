@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"financas/internal/models"
+	"sort"
+)
+
+// ErrNoParticipants indica compras divididas entre todos num mês em que nenhum membro participa
+// (todos com fator 0: fora da equipe no período ou com peso 0)
+var ErrNoParticipants = errors.New("há compras divididas entre todos, mas nenhum membro participa do rateio deste mês; ajuste os pesos de participação")
+
+// memberShares calcula a cota de cada membro no mês, compra a compra
+// Compras sem participantes são divididas entre todos pelo fator de participação do mês;
+// compras com participantes são divididas só entre eles (valores fixos + restante pelos pesos).
+// As divisões são feitas em centavos exatos (Money.Allocate), então a soma das cotas é igual
+// ao total das compras e os saldos do mês somam exatamente zero.
+// Retorna também a cota cheia (por unidade de participação) das compras divididas entre todos.
+// Se houver compras divididas entre todos e nenhum fator positivo, retorna ErrNoParticipants
+// em vez de deixar essas compras fora das cotas
+func memberShares(purchases []models.Purchase, factors map[int]float64) (map[int]models.Money, models.Money, error) {
+	shares := make(map[int]models.Money)
+
+	var sharedTotal models.Money
+	for _, purchase := range purchases {
+		if purchase.SplitAmongAll() {
+			sharedTotal += purchase.Amount
+			continue
+		}
+		for userID, value := range purchaseSplit(purchase) {
+			shares[userID] += value
+		}
+	}
+
+	userIDs, weights := sortedWeights(factors)
+	totalWeight := 0.0
+	for _, w := range weights {
+		if w > 0 {
+			totalWeight += w
+		}
+	}
+	if totalWeight == 0 {
+		if sharedTotal != 0 {
+			return nil, 0, ErrNoParticipants
+		}
+		return shares, 0, nil
+	}
+
+	for i, part := range sharedTotal.Allocate(weights) {
+		if part != 0 {
+			shares[userIDs[i]] += part
+		}
+	}
+	return shares, models.MoneyFromFloat(sharedTotal.Float() / totalWeight), nil
+}
+
+// purchaseSplit divide uma compra entre seus participantes
+// Quem tem valor fixo paga o valor do próprio item; o restante é dividido pelos pesos dos demais
+func purchaseSplit(purchase models.Purchase) map[int]models.Money {
+	split := make(map[int]models.Money)
+
+	remaining := purchase.Amount
+	weighted := make(map[int]float64)
+	for _, p := range purchase.Participants {
+		if p.Amount > 0 {
+			split[p.UserID] += p.Amount
+			remaining -= p.Amount
+		} else if p.Weight > 0 {
+			weighted[p.UserID] = p.Weight
+		}
+	}
+
+	userIDs, weights := sortedWeights(weighted)
+	for i, part := range remaining.Allocate(weights) {
+		if part != 0 {
+			split[userIDs[i]] += part
+		}
+	}
+	return split
+}
+
+// sortedWeights ordena os pesos por ID do membro, para que a distribuição dos centavos
+// que sobram seja sempre a mesma
+func sortedWeights(weightsByUser map[int]float64) ([]int, []float64) {
+	userIDs := make([]int, 0, len(weightsByUser))
+	for userID := range weightsByUser {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Ints(userIDs)
+
+	weights := make([]float64, len(userIDs))
+	for i, userID := range userIDs {
+		weights[i] = weightsByUser[userID]
+	}
+	return userIDs, weights
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"financas/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestMemberShares(t *testing.T) {
+	all := models.Purchase{Amount: 1000}
+	items := models.Purchase{Amount: 1000, Participants: []models.PurchaseParticipant{
+		{UserID: 1, Amount: 400},
+		{UserID: 2, Weight: 1},
+		{UserID: 3, Weight: 2},
+	}}
+
+	tests := []struct {
+		name      string
+		purchases []models.Purchase
+		factors   map[int]float64
+		want      map[int]models.Money
+		wantUnit  models.Money
+		wantErr   error
+	}{
+		{"todos em partes iguais", []models.Purchase{all}, map[int]float64{1: 1, 2: 1, 3: 1},
+			map[int]models.Money{1: 334, 2: 333, 3: 333}, 333, nil},
+		{"meio mês de participação", []models.Purchase{all}, map[int]float64{1: 1, 2: 0.5, 3: 1},
+			map[int]models.Money{1: 400, 2: 200, 3: 400}, 400, nil},
+		{"fator 0 fica fora", []models.Purchase{all}, map[int]float64{1: 1, 2: 0},
+			map[int]models.Money{1: 1000}, 1000, nil},
+		{"valor fixo e pesos", []models.Purchase{items}, map[int]float64{1: 1, 2: 1, 3: 1},
+			map[int]models.Money{1: 400, 2: 200, 3: 400}, 0, nil},
+		{"só participantes com todos os fatores 0", []models.Purchase{items}, map[int]float64{1: 0, 2: 0, 3: 0},
+			map[int]models.Money{1: 400, 2: 200, 3: 400}, 0, nil},
+		{"compra para todos sem ninguém participando", []models.Purchase{all, items}, map[int]float64{1: 0, 2: 0, 3: 0},
+			nil, 0, ErrNoParticipants},
+		{"compra para todos sem membros", []models.Purchase{all}, map[int]float64{},
+			nil, 0, ErrNoParticipants},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, unit, err := memberShares(tt.purchases, tt.factors)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("erro = %v, quer %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(shares, tt.want) {
+				t.Errorf("cotas = %v, quer %v", shares, tt.want)
+			}
+			if unit != tt.wantUnit {
+				t.Errorf("cota cheia = %d, quer %d", unit, tt.wantUnit)
+			}
+		})
+	}
+}
+
+func TestRateioWithoutParticipants(t *testing.T) {
+	e := newTestEnv(t)
+	ana := e.newUser(t, "Ana")
+	e.newPurchase(t, ana, 1000, "2026-01-10")
+
+	// Compra lançada antes de qualquer membro entrar na equipe
+	e.exec(t, `UPDATE users SET joined_at = '2026-02-01'`)
+
+	if _, err := e.purchases.CalculateRateio("2026-01"); !errors.Is(err, ErrNoParticipants) {
+		t.Errorf("rateio = %v, quer ErrNoParticipants", err)
+	}
+	if err := e.purchases.CloseMonth("2026-01", false); !errors.Is(err, ErrNoParticipants) {
+		t.Errorf("fechamento = %v, quer ErrNoParticipants", err)
+	}
+}
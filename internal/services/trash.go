@@ -0,0 +1,36 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"financas/internal/models"
+	"time"
+)
+
+// ErrExpenseNotFound indica um lançamento inexistente ou que está na lixeira
+var ErrExpenseNotFound = errors.New("lançamento não encontrado")
+
+// DefaultTrashRetentionDays é quanto tempo um lançamento removido fica na lixeira antes de ser apagado
+const DefaultTrashRetentionDays = 30
+
+// FindDeleted retorna os lançamentos na lixeira
+func (s *ExpenseService) FindDeleted() ([]models.Expense, error) {
+	return s.repository.FindDeleted()
+}
+
+// Restore tira um lançamento da lixeira
+func (s *ExpenseService) Restore(id int) error {
+	err := s.repository.Restore(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrExpenseNotFound
+	}
+	return err
+}
+
+// PurgeTrash apaga definitivamente o que está na lixeira há mais de retentionDays dias
+func (s *ExpenseService) PurgeTrash(now time.Time, retentionDays int) (int64, error) {
+	if retentionDays <= 0 {
+		return 0, errors.New("o prazo da lixeira deve ser positivo")
+	}
+	return s.repository.Purge(now.AddDate(0, 0, -retentionDays))
+}
@@ -4,15 +4,24 @@ import (
 	"errors"
 	"financas/internal/models"
 	"financas/internal/repositories"
+	"math"
 	"strings"
+	"time"
 )
 
+// ErrMemberHasBalance indica que o membro ainda tem saldo em aberto no rateio
+var ErrMemberHasBalance = errors.New("o membro possui saldo em aberto; acerte as contas ou force o arquivamento")
+
 type UserService struct {
-	repository *repositories.UserRepository
+	repository        *repositories.UserRepository
+	participationRepo *repositories.ParticipationRepository
 }
 
-func NewUserService(repository *repositories.UserRepository) *UserService {
-	return &UserService{repository: repository}
+func NewUserService(repository *repositories.UserRepository, participationRepo *repositories.ParticipationRepository) *UserService {
+	return &UserService{
+		repository:        repository,
+		participationRepo: participationRepo,
+	}
 }
 
 // Create cria um novo membro da equipe
@@ -25,29 +34,87 @@ func (s *UserService) Create(user *models.User) error {
 	return s.repository.Create(user)
 }
 
-// FindAll retorna todos os membros
+// FindAll retorna os membros ativos
 func (s *UserService) FindAll() ([]models.User, error) {
 	return s.repository.FindAll()
 }
 
+// FindArchived retorna os membros arquivados
+func (s *UserService) FindArchived() ([]models.User, error) {
+	return s.repository.FindArchived()
+}
+
 // FindByID busca um membro pelo ID
 func (s *UserService) FindByID(id int) (*models.User, error) {
 	return s.repository.FindByID(id)
 }
 
+// Update altera nome e período de participação de um membro
+func (s *UserService) Update(user *models.User) error {
+	if strings.TrimSpace(user.Name) == "" {
+		return errors.New("o nome não pode ser vazio")
+	}
+	user.Name = strings.TrimSpace(user.Name)
+	if !user.JoinedAt.IsZero() && !user.LeftAt.IsZero() && user.LeftAt.Before(user.JoinedAt) {
+		return errors.New("a data de saída não pode ser anterior à data de entrada")
+	}
+	return s.repository.Update(user)
+}
+
+// SetParticipation define o peso do membro no rateio de um mês (0 = opt-out)
+func (s *UserService) SetParticipation(p *models.MonthParticipation) error {
+	if _, err := time.Parse("2006-01", p.Month); err != nil {
+		return errors.New("mês inválido")
+	}
+	if math.IsNaN(p.Weight) || math.IsInf(p.Weight, 0) {
+		return errors.New("peso inválido")
+	}
+	if p.Weight < 0 {
+		return errors.New("o peso não pode ser negativo")
+	}
+	if _, err := s.repository.FindByID(p.UserID); err != nil {
+		return errors.New("usuário não encontrado")
+	}
+	return s.participationRepo.Save(p)
+}
+
+// DeleteParticipation remove o peso configurado, voltando à cota cheia no mês
+func (s *UserService) DeleteParticipation(userID int, month string) error {
+	return s.participationRepo.Delete(userID, month)
+}
+
+// GetParticipations retorna os pesos mensais configurados de um membro
+func (s *UserService) GetParticipations(userID int) ([]models.MonthParticipation, error) {
+	return s.participationRepo.FindByUser(userID)
+}
+
 // GetRanking retorna o ranking de pontos
 func (s *UserService) GetRanking() ([]models.User, error) {
 	return s.repository.GetRanking()
 }
 
-// UpdatePoints atualiza os pontos de um usuário
-func (s *UserService) UpdatePoints(userID int, points int) error {
-	return s.repository.UpdatePoints(userID, points)
+// Archive arquiva um membro em vez de apagá-lo
+// Com saldo acumulado diferente de zero só arquiva se force for true
+func (s *UserService) Archive(id int, outstanding models.Money, force bool) error {
+	user, err := s.repository.FindByID(id)
+	if err != nil {
+		return errors.New("usuário não encontrado")
+	}
+	if user.IsArchived() {
+		return nil
+	}
+	if outstanding != 0 && !force {
+		return ErrMemberHasBalance
+	}
+	return s.repository.Archive(id)
 }
 
-// Delete remove um usuário
-func (s *UserService) Delete(id int) error {
-	return s.repository.Delete(id)
+// Restore reativa um membro arquivado
+func (s *UserService) Restore(id int) error {
+	if _, err := s.repository.FindByID(id); err != nil {
+		return errors.New("usuário não encontrado")
+	}
+	return s.repository.Restore(id)
 }
 
 // Count retorna o número de membros
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"financas/internal/models"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Estilos de célula definidos em xlsxStyles (índice em cellXfs)
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleMoney
+	xlsxStyleDate
+	xlsxStyleHeader
+	xlsxStyleDecimal
+)
+
+// xlsxEpoch é o dia zero das datas seriais do Excel
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// writeXLSX escreve as tabelas como uma planilha XLSX, uma aba por tabela
+// O arquivo é montado à mão (SpreadsheetML mínimo): textos inline, valores e datas numéricos
+// com formato de célula, para que somas e filtros funcionem na planilha
+func writeXLSX(w io.Writer, tables []ExportTable, br bool) error {
+	archive := zip.NewWriter(w)
+
+	var contentTypes, workbook, rels strings.Builder
+	sheets := make([]string, len(tables))
+	contentTypes.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for i, table := range tables {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(xlsxSheetName(table.Name, n)), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" `+
+			`Target="worksheets/sheet%d.xml"/>`, n, n)
+		sheets[i] = xlsxSheet(table)
+	}
+
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" `+
+		`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" `+
+		`Target="styles.xml"/></Relationships>`, len(tables)+1)
+
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" ` +
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" ` +
+			`Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", rels.String()},
+		{"xl/styles.xml", xlsxStyles(br)},
+	}
+	// [Content_Types].xml primeiro: alguns leitores exigem essa ordem no pacote
+	for i, sheet := range sheets {
+		files = append(files, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet})
+	}
+	for _, f := range files {
+		if err := writeZipFile(archive, f.name, f.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// xlsxStyles define os formatos de valor e data; no formato brasileiro, "R$" e dd/mm/aaaa
+// O separador decimal exibido segue a configuração regional de quem abre a planilha
+func xlsxStyles(br bool) string {
+	moneyFormat, dateFormat := "#,##0.00", "yyyy-mm-dd"
+	if br {
+		moneyFormat, dateFormat = `"R$" #,##0.00`, "dd/mm/yyyy"
+	}
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="2">` +
+		`<numFmt numFmtId="164" formatCode="` + xmlEscape(moneyFormat) + `"/>` +
+		`<numFmt numFmtId="165" formatCode="` + xmlEscape(dateFormat) + `"/>` +
+		`</numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font>` +
+		`<font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill>` +
+		`<fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="5">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`<xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`</cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+}
+
+// xlsxSheet monta a aba: cabeçalho em negrito e uma linha por registro
+func xlsxSheet(table ExportTable) string {
+	widths := make([]int, len(table.Columns))
+	for i, column := range table.Columns {
+		widths[i] = utf8.RuneCountInString(column)
+	}
+
+	var data strings.Builder
+	data.WriteString(`<row r="1">`)
+	for i, column := range table.Columns {
+		data.WriteString(xlsxCell(i, 1, column, xlsxStyleHeader))
+	}
+	data.WriteString(`</row>`)
+
+	for r, row := range table.Rows {
+		line := r + 2
+		fmt.Fprintf(&data, `<row r="%d">`, line)
+		for i, cell := range row {
+			data.WriteString(xlsxValueCell(i, line, cell))
+			if s, ok := cell.(string); ok && i < len(widths) && utf8.RuneCountInString(s) > widths[i] {
+				widths[i] = utf8.RuneCountInString(s)
+			}
+		}
+		data.WriteString(`</row>`)
+	}
+
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	if len(widths) > 0 {
+		sheet.WriteString(`<cols>`)
+		for i, width := range widths {
+			width = min(max(width+2, 12), 60)
+			fmt.Fprintf(&sheet, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+		}
+		sheet.WriteString(`</cols>`)
+	}
+	sheet.WriteString(`<sheetData>`)
+	sheet.WriteString(data.String())
+	sheet.WriteString(`</sheetData></worksheet>`)
+	return sheet.String()
+}
+
+// xlsxValueCell escreve a célula conforme o tipo: texto inline, número, valor em reais ou data serial
+func xlsxValueCell(col, row int, cell interface{}) string {
+	ref := xlsxColumn(col) + strconv.Itoa(row)
+	switch v := cell.(type) {
+	case string:
+		if v == "" {
+			return ""
+		}
+		return xlsxCell(col, row, v, xlsxStyleDefault)
+	case int:
+		return fmt.Sprintf(`<c r="%s"><v>%d</v></c>`, ref, v)
+	case float64:
+		return fmt.Sprintf(`<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleDecimal, strconv.FormatFloat(v, 'f', -1, 64))
+	case models.Money:
+		return fmt.Sprintf(`<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleMoney, strconv.FormatFloat(v.Float(), 'f', 2, 64))
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		day := time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC)
+		serial := int(day.Sub(xlsxEpoch).Hours() / 24)
+		return fmt.Sprintf(`<c r="%s" s="%d"><v>%d</v></c>`, ref, xlsxStyleDate, serial)
+	default:
+		return xlsxCell(col, row, fmt.Sprint(v), xlsxStyleDefault)
+	}
+}
+
+// xlsxCell escreve uma célula de texto inline
+// Texto inline nunca é avaliado como fórmula, mesmo começando com "=" (descrições importadas)
+func xlsxCell(col, row int, text string, style int) string {
+	return fmt.Sprintf(`<c r="%s%d" t="inlineStr" s="%d"><is><t xml:space="preserve">%s</t></is></c>`,
+		xlsxColumn(col), row, style, xmlEscape(text))
+}
+
+// xlsxColumn converte o índice da coluna (0 = A) para a letra da planilha (A, B, ..., Z, AA...)
+func xlsxColumn(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// xlsxSheetName ajusta o nome da aba às regras do Excel (até 31 caracteres, sem []:*?/\)
+func xlsxSheetName(name string, n int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if strings.TrimSpace(name) == "" {
+		name = "Planilha " + strconv.Itoa(n)
+	}
+	return name
+}
+
+// xmlEscape escapa texto para conteúdo e atributos XML
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// writeZipFile grava um arquivo do pacote XLSX
+func writeZipFile(archive *zip.Writer, name, content string) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}